@@ -6,12 +6,19 @@ import (
 	"log"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/processor"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/config"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/jwtkeys"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/metrics"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/outbox"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository/pg"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/tracing"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/webhook"
 )
 
 func main() {
@@ -29,22 +36,61 @@ func run() error {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	shutdownTracing, err := tracing.Setup(ctx, cfg.TraceExporter)
+	if err != nil {
+		return fmt.Errorf("failed to init tracing: %w", err)
+	}
+	defer func() {
+		// Выгружаем оставшиеся спаны уже после отмены основного контекста.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("failed to shutdown tracing: %v", err)
+		}
+	}()
+
 	db, err := pg.NewDB(ctx, cfg.DatabaseDSN)
 	if err != nil {
 		return fmt.Errorf("failed to init DB: %w", err)
 	}
 	defer db.Close()
 
-	logger, err := logger.New()
+	logger, err := logger.New(*cfg.Log)
 	if err != nil {
 		return fmt.Errorf("failed to init logger: %w", err)
 	}
 	defer logger.Sync()
 
+	keys, err := jwtkeys.Load(cfg.JWTSigningKey, cfg.JWTVerifyKeys)
+	if err != nil {
+		return fmt.Errorf("failed to load jwt keys: %w", err)
+	}
+	if cfg.JWTSigningKey == "" {
+		logger.Warn("jwt signing key is not configured, tokens will not survive restart", nil)
+	}
+
 	processor := processor.New(cfg.AccrualGfg, db, logger)
 	processor.Run(ctx)
 	defer processor.Stop()
 
-	httpServer := api.NewApp(cfg, db, logger)
+	metrics.RegisterDBPool(db.Pool())
+	metrics.RegisterOrders(repository.NewAdminOrder(db))
+	metrics.RegisterQueue(processor.QueueDepth)
+
+	dispatcher := webhook.NewDispatcher(repository.NewWebhook(db), logger, webhook.DefaultConfig)
+	dispatcher.Run(ctx)
+	defer dispatcher.Stop()
+
+	sink, closeSink, err := outbox.NewSink(cfg.OutboxSink, logger)
+	if err != nil {
+		return fmt.Errorf("failed to init outbox sink: %w", err)
+	}
+	defer closeSink()
+
+	relay := outbox.NewRelay(repository.NewOutbox(db), sink, logger, outbox.DefaultConfig)
+	relay.Run(ctx)
+	defer relay.Stop()
+
+	httpServer := api.NewApp(cfg, db, keys, processor, logger)
 	return httpServer.Run(ctx)
 }
@@ -0,0 +1,6 @@
+BEGIN TRANSACTION;
+
+COMMENT ON TABLE balance IS NULL;
+DROP TABLE ledger;
+
+COMMIT;
@@ -0,0 +1,38 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS ledger (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT ON UPDATE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('ACCRUAL', 'WITHDRAWAL', 'ADJUSTMENT')),
+    amount NUMERIC(10, 2) NOT NULL CHECK (amount <> 0),
+    order_num VARCHAR(32) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE ledger IS 'Append-only journal of all balance movements.';
+COMMENT ON COLUMN ledger.amount IS 'Positive for credits, negative for debits.';
+COMMENT ON COLUMN ledger.order_num IS 'Accrued or paid order number, empty for adjustments.';
+CREATE INDEX idx_ledger_user_id ON ledger(user_id);
+CREATE UNIQUE INDEX idx_ledger_accrual_order_num ON ledger(order_num) WHERE kind = 'ACCRUAL';
+
+INSERT INTO ledger (user_id, kind, amount, order_num, created_at)
+    SELECT user_id, 'ACCRUAL', accrual, number, updated_at
+    FROM orders
+    WHERE status = 'PROCESSED' AND accrual > 0;
+
+INSERT INTO ledger (user_id, kind, amount, order_num, created_at)
+    SELECT user_id, 'WITHDRAWAL', -sum, order_num, processed_at
+    FROM withdrawals
+    WHERE sum <> 0;
+
+-- Расхождения, накопленные до появления журнала, фиксируются корректировкой
+INSERT INTO ledger (user_id, kind, amount)
+    SELECT b.user_id, 'ADJUSTMENT', b.balance - COALESCE(l.total, 0)
+    FROM balance b
+    LEFT JOIN (SELECT user_id, SUM(amount) AS total FROM ledger GROUP BY user_id) l
+        ON l.user_id = b.user_id
+    WHERE b.balance <> COALESCE(l.total, 0);
+
+COMMENT ON TABLE balance IS 'Cached projection of the ledger table.';
+
+COMMIT;
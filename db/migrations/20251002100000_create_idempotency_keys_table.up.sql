@@ -0,0 +1,19 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT ON UPDATE CASCADE,
+    key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status_code SMALLINT NOT NULL DEFAULT 0,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    body BYTEA NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, key)
+);
+
+COMMENT ON TABLE idempotency_keys IS 'Stored responses for requests sent with an Idempotency-Key header.';
+COMMENT ON COLUMN idempotency_keys.request_hash IS 'SHA-256 of the request method, path and body.';
+COMMENT ON COLUMN idempotency_keys.status_code IS 'Zero while the original request is still in progress.';
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys(created_at);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE withdrawals
+    DROP CONSTRAINT IF EXISTS withdrawals_order_num_payment_num_key,
+    DROP COLUMN IF EXISTS payment_num;
+
+COMMIT;
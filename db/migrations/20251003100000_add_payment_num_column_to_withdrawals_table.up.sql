@@ -0,0 +1,19 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE withdrawals
+    ADD COLUMN IF NOT EXISTS payment_num SMALLINT NOT NULL DEFAULT 1 CHECK (payment_num > 0);
+
+UPDATE withdrawals w SET payment_num = n.num
+    FROM (
+        SELECT id, ROW_NUMBER() OVER (PARTITION BY order_num ORDER BY id) AS num
+        FROM withdrawals
+    ) n
+    WHERE w.id = n.id;
+
+ALTER TABLE withdrawals
+    ADD CONSTRAINT withdrawals_order_num_payment_num_key UNIQUE (order_num, payment_num);
+
+COMMENT ON COLUMN withdrawals.payment_num IS
+    'Sequence number of the partial payment for the order, limited by the withdraw policy.';
+
+COMMIT;
@@ -0,0 +1,27 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT ON UPDATE CASCADE,
+    hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE refresh_tokens IS 'Issued refresh tokens, each one can be exchanged only once.';
+COMMENT ON COLUMN refresh_tokens.hash IS 'SHA-256 of the token, the token itself is never stored.';
+COMMENT ON COLUMN refresh_tokens.revoked_at IS 'Set on rotation or logout, NULL while the token is active.';
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+
+CREATE TABLE IF NOT EXISTS revoked_access_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT ON UPDATE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+COMMENT ON TABLE revoked_access_tokens IS 'Access tokens revoked before expiration.';
+COMMENT ON COLUMN revoked_access_tokens.expires_at IS 'Token expiration, the row is useless after it.';
+CREATE INDEX idx_revoked_access_tokens_expires_at ON revoked_access_tokens(expires_at);
+
+COMMIT;
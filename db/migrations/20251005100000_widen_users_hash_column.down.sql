@@ -0,0 +1,7 @@
+BEGIN TRANSACTION;
+
+-- Откат невозможен, пока в таблице есть argon2id хеши длиннее 60 символов.
+ALTER TABLE users ALTER COLUMN hash TYPE VARCHAR(60);
+COMMENT ON COLUMN users.hash IS 'Password hash with salt.';
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE users ALTER COLUMN hash TYPE VARCHAR(255);
+COMMENT ON COLUMN users.hash IS 'Password hash in PHC format (argon2id) or legacy bcrypt hash.';
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS login_attempts (
+    key VARCHAR(128) PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE login_attempts IS 'Failed login attempts shared by all service replicas.';
+COMMENT ON COLUMN login_attempts.key IS 'Throttled subject: login:<login> or ip:<address>.';
+COMMENT ON COLUMN login_attempts.updated_at IS 'Time of the last failure, older counters are forgotten.';
+CREATE INDEX idx_login_attempts_updated_at ON login_attempts(updated_at);
+
+COMMIT;
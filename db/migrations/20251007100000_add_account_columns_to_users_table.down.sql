@@ -0,0 +1,14 @@
+BEGIN TRANSACTION;
+
+-- Списания закрытых аккаунтов становятся корректировками, чтобы журнал сходился с балансом
+UPDATE ledger SET kind = 'ADJUSTMENT' WHERE kind = 'FORFEIT';
+ALTER TABLE ledger DROP CONSTRAINT ledger_kind_check;
+ALTER TABLE ledger ADD CONSTRAINT ledger_kind_check
+    CHECK (kind IN ('ACCRUAL', 'WITHDRAWAL', 'ADJUSTMENT'));
+COMMENT ON COLUMN ledger.kind IS NULL;
+
+ALTER TABLE users
+    DROP COLUMN deleted_at,
+    DROP COLUMN tokens_valid_after;
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE users
+    ADD COLUMN tokens_valid_after TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN users.tokens_valid_after IS 'Access tokens issued earlier are rejected, set on password change.';
+COMMENT ON COLUMN users.deleted_at IS 'Account closing time, the row is kept anonymized for orders and ledger.';
+
+ALTER TABLE ledger DROP CONSTRAINT ledger_kind_check;
+ALTER TABLE ledger ADD CONSTRAINT ledger_kind_check
+    CHECK (kind IN ('ACCRUAL', 'WITHDRAWAL', 'ADJUSTMENT', 'FORFEIT'));
+COMMENT ON COLUMN ledger.kind IS 'FORFEIT writes off the remaining balance of a closed account.';
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN TRANSACTION;
+
+DROP INDEX IF EXISTS idx_orders_user_id_uploaded_at;
+CREATE INDEX idx_orders_user_id ON orders(user_id);
+
+DROP INDEX IF EXISTS idx_withdrawals_user_id_processed_at;
+CREATE INDEX idx_withdrawals_user_id ON withdrawals(user_id);
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN TRANSACTION;
+
+DROP INDEX IF EXISTS idx_orders_user_id;
+CREATE INDEX idx_orders_user_id_uploaded_at ON orders(user_id, uploaded_at, number);
+
+DROP INDEX IF EXISTS idx_withdrawals_user_id;
+CREATE INDEX idx_withdrawals_user_id_processed_at ON withdrawals(user_id, processed_at, id);
+
+COMMIT;
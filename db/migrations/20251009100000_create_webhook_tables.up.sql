@@ -0,0 +1,40 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT ON UPDATE CASCADE,
+    url VARCHAR(2048) NOT NULL,
+    secret CHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, url)
+);
+
+COMMENT ON TABLE webhooks IS 'User endpoints notified about order status changes.';
+COMMENT ON COLUMN webhooks.secret IS 'HMAC-SHA256 key for the delivery signature, shown to the user once.';
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    webhook_id BIGINT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    event_id UUID NOT NULL,
+    order_num VARCHAR(255) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    accrual NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    failed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE webhook_deliveries IS
+    'Outbox of order status events, written in the same transaction as the status change.';
+COMMENT ON COLUMN webhook_deliveries.event_id IS 'Same for all webhooks of one event, receivers use it for deduplication.';
+COMMENT ON COLUMN webhook_deliveries.next_attempt_at IS
+    'Not earlier than this time the delivery is (re)tried, a claimed delivery is leased by moving it forward.';
+COMMENT ON COLUMN webhook_deliveries.failed_at IS 'Set when all attempts are exhausted.';
+CREATE INDEX idx_webhook_deliveries_pending ON webhook_deliveries(next_attempt_at)
+    WHERE delivered_at IS NULL AND failed_at IS NULL;
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id);
+
+COMMIT;
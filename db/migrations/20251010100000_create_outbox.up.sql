@@ -0,0 +1,22 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    published_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE outbox IS
+    'Accrual processing events, written in the same transaction as the order and balance changes.';
+COMMENT ON COLUMN outbox.event_id IS 'Sent with the event, consumers use it for deduplication.';
+COMMENT ON COLUMN outbox.next_attempt_at IS
+    'Not earlier than this time the event is (re)published, a claimed event is leased by moving it forward.';
+CREATE INDEX idx_outbox_pending ON outbox(next_attempt_at, id) WHERE published_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN TRANSACTION;
+
+DROP INDEX IF EXISTS idx_orders_pending;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS lease_expires_at,
+    DROP COLUMN IF EXISTS lease_owner;
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS lease_owner VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS lease_expires_at TIMESTAMP WITH TIME ZONE;
+
+COMMENT ON COLUMN orders.lease_owner IS 'Gophermart instance processing the order, NULL when the order is not leased.';
+COMMENT ON COLUMN orders.lease_expires_at IS 'After this time the order can be claimed by another instance.';
+
+CREATE INDEX IF NOT EXISTS idx_orders_pending ON orders(updated_at) WHERE status IN ('NEW', 'PROCESSING');
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN TRANSACTION;
+
+DROP INDEX IF EXISTS idx_orders_failed;
+DROP INDEX IF EXISTS idx_orders_pending;
+CREATE INDEX idx_orders_pending ON orders(updated_at) WHERE status IN ('NEW', 'PROCESSING');
+
+-- Заказы из dead-letter возвращаются в очередь
+UPDATE orders SET status = 'NEW', attempts = 0 WHERE status = 'FAILED';
+ALTER TABLE orders DROP CONSTRAINT orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+    CHECK (status IN ('NEW', 'PROCESSING', 'INVALID', 'PROCESSED'));
+COMMENT ON COLUMN orders.status IS NULL;
+
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS last_error,
+    DROP COLUMN IF EXISTS next_attempt_at;
+
+COMMIT;
@@ -0,0 +1,21 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS last_error TEXT;
+
+COMMENT ON COLUMN orders.next_attempt_at IS
+    'Not earlier than this time the order is polled in the accrual system, NULL until the first attempt.';
+COMMENT ON COLUMN orders.last_error IS 'Reason of the last unsuccessful processing attempt.';
+
+ALTER TABLE orders DROP CONSTRAINT orders_status_check;
+ALTER TABLE orders ADD CONSTRAINT orders_status_check
+    CHECK (status IN ('NEW', 'PROCESSING', 'INVALID', 'PROCESSED', 'FAILED'));
+COMMENT ON COLUMN orders.status IS
+    'FAILED orders ran out of attempts because of accrual system errors and wait for an operator.';
+
+DROP INDEX IF EXISTS idx_orders_pending;
+CREATE INDEX idx_orders_pending ON orders(next_attempt_at) WHERE status IN ('NEW', 'PROCESSING');
+CREATE INDEX idx_orders_failed ON orders(updated_at) WHERE status = 'FAILED';
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE users DROP COLUMN IF EXISTS role;
+
+COMMIT;
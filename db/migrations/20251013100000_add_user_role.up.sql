@@ -0,0 +1,9 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(16) NOT NULL DEFAULT 'user'
+        CONSTRAINT users_role_check CHECK (role IN ('user', 'support', 'admin'));
+
+COMMENT ON COLUMN users.role IS 'Access role: user, support or admin. Copied to the role claim of issued JWT.';
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN TRANSACTION;
+
+DROP INDEX IF EXISTS idx_ledger_user_id_created_at;
+DROP TABLE IF EXISTS balance_adjustments;
+
+COMMIT;
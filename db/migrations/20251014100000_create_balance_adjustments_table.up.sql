@@ -0,0 +1,20 @@
+BEGIN TRANSACTION;
+
+CREATE TABLE IF NOT EXISTS balance_adjustments (
+    id BIGSERIAL PRIMARY KEY,
+    ledger_id BIGINT NOT NULL UNIQUE REFERENCES ledger(id) ON DELETE RESTRICT,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT ON UPDATE CASCADE,
+    amount NUMERIC(10, 2) NOT NULL CHECK (amount <> 0),
+    reason TEXT NOT NULL CHECK (reason <> ''),
+    operator_id BIGINT NOT NULL REFERENCES users(id) ON DELETE RESTRICT ON UPDATE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE balance_adjustments IS 'Audit trail of manual balance corrections made by support staff.';
+COMMENT ON COLUMN balance_adjustments.ledger_id IS 'ADJUSTMENT ledger entry created by this correction.';
+COMMENT ON COLUMN balance_adjustments.operator_id IS 'Support or admin user who made the correction.';
+CREATE INDEX idx_balance_adjustments_user_id ON balance_adjustments(user_id);
+
+CREATE INDEX idx_ledger_user_id_created_at ON ledger(user_id, created_at, id);
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_login_check;
+
+UPDATE users SET login = 'deleted-' || id WHERE login IS NULL;
+
+ALTER TABLE users ALTER COLUMN login SET NOT NULL;
+
+COMMENT ON COLUMN users.login IS NULL;
+
+COMMIT;
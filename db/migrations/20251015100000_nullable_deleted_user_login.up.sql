@@ -0,0 +1,12 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE users ALTER COLUMN login DROP NOT NULL;
+
+UPDATE users SET login = NULL WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE users
+    ADD CONSTRAINT users_login_check CHECK (login IS NOT NULL OR deleted_at IS NOT NULL);
+
+COMMENT ON COLUMN users.login IS 'Unique login, NULL for closed accounts so the name can be registered again.';
+
+COMMIT;
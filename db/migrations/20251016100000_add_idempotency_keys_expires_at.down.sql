@@ -0,0 +1,8 @@
+BEGIN TRANSACTION;
+
+DROP INDEX IF EXISTS idx_idempotency_keys_expires_at;
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys(created_at);
+
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS expires_at;
+
+COMMIT;
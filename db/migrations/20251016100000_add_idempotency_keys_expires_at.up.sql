@@ -0,0 +1,14 @@
+BEGIN TRANSACTION;
+
+ALTER TABLE idempotency_keys
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+
+UPDATE idempotency_keys
+    SET expires_at = created_at + CASE WHEN status_code = 0 THEN INTERVAL '1 minute' ELSE INTERVAL '24 hours' END;
+
+COMMENT ON COLUMN idempotency_keys.expires_at IS 'Short lease while the request is in progress, replay retention once completed.';
+
+DROP INDEX IF EXISTS idx_idempotency_keys_created_at;
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN TRANSACTION;
+
+DROP INDEX IF EXISTS idx_outbox_unpublished;
+
+COMMIT;
@@ -0,0 +1,202 @@
+// Package breaker приостанавливает запросы к системе расчета начислений, пока она недоступна.
+// После FailureThreshold ошибок подряд breaker открывается на OpenTimeout, затем пропускает
+// по одному пробному запросу и закрывается после SuccessThreshold успешных подряд.
+package breaker
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+type Config struct {
+	FailureThreshold int
+	SuccessThreshold int
+	OpenTimeout      time.Duration
+}
+
+// State Failures ошибки подряд в текущем состоянии, Trips число открытий.
+type State struct {
+	State    string    `json:"state"`
+	Failures int       `json:"failures"`
+	OpenedAt time.Time `json:"opened_at,omitzero"`
+	RetryAt  time.Time `json:"retry_at,omitzero"`
+	Trips    uint64    `json:"trips"`
+}
+
+type Breaker struct {
+	mu        sync.Mutex
+	config    Config
+	state     string
+	failures  int
+	successes int
+	probing   bool
+	openedAt  time.Time
+	trips     uint64
+	changed   chan struct{}
+	now       func() time.Time
+}
+
+func New(cfg Config) *Breaker {
+	return &Breaker{
+		config:  cfg,
+		state:   StateClosed,
+		changed: make(chan struct{}),
+		now:     time.Now,
+	}
+}
+
+// Wait ждет, пока breaker пропустит запрос, или отмену ctx.
+// После Wait обязателен вызов Success, Failure или Cancel.
+func (b *Breaker) Wait(ctx context.Context) error {
+	for {
+		wait, changed, ok := b.allow()
+		if ok {
+			return nil
+		}
+
+		// В half-open ждем результата пробного запроса без таймаута.
+		var (
+			timer   *time.Timer
+			timeout <-chan time.Time
+		)
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			timeout = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-changed:
+		case <-timeout:
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		b.failures = 0
+	case StateHalfOpen:
+		b.probing = false
+		b.successes++
+		if b.successes >= b.config.SuccessThreshold {
+			b.setState(StateClosed)
+		} else {
+			b.notify()
+		}
+	}
+}
+
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		b.failures++
+		if b.failures >= b.config.FailureThreshold {
+			b.open()
+		}
+	case StateHalfOpen:
+		b.probing = false
+		b.failures++
+		b.open()
+	}
+}
+
+// Cancel освобождает пропуск, если запрос не был отправлен или прерван остановкой сервиса:
+// результат не учитывается, а в half-open следующий запрос может стать пробным.
+func (b *Breaker) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateHalfOpen && b.probing {
+		b.probing = false
+		b.notify()
+	}
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.halfOpenIfReady()
+
+	state := State{State: b.state, Failures: b.failures, Trips: b.trips}
+	if b.state != StateClosed {
+		state.OpenedAt = b.openedAt
+	}
+	if b.state == StateOpen {
+		state.RetryAt = b.openedAt.Add(b.config.OpenTimeout)
+	}
+
+	return state
+}
+
+// allow пропускает запрос или возвращает время до перехода в half-open
+// и канал, который закроется при смене состояния.
+func (b *Breaker) allow() (time.Duration, <-chan struct{}, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.halfOpenIfReady()
+
+	switch b.state {
+	case StateOpen:
+		return b.openedAt.Add(b.config.OpenTimeout).Sub(b.now()), b.changed, false
+	case StateHalfOpen:
+		if b.probing {
+			return 0, b.changed, false
+		}
+		b.probing = true
+	}
+
+	return 0, nil, true
+}
+
+func (b *Breaker) halfOpenIfReady() {
+	if b.state == StateOpen && !b.now().Before(b.openedAt.Add(b.config.OpenTimeout)) {
+		b.setState(StateHalfOpen)
+	}
+}
+
+func (b *Breaker) open() {
+	b.openedAt = b.now()
+	b.trips++
+	b.setState(StateOpen)
+}
+
+func (b *Breaker) setState(state string) {
+	if state == StateClosed {
+		b.failures = 0
+	}
+	b.successes = 0
+	b.state = state
+	b.notify()
+
+	log.Printf("accrual circuit breaker %s", state)
+}
+
+// notify будит ожидающих в Wait.
+func (b *Breaker) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
@@ -0,0 +1,155 @@
+package breaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = Config{FailureThreshold: 3, SuccessThreshold: 2, OpenTimeout: 30 * time.Second}
+
+func newTestBreaker(now *time.Time) *Breaker {
+	b := New(testConfig)
+	b.now = func() time.Time { return *now }
+	return b
+}
+
+func TestBreaker_open(t *testing.T) {
+	now := time.Date(2025, 10, 11, 10, 0, 0, 0, time.UTC)
+	b := newTestBreaker(&now)
+
+	b.Failure()
+	b.Failure()
+	b.Success()
+	b.Failure()
+	b.Failure()
+	assert.Equal(t, State{State: StateClosed, Failures: 2}, b.State(), "Success resets failures")
+
+	b.Failure()
+	assert.Equal(t, State{
+		State:    StateOpen,
+		Failures: 3,
+		OpenedAt: now,
+		RetryAt:  now.Add(30 * time.Second),
+		Trips:    1,
+	}, b.State(), "Opened after threshold")
+
+	wait, _, ok := b.allow()
+	assert.False(t, ok, "Request rejected")
+	assert.Equal(t, 30*time.Second, wait, "Wait for open timeout")
+}
+
+func TestBreaker_halfOpen(t *testing.T) {
+	opened := time.Date(2025, 10, 11, 10, 0, 0, 0, time.UTC)
+	now := opened
+	b := newTestBreaker(&now)
+	for range 3 {
+		b.Failure()
+	}
+
+	now = now.Add(30 * time.Second)
+	_, _, ok := b.allow()
+	require.True(t, ok, "Probe allowed after timeout")
+
+	wait, changed, ok := b.allow()
+	assert.False(t, ok, "Single probe at a time")
+	assert.Equal(t, time.Duration(0), wait, "Wait for probe result")
+
+	b.Success()
+	select {
+	case <-changed:
+	default:
+		t.Error("Waiters are not notified on probe result")
+	}
+	assert.Equal(t, StateHalfOpen, b.State().State, "Not enough successes")
+
+	_, _, ok = b.allow()
+	require.True(t, ok, "Second probe allowed")
+	b.Success()
+	assert.Equal(t, State{State: StateClosed, Trips: 1}, b.State(), "Closed after successes")
+
+	now = now.Add(time.Second)
+	for range 3 {
+		b.Failure()
+	}
+	now = now.Add(30 * time.Second)
+	_, _, ok = b.allow()
+	require.True(t, ok, "Probe allowed after timeout")
+	b.Failure()
+	assert.Equal(t, State{
+		State:    StateOpen,
+		Failures: 4,
+		OpenedAt: now,
+		RetryAt:  now.Add(30 * time.Second),
+		Trips:    3,
+	}, b.State(), "Failed probe opens again")
+}
+
+func TestBreaker_Cancel(t *testing.T) {
+	now := time.Date(2025, 10, 11, 10, 0, 0, 0, time.UTC)
+	b := newTestBreaker(&now)
+
+	b.Cancel()
+	assert.Equal(t, State{State: StateClosed}, b.State(), "Cancel in closed state changes nothing")
+
+	for range 3 {
+		b.Failure()
+	}
+	now = now.Add(30 * time.Second)
+	_, _, ok := b.allow()
+	require.True(t, ok, "Probe allowed after timeout")
+	_, changed, ok := b.allow()
+	require.False(t, ok, "Single probe at a time")
+
+	b.Cancel()
+	select {
+	case <-changed:
+	default:
+		t.Error("Waiters are not notified on cancelled probe")
+	}
+	assert.Equal(t, StateHalfOpen, b.State().State, "Cancelled probe is not counted")
+
+	_, _, ok = b.allow()
+	require.True(t, ok, "Next probe allowed after cancel")
+	b.Success()
+	_, _, ok = b.allow()
+	require.True(t, ok, "Second probe allowed")
+	b.Success()
+	assert.Equal(t, StateClosed, b.State().State, "Closed after successes")
+}
+
+func TestBreaker_Wait(t *testing.T) {
+	b := New(Config{FailureThreshold: 1, SuccessThreshold: 1, OpenTimeout: 10 * time.Millisecond})
+
+	require.Nil(t, b.Wait(context.Background()), "Closed breaker")
+	b.Failure()
+
+	require.Nil(t, b.Wait(context.Background()), "Probe after open timeout")
+
+	done := make(chan error)
+	go func() {
+		done <- b.Wait(context.Background())
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Second request passed while probe is running")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	b.Success()
+	select {
+	case err := <-done:
+		assert.Nil(t, err, "Request after successful probe")
+	case <-time.After(time.Second):
+		t.Fatal("Waiter is not woken up")
+	}
+
+	b.Failure()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, b.Wait(ctx), context.Canceled, "Cancelled while open")
+}
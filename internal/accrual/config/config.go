@@ -1,9 +1,23 @@
 package config
 
+// Config ProcessDelay основа экспоненциальной задержки повторов, RetryJitter в процентах.
+// WorkerID отличает экземпляр сервиса в очереди заказов,
+// по умолчанию составляется из имени хоста и pid.
 type Config struct {
 	AccrualAddr         string
 	RateLimit           uint64
+	RequestsPerSecond   uint64
+	Burst               uint64
+	BreakerFailures     uint64
+	BreakerSuccesses    uint64
+	BreakerTimeout      uint64
 	ProcessDelay        uint64
 	PollInterval        uint64
 	UnregisteredRetries uint64
+	FailedRetries       uint64
+	MaxRetryDelay       uint64
+	RetryJitter         uint64
+	BatchSize           uint64
+	LeaseDuration       uint64
+	WorkerID            string
 }
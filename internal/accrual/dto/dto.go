@@ -1,8 +1,11 @@
 package dto
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
 )
 
 var ErrInvalidResponseData = errors.New("invalid response data")
@@ -15,9 +18,35 @@ const (
 )
 
 type Order struct {
-	Number  string  `json:"order"`
-	Status  string  `json:"status"`
-	Accrual float64 `json:"accrual,omitempty"`
+	Number  string       `json:"order"`
+	Status  string       `json:"status"`
+	Accrual money.Amount `json:"accrual,omitempty"`
+}
+
+// UnmarshalJSON округляет начисление до сотых: система расчета может прислать
+// больше знаков, и это не ошибка ответа.
+func (o *Order) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Number  string      `json:"order"`
+		Status  string      `json:"status"`
+		Accrual json.Number `json:"accrual"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*o = Order{Number: raw.Number, Status: raw.Status}
+	if raw.Accrual == "" {
+		return nil
+	}
+
+	accrual, err := money.ParseRound(raw.Accrual.String())
+	if err != nil {
+		return fmt.Errorf("%w: invalid order accrual", ErrInvalidResponseData)
+	}
+	o.Accrual = accrual
+
+	return nil
 }
 
 func (o Order) Validate(sentNumber string) error {
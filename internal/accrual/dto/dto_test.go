@@ -1,7 +1,11 @@
 package dto
 
 import (
+	"encoding/json"
+	"errors"
 	"testing"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
 )
 
 func TestOrder_Validate(t *testing.T) {
@@ -17,7 +21,7 @@ func TestOrder_Validate(t *testing.T) {
 			order: Order{
 				Number:  "5062821234567892",
 				Status:  OrderStatusProcessed,
-				Accrual: 13.13,
+				Accrual: money.New(13, 13),
 			},
 			wantErr: false,
 		},
@@ -54,7 +58,7 @@ func TestOrder_Validate(t *testing.T) {
 			order: Order{
 				Number:  "5062821234567892",
 				Status:  OrderStatusProcessed,
-				Accrual: 13.13,
+				Accrual: money.New(13, 13),
 			},
 			wantErr: true,
 		},
@@ -64,7 +68,7 @@ func TestOrder_Validate(t *testing.T) {
 			order: Order{
 				Number:  "5062821234567892",
 				Status:  "UNSUPORTED",
-				Accrual: 13.13,
+				Accrual: money.New(13, 13),
 			},
 			wantErr: true,
 		},
@@ -74,7 +78,7 @@ func TestOrder_Validate(t *testing.T) {
 			order: Order{
 				Number:  "5062821234567892",
 				Status:  "UNSUPORTED",
-				Accrual: -13.13,
+				Accrual: money.New(-13, -13),
 			},
 			wantErr: true,
 		},
@@ -94,3 +98,51 @@ func TestOrder_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestOrder_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    Order
+		wantErr error
+	}{
+		{
+			name: "cents",
+			body: `{"order":"5062821234567892","status":"PROCESSED","accrual":500.5}`,
+			want: Order{Number: "5062821234567892", Status: OrderStatusProcessed, Accrual: money.New(500, 50)},
+		},
+		{
+			name: "rounded",
+			body: `{"order":"5062821234567892","status":"PROCESSED","accrual":729.987}`,
+			want: Order{Number: "5062821234567892", Status: OrderStatusProcessed, Accrual: money.New(729, 99)},
+		},
+		{
+			name: "no_accrual",
+			body: `{"order":"5062821234567892","status":"REGISTERED"}`,
+			want: Order{Number: "5062821234567892", Status: OrderStatusRegistred},
+		},
+		{
+			name:    "negative_out_of_range",
+			body:    `{"order":"5062821234567892","status":"PROCESSED","accrual":1e30}`,
+			wantErr: ErrInvalidResponseData,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Order
+			err := json.Unmarshal([]byte(tt.body), &got)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,13 +5,19 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/dto"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/metrics"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/requestid"
 )
 
 const (
@@ -19,6 +25,8 @@ const (
 	consumersTimeout = time.Duration(60 * time.Second)
 )
 
+var tracer = otel.Tracer("github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/processor")
+
 var (
 	ErrAccrualInternalError        = errors.New("internal server error")
 	ErrAccrualUnexpectedStatusCode = errors.New("unexpected status code")
@@ -27,67 +35,149 @@ var (
 type ProcessingService interface {
 	ProsessOrder(ctx context.Context, order dto.Order)
 	MarkOrderForRetry(ctx context.Context, number string)
+	MarkOrderFailed(ctx context.Context, number string, reason error)
+}
+
+type Limiter interface {
+	Wait(ctx context.Context) error
+	Pause(d time.Duration)
+	SetRate(rate float64)
+}
+
+// Breaker после Wait ждет результат запроса: Failure при недоступности системы расчета, иначе Success.
+// Cancel, если запрос не отправлен или прерван остановкой сервиса.
+type Breaker interface {
+	Wait(ctx context.Context) error
+	Success()
+	Failure()
+	Cancel()
 }
 
 type Logger interface {
-	Error(message string, err error)
-	Warn(message string, err error)
+	Error(message string, err error, fields ...logger.Field)
+	Warn(message string, err error, fields ...logger.Field)
 }
 
+// rateHint лимит, который система расчета сообщает в теле ответа 429.
+var rateHint = regexp.MustCompile(`No more than (\d+) requests per minute allowed`)
+
 type OrderConsumer struct {
 	service ProcessingService
+	limiter Limiter
+	breaker Breaker
 	logger  Logger
 	client  *resty.Client
 	address string
 }
 
-func NewOrderConsumer(srv ProcessingService, l Logger, serverAddr string) *OrderConsumer {
+func NewOrderConsumer(
+	srv ProcessingService,
+	lim Limiter,
+	br Breaker,
+	l Logger,
+	serverAddr string,
+) *OrderConsumer {
 	serverAddr = strings.Trim(serverAddr, "/")
 
 	return &OrderConsumer{
 		service: srv,
+		limiter: lim,
+		breaker: br,
 		logger:  l,
 		address: serverAddr,
+		// Транспорт otelhttp создает клиентские спаны и передает traceparent системе расчета.
 		client: resty.New().
+			SetTransport(otelhttp.NewTransport(http.DefaultTransport)).
 			SetTimeout(time.Duration(1) * time.Second).
 			SetBaseURL(serverAddr + "/" + pathPrefix),
 	}
 }
 
-func (c *OrderConsumer) Consume(ctx context.Context, sleepAll chan<- time.Duration, number string) {
+func (c *OrderConsumer) Consume(ctx context.Context, number string) {
+	ctx, span := tracer.Start(ctx, "OrderConsumer.Consume")
+	defer span.End()
+
+	// Опрос идет вне HTTP запроса, поэтому идентификатор создается на каждое обращение:
+	// он попадает в логи обработки заказа и передается системе расчета.
+	id := requestid.FromContext(ctx)
+	if id == "" {
+		id = requestid.New()
+		ctx = requestid.NewContext(ctx, id)
+	}
+
 	var order dto.Order
+
+	if err := c.breaker.Wait(ctx); err != nil {
+		return
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		c.breaker.Cancel()
+		return
+	}
+
 	req := c.client.R().
 		SetContext(ctx).
+		SetHeader(requestid.Header, id).
 		SetResult(&order)
 
 	resp, err := req.Get(number)
+	if err != nil && resp != nil && resp.RawResponse != nil {
+		// Ответ получен, но тело не разобрано: система расчета доступна, ошибка в данных заказа.
+		metrics.AccrualResponse(resp.StatusCode())
+		c.breaker.Success()
+		c.logger.Warn("invalid accrual service responce data", err, logger.OrderNumber(number), logger.Request(ctx))
+		c.service.MarkOrderFailed(ctx, number, err)
+		return
+	}
 	if err != nil {
-		c.logger.Warn("failed to request accrual servise", err)
+		metrics.AccrualResponse(0)
+		// Запрос, прерванный остановкой сервиса, не говорит о недоступности системы расчета.
+		if ctx.Err() == nil {
+			c.breaker.Failure()
+			c.service.MarkOrderFailed(ctx, number, err)
+		} else {
+			c.breaker.Cancel()
+		}
+		c.logger.Warn("failed to request accrual servise", err, logger.OrderNumber(number), logger.Request(ctx))
 		return
 	}
 
-	switch code := resp.StatusCode(); code {
-	case http.StatusOK:
+	metrics.AccrualResponse(resp.StatusCode())
+	// Любой ответ 5xx, в том числе 502-504 от прокси перед системой расчета, говорит о ее недоступности.
+	code := resp.StatusCode()
+	serverError := code >= http.StatusInternalServerError
+	if serverError {
+		c.breaker.Failure()
+	} else {
+		c.breaker.Success()
+	}
+
+	switch {
+	case serverError:
+		c.logger.Warn("accrual service internal error", ErrAccrualInternalError,
+			logger.OrderNumber(number), logger.Request(ctx))
+		c.service.MarkOrderFailed(ctx, number, ErrAccrualInternalError)
+	case code == http.StatusOK:
 		if err := order.Validate(number); err == nil {
 			c.service.ProsessOrder(ctx, order)
 		} else {
-			c.logger.Warn("invalid accrual service responce data", err)
-			c.service.MarkOrderForRetry(ctx, number)
+			c.logger.Warn("invalid accrual service responce data", err, logger.OrderNumber(number), logger.Request(ctx))
+			c.service.MarkOrderFailed(ctx, number, err)
 		}
-	case http.StatusNoContent:
+	case code == http.StatusNoContent:
 		c.service.MarkOrderForRetry(ctx, number)
-	case http.StatusTooManyRequests:
-		select {
-		case sleepAll <- c.parseDelay(resp):
-		default:
+	case code == http.StatusTooManyRequests:
+		delay := c.parseDelay(resp)
+		c.limiter.Pause(delay)
+		metrics.AccrualThrottled(delay)
+		if m := rateHint.FindStringSubmatch(resp.String()); m != nil {
+			perMinute, _ := strconv.ParseFloat(m[1], 64)
+			c.limiter.SetRate(perMinute / 60)
 		}
-	case http.StatusInternalServerError:
-		c.logger.Warn("accrual service internal error", ErrAccrualInternalError)
 	default:
-		c.logger.Error(
-			"unexpected accrual service responce code",
-			fmt.Errorf("%w: %d", ErrAccrualUnexpectedStatusCode, code),
-		)
+		err := fmt.Errorf("%w: %d", ErrAccrualUnexpectedStatusCode, code)
+		c.logger.Error("unexpected accrual service responce code", err, logger.OrderNumber(number), logger.Request(ctx))
+		c.service.MarkOrderFailed(ctx, number, err)
 	}
 }
 
@@ -96,7 +186,7 @@ func (c *OrderConsumer) parseDelay(resp *resty.Response) time.Duration {
 
 	v, err := strconv.ParseUint(delay, 10, 64)
 	if err != nil {
-		c.logger.Warn("failed to parse retry-after header", err)
+		c.logger.Warn("failed to parse retry-after header", err, logger.Request(resp.Request.Context()))
 		return consumersTimeout
 	}
 
@@ -9,6 +9,8 @@ import (
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/dto"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/processor/mocks"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/requestid"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -26,27 +28,32 @@ func TestOrderConsumer_Consume(t *testing.T) {
 	tests := []struct {
 		name     string
 		number   string
-		sleep    time.Duration
-		sleeping bool
+		pause    time.Duration
+		rate     float64
+		failure  bool
 		resp     accrualResponse
 		srvSetup func(t *testing.T) ProcessingService
 		lSetup   func(t *testing.T) Logger
 	}{
 		{
-			name:   "network_error",
-			number: "5062821234567819",
+			name:    "network_error",
+			number:  "5062821234567819",
+			failure: true,
 			resp: accrualResponse{
 				netErr: true,
 			},
 			srvSetup: func(t *testing.T) ProcessingService {
 				ctrl := gomock.NewController(t)
-				return mocks.NewMockProcessingService(ctrl)
+				service := mocks.NewMockProcessingService(ctrl)
+				service.EXPECT().
+					MarkOrderFailed(gomock.All(), "5062821234567819", gomock.Not(nil))
+				return service
 			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Warn("failed to request accrual servise", gomock.All())
+					Warn("failed to request accrual servise", gomock.All(), gomock.Any())
 				return logger
 			},
 		},
@@ -69,7 +76,7 @@ func TestOrderConsumer_Consume(t *testing.T) {
 					ProsessOrder(gomock.All(), dto.Order{
 						Number:  "5062821234567892",
 						Status:  dto.OrderStatusProcessed,
-						Accrual: 500,
+						Accrual: money.New(500, 0),
 					})
 				return service
 			},
@@ -78,6 +85,53 @@ func TestOrderConsumer_Consume(t *testing.T) {
 				return mocks.NewMockLogger(ctrl)
 			},
 		},
+		{
+			name:   "valid_response_data_rounded",
+			number: "5062821234567892",
+			resp: accrualResponse{
+				status:      http.StatusOK,
+				contentType: "application/json",
+				body:        `{"order": "5062821234567892", "status": "PROCESSED", "accrual": 729.987}`,
+			},
+			srvSetup: func(t *testing.T) ProcessingService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockProcessingService(ctrl)
+				service.EXPECT().
+					ProsessOrder(gomock.All(), dto.Order{
+						Number:  "5062821234567892",
+						Status:  dto.OrderStatusProcessed,
+						Accrual: money.New(729, 99),
+					})
+				return service
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				return mocks.NewMockLogger(ctrl)
+			},
+		},
+		{
+			name:   "malformed_response_body",
+			number: "5062821234567892",
+			resp: accrualResponse{
+				status:      http.StatusOK,
+				contentType: "application/json",
+				body:        `{"order": "5062821234567892", "status": "PROCESSED", "accrual": "many"}`,
+			},
+			srvSetup: func(t *testing.T) ProcessingService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockProcessingService(ctrl)
+				service.EXPECT().
+					MarkOrderFailed(gomock.All(), "5062821234567892", gomock.Not(nil))
+				return service
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Warn("invalid accrual service responce data", gomock.All(), gomock.Any())
+				return logger
+			},
+		},
 		{
 			name:   "invalid_response_data",
 			number: "5062821234567892",
@@ -94,14 +148,14 @@ func TestOrderConsumer_Consume(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				service := mocks.NewMockProcessingService(ctrl)
 				service.EXPECT().
-					MarkOrderForRetry(gomock.All(), "5062821234567892")
+					MarkOrderFailed(gomock.All(), "5062821234567892", gomock.Not(nil))
 				return service
 			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Warn("invalid accrual service responce data", gomock.All())
+					Warn("invalid accrual service responce data", gomock.All(), gomock.Any())
 				return logger
 			},
 		},
@@ -126,7 +180,7 @@ func TestOrderConsumer_Consume(t *testing.T) {
 		{
 			name:   "to_many_request",
 			number: "5062821234567819",
-			sleep:  13 * time.Second,
+			pause:  13 * time.Second,
 			resp: accrualResponse{
 				status:     http.StatusTooManyRequests,
 				retryAfter: "13",
@@ -141,13 +195,15 @@ func TestOrderConsumer_Consume(t *testing.T) {
 			},
 		},
 		{
-			name:     "to_many_request_duplicate_signal",
-			number:   "5062821234567819",
-			sleep:    13 * time.Second,
-			sleeping: true,
+			name:   "to_many_request_rate_hint",
+			number: "5062821234567819",
+			pause:  60 * time.Second,
+			rate:   2,
 			resp: accrualResponse{
-				status:     http.StatusTooManyRequests,
-				retryAfter: "13",
+				status:      http.StatusTooManyRequests,
+				contentType: "text/plain",
+				retryAfter:  "60",
+				body:        "No more than 120 requests per minute allowed",
 			},
 			srvSetup: func(t *testing.T) ProcessingService {
 				ctrl := gomock.NewController(t)
@@ -161,7 +217,7 @@ func TestOrderConsumer_Consume(t *testing.T) {
 		{
 			name:   "to_many_request_bad_header",
 			number: "5062821234567819",
-			sleep:  consumersTimeout,
+			pause:  consumersTimeout,
 			resp: accrualResponse{
 				status:     http.StatusTooManyRequests,
 				retryAfter: "bad_header",
@@ -174,45 +230,94 @@ func TestOrderConsumer_Consume(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Warn("failed to parse retry-after header", gomock.All())
+					Warn("failed to parse retry-after header", gomock.All(), gomock.Any())
 				return logger
 			},
 		},
 		{
-			name:   "accrual_servise_internal_error",
-			number: "5062821234567819",
-			sleep:  consumersTimeout,
+			name:    "accrual_servise_internal_error",
+			number:  "5062821234567819",
+			failure: true,
 			resp: accrualResponse{
 				status: http.StatusInternalServerError,
 			},
 			srvSetup: func(t *testing.T) ProcessingService {
 				ctrl := gomock.NewController(t)
-				return mocks.NewMockProcessingService(ctrl)
+				service := mocks.NewMockProcessingService(ctrl)
+				service.EXPECT().
+					MarkOrderFailed(gomock.All(), "5062821234567819", ErrAccrualInternalError)
+				return service
 			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Warn("accrual service internal error", gomock.All())
+					Warn("accrual service internal error", gomock.All(), gomock.Any())
+				return logger
+			},
+		},
+		{
+			name:    "accrual_servise_unavailable",
+			number:  "5062821234567819",
+			failure: true,
+			resp: accrualResponse{
+				status: http.StatusServiceUnavailable,
+			},
+			srvSetup: func(t *testing.T) ProcessingService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockProcessingService(ctrl)
+				service.EXPECT().
+					MarkOrderFailed(gomock.All(), "5062821234567819", ErrAccrualInternalError)
+				return service
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Warn("accrual service internal error", gomock.All(), gomock.Any())
+				return logger
+			},
+		},
+		{
+			name:    "accrual_servise_bad_gateway",
+			number:  "5062821234567819",
+			failure: true,
+			resp: accrualResponse{
+				status: http.StatusBadGateway,
+			},
+			srvSetup: func(t *testing.T) ProcessingService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockProcessingService(ctrl)
+				service.EXPECT().
+					MarkOrderFailed(gomock.All(), "5062821234567819", ErrAccrualInternalError)
+				return service
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Warn("accrual service internal error", gomock.All(), gomock.Any())
 				return logger
 			},
 		},
 		{
 			name:   "unexpected_responce_staus_code",
 			number: "5062821234567819",
-			sleep:  consumersTimeout,
 			resp: accrualResponse{
 				status: http.StatusTeapot,
 			},
 			srvSetup: func(t *testing.T) ProcessingService {
 				ctrl := gomock.NewController(t)
-				return mocks.NewMockProcessingService(ctrl)
+				service := mocks.NewMockProcessingService(ctrl)
+				service.EXPECT().
+					MarkOrderFailed(gomock.All(), "5062821234567819", gomock.Not(nil))
+				return service
 			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Error("unexpected accrual service responce code", gomock.All())
+					Error("unexpected accrual service responce code", gomock.All(), gomock.Any())
 				return logger
 			},
 		},
@@ -236,23 +341,129 @@ func TestOrderConsumer_Consume(t *testing.T) {
 
 			service := test.srvSetup(t)
 			logger := test.lSetup(t)
-			sleepAll := make(chan time.Duration, 1)
-			defer close(sleepAll)
-
-			consumer := NewOrderConsumer(service, logger, server.URL)
-			if test.resp.netErr {
-				server.Close()
+			ctrl := gomock.NewController(t)
+			limiter := mocks.NewMockLimiter(ctrl)
+			limiter.EXPECT().Wait(gomock.All()).Return(nil)
+			if test.pause > 0 {
+				limiter.EXPECT().Pause(test.pause)
+			}
+			if test.rate > 0 {
+				limiter.EXPECT().SetRate(test.rate)
 			}
-			if test.sleeping {
-				sleepAll <- test.sleep
+
+			breaker := mocks.NewMockBreaker(ctrl)
+			breaker.EXPECT().Wait(gomock.All()).Return(nil)
+			if test.failure {
+				breaker.EXPECT().Failure()
+			} else {
+				breaker.EXPECT().Success()
 			}
-			consumer.Consume(context.Background(), sleepAll, test.number)
 
-			select {
-			case sleep := <-sleepAll:
-				assert.Equal(t, test.sleep, sleep, "Too many requests timeout")
-			default:
+			consumer := NewOrderConsumer(service, limiter, breaker, logger, server.URL)
+			if test.resp.netErr {
+				server.Close()
 			}
+			consumer.Consume(context.Background(), test.number)
 		})
 	}
 }
+
+func TestOrderConsumer_Consume_limiterCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request sent without limiter token")
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	limiter := mocks.NewMockLimiter(ctrl)
+	limiter.EXPECT().Wait(gomock.All()).Return(context.Canceled)
+	breaker := mocks.NewMockBreaker(ctrl)
+	breaker.EXPECT().Wait(gomock.All()).Return(nil)
+	breaker.EXPECT().Cancel()
+
+	consumer := NewOrderConsumer(
+		mocks.NewMockProcessingService(ctrl),
+		limiter,
+		breaker,
+		mocks.NewMockLogger(ctrl),
+		server.URL,
+	)
+	consumer.Consume(context.Background(), "5062821234567819")
+}
+
+func TestOrderConsumer_Consume_stopped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	limiter := mocks.NewMockLimiter(ctrl)
+	limiter.EXPECT().Wait(gomock.All()).Return(nil)
+	breaker := mocks.NewMockBreaker(ctrl)
+	breaker.EXPECT().Wait(gomock.All()).Return(nil)
+	breaker.EXPECT().Cancel()
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Warn("failed to request accrual servise", gomock.All(), gomock.Any())
+
+	consumer := NewOrderConsumer(
+		mocks.NewMockProcessingService(ctrl),
+		limiter,
+		breaker,
+		logger,
+		server.URL,
+	)
+	consumer.Consume(ctx, "5062821234567819")
+}
+
+func TestOrderConsumer_Consume_breakerOpen(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request sent while breaker is open")
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	breaker := mocks.NewMockBreaker(ctrl)
+	breaker.EXPECT().Wait(gomock.All()).Return(context.Canceled)
+
+	consumer := NewOrderConsumer(
+		mocks.NewMockProcessingService(ctrl),
+		mocks.NewMockLimiter(ctrl),
+		breaker,
+		mocks.NewMockLogger(ctrl),
+		server.URL,
+	)
+	consumer.Consume(context.Background(), "5062821234567819")
+}
+
+func TestOrderConsumer_Consume_requestID(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(requestid.Header)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	limiter := mocks.NewMockLimiter(ctrl)
+	limiter.EXPECT().Wait(gomock.All()).Return(nil)
+	breaker := mocks.NewMockBreaker(ctrl)
+	breaker.EXPECT().Wait(gomock.All()).Return(nil)
+	breaker.EXPECT().Success()
+
+	var processed string
+	service := mocks.NewMockProcessingService(ctrl)
+	service.EXPECT().
+		MarkOrderForRetry(gomock.All(), "5062821234567819").
+		Do(func(ctx context.Context, number string) {
+			processed = requestid.FromContext(ctx)
+		})
+
+	consumer := NewOrderConsumer(service, limiter, breaker, mocks.NewMockLogger(ctrl), server.URL)
+	consumer.Consume(context.Background(), "5062821234567819")
+
+	assert.True(t, requestid.Valid(received), "Request id sent to accrual service")
+	assert.Equal(t, received, processed, "Request id in processing context")
+}
@@ -7,8 +7,10 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	dto "github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/dto"
+	logger "github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -35,6 +37,18 @@ func (m *MockProcessingService) EXPECT() *MockProcessingServiceMockRecorder {
 	return m.recorder
 }
 
+// MarkOrderFailed mocks base method.
+func (m *MockProcessingService) MarkOrderFailed(ctx context.Context, number string, reason error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "MarkOrderFailed", ctx, number, reason)
+}
+
+// MarkOrderFailed indicates an expected call of MarkOrderFailed.
+func (mr *MockProcessingServiceMockRecorder) MarkOrderFailed(ctx, number, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOrderFailed", reflect.TypeOf((*MockProcessingService)(nil).MarkOrderFailed), ctx, number, reason)
+}
+
 // MarkOrderForRetry mocks base method.
 func (m *MockProcessingService) MarkOrderForRetry(ctx context.Context, number string) {
 	m.ctrl.T.Helper()
@@ -59,6 +73,140 @@ func (mr *MockProcessingServiceMockRecorder) ProsessOrder(ctx, order interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProsessOrder", reflect.TypeOf((*MockProcessingService)(nil).ProsessOrder), ctx, order)
 }
 
+// MockLimiter is a mock of Limiter interface.
+type MockLimiter struct {
+	ctrl     *gomock.Controller
+	recorder *MockLimiterMockRecorder
+}
+
+// MockLimiterMockRecorder is the mock recorder for MockLimiter.
+type MockLimiterMockRecorder struct {
+	mock *MockLimiter
+}
+
+// NewMockLimiter creates a new mock instance.
+func NewMockLimiter(ctrl *gomock.Controller) *MockLimiter {
+	mock := &MockLimiter{ctrl: ctrl}
+	mock.recorder = &MockLimiterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLimiter) EXPECT() *MockLimiterMockRecorder {
+	return m.recorder
+}
+
+// Pause mocks base method.
+func (m *MockLimiter) Pause(d time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Pause", d)
+}
+
+// Pause indicates an expected call of Pause.
+func (mr *MockLimiterMockRecorder) Pause(d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pause", reflect.TypeOf((*MockLimiter)(nil).Pause), d)
+}
+
+// SetRate mocks base method.
+func (m *MockLimiter) SetRate(rate float64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetRate", rate)
+}
+
+// SetRate indicates an expected call of SetRate.
+func (mr *MockLimiterMockRecorder) SetRate(rate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRate", reflect.TypeOf((*MockLimiter)(nil).SetRate), rate)
+}
+
+// Wait mocks base method.
+func (m *MockLimiter) Wait(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Wait", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Wait indicates an expected call of Wait.
+func (mr *MockLimiterMockRecorder) Wait(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Wait", reflect.TypeOf((*MockLimiter)(nil).Wait), ctx)
+}
+
+// MockBreaker is a mock of Breaker interface.
+type MockBreaker struct {
+	ctrl     *gomock.Controller
+	recorder *MockBreakerMockRecorder
+}
+
+// MockBreakerMockRecorder is the mock recorder for MockBreaker.
+type MockBreakerMockRecorder struct {
+	mock *MockBreaker
+}
+
+// NewMockBreaker creates a new mock instance.
+func NewMockBreaker(ctrl *gomock.Controller) *MockBreaker {
+	mock := &MockBreaker{ctrl: ctrl}
+	mock.recorder = &MockBreakerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBreaker) EXPECT() *MockBreakerMockRecorder {
+	return m.recorder
+}
+
+// Cancel mocks base method.
+func (m *MockBreaker) Cancel() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Cancel")
+}
+
+// Cancel indicates an expected call of Cancel.
+func (mr *MockBreakerMockRecorder) Cancel() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cancel", reflect.TypeOf((*MockBreaker)(nil).Cancel))
+}
+
+// Failure mocks base method.
+func (m *MockBreaker) Failure() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Failure")
+}
+
+// Failure indicates an expected call of Failure.
+func (mr *MockBreakerMockRecorder) Failure() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Failure", reflect.TypeOf((*MockBreaker)(nil).Failure))
+}
+
+// Success mocks base method.
+func (m *MockBreaker) Success() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Success")
+}
+
+// Success indicates an expected call of Success.
+func (mr *MockBreakerMockRecorder) Success() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Success", reflect.TypeOf((*MockBreaker)(nil).Success))
+}
+
+// Wait mocks base method.
+func (m *MockBreaker) Wait(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Wait", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Wait indicates an expected call of Wait.
+func (mr *MockBreakerMockRecorder) Wait(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Wait", reflect.TypeOf((*MockBreaker)(nil).Wait), ctx)
+}
+
 // MockLogger is a mock of Logger interface.
 type MockLogger struct {
 	ctrl     *gomock.Controller
@@ -83,25 +231,35 @@ func (m *MockLogger) EXPECT() *MockLoggerMockRecorder {
 }
 
 // Error mocks base method.
-func (m *MockLogger) Error(message string, err error) {
+func (m *MockLogger) Error(message string, err error, fields ...logger.Field) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Error", message, err)
+	varargs := []interface{}{message, err}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Error", varargs...)
 }
 
 // Error indicates an expected call of Error.
-func (mr *MockLoggerMockRecorder) Error(message, err interface{}) *gomock.Call {
+func (mr *MockLoggerMockRecorder) Error(message, err interface{}, fields ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Error", reflect.TypeOf((*MockLogger)(nil).Error), message, err)
+	varargs := append([]interface{}{message, err}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Error", reflect.TypeOf((*MockLogger)(nil).Error), varargs...)
 }
 
 // Warn mocks base method.
-func (m *MockLogger) Warn(message string, err error) {
+func (m *MockLogger) Warn(message string, err error, fields ...logger.Field) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Warn", message, err)
+	varargs := []interface{}{message, err}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Warn", varargs...)
 }
 
 // Warn indicates an expected call of Warn.
-func (mr *MockLoggerMockRecorder) Warn(message, err interface{}) *gomock.Call {
+func (mr *MockLoggerMockRecorder) Warn(message, err interface{}, fields ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Warn", reflect.TypeOf((*MockLogger)(nil).Warn), message, err)
+	varargs := append([]interface{}{message, err}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Warn", reflect.TypeOf((*MockLogger)(nil).Warn), varargs...)
 }
@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: queue.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockProducer is a mock of Producer interface.
+type MockProducer struct {
+	ctrl     *gomock.Controller
+	recorder *MockProducerMockRecorder
+}
+
+// MockProducerMockRecorder is the mock recorder for MockProducer.
+type MockProducerMockRecorder struct {
+	mock *MockProducer
+}
+
+// NewMockProducer creates a new mock instance.
+func NewMockProducer(ctrl *gomock.Controller) *MockProducer {
+	mock := &MockProducer{ctrl: ctrl}
+	mock.recorder = &MockProducerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProducer) EXPECT() *MockProducerMockRecorder {
+	return m.recorder
+}
+
+// ListToProccess mocks base method.
+func (m *MockProducer) ListToProccess(ctx context.Context, limit int) []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListToProccess", ctx, limit)
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// ListToProccess indicates an expected call of ListToProccess.
+func (mr *MockProducerMockRecorder) ListToProccess(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListToProccess", reflect.TypeOf((*MockProducer)(nil).ListToProccess), ctx, limit)
+}
+
+// Release mocks base method.
+func (m *MockProducer) Release(ctx context.Context, numbers []string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Release", ctx, numbers)
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockProducerMockRecorder) Release(ctx, numbers interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockProducer)(nil).Release), ctx, numbers)
+}
+
+// MockConsumer is a mock of Consumer interface.
+type MockConsumer struct {
+	ctrl     *gomock.Controller
+	recorder *MockConsumerMockRecorder
+}
+
+// MockConsumerMockRecorder is the mock recorder for MockConsumer.
+type MockConsumerMockRecorder struct {
+	mock *MockConsumer
+}
+
+// NewMockConsumer creates a new mock instance.
+func NewMockConsumer(ctrl *gomock.Controller) *MockConsumer {
+	mock := &MockConsumer{ctrl: ctrl}
+	mock.recorder = &MockConsumerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConsumer) EXPECT() *MockConsumerMockRecorder {
+	return m.recorder
+}
+
+// Consume mocks base method.
+func (m *MockConsumer) Consume(ctx context.Context, number string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Consume", ctx, number)
+}
+
+// Consume indicates an expected call of Consume.
+func (mr *MockConsumerMockRecorder) Consume(ctx, number interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockConsumer)(nil).Consume), ctx, number)
+}
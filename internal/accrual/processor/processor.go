@@ -2,9 +2,15 @@ package processor
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"os"
+	"time"
 
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/breaker"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/config"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/ratelimit"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository/pg"
@@ -12,24 +18,73 @@ import (
 )
 
 type Processor struct {
-	config *config.Config
-	db     *pg.DB
-	logger *logger.Logger
-	queue  *MessageBroker
+	config  *config.Config
+	db      *pg.DB
+	logger  *logger.Logger
+	limiter *ratelimit.Limiter
+	breaker *breaker.Breaker
+	queue   *MessageBroker
 }
 
 func New(c *config.Config, db *pg.DB, l *logger.Logger) *Processor {
-	return &Processor{config: c, db: db, logger: l}
+	return &Processor{
+		config:  c,
+		db:      db,
+		logger:  l,
+		limiter: ratelimit.New(float64(c.RequestsPerSecond), int(c.Burst)),
+		breaker: breaker.New(breaker.Config{
+			FailureThreshold: int(c.BreakerFailures),
+			SuccessThreshold: int(c.BreakerSuccesses),
+			OpenTimeout:      time.Duration(c.BreakerTimeout) * time.Second,
+		}),
+	}
+}
+
+// LimiterState и BreakerState нужны для мониторинга запросов к системе расчета.
+func (p *Processor) LimiterState() ratelimit.State {
+	return p.limiter.State()
+}
+
+func (p *Processor) BreakerState() breaker.State {
+	return p.breaker.State()
+}
+
+// QueueDepth до запуска процессора очередь пуста.
+func (p *Processor) QueueDepth() int {
+	if p.queue == nil {
+		return 0
+	}
+	return p.queue.QueueDepth()
+}
+
+// Consumers до запуска процессора обработчиков нет.
+func (p *Processor) Consumers() int {
+	if p.queue == nil {
+		return 0
+	}
+	return p.queue.Consumers()
 }
 
 func (p *Processor) Run(ctx context.Context) {
-	processRepository := repository.NewProcessing(
-		p.db,
-		p.config.ProcessDelay,
-		p.config.UnregisteredRetries,
+	processRepository := repository.NewProcessing(p.db, entity.Backoff{
+		Base:   time.Duration(p.config.ProcessDelay) * time.Second,
+		Max:    time.Duration(p.config.MaxRetryDelay) * time.Second,
+		Jitter: float64(p.config.RetryJitter) / 100,
+	})
+	processService := service.NewProcessing(
+		processRepository,
+		p.logger,
+		entity.OrderLease{
+			Owner:    workerID(p.config.WorkerID),
+			Limit:    int(p.config.BatchSize),
+			Duration: time.Duration(p.config.LeaseDuration) * time.Second,
+		},
+		service.RetryLimits{
+			Unregistered: p.config.UnregisteredRetries,
+			Failed:       p.config.FailedRetries,
+		},
 	)
-	processService := service.NewProcessing(processRepository, p.logger)
-	consumer := NewOrderConsumer(processService, p.logger, p.config.AccrualAddr)
+	consumer := NewOrderConsumer(processService, p.limiter, p.breaker, p.logger, p.config.AccrualAddr)
 
 	p.queue = NewMessageBroker(processService, consumer, *p.config)
 	p.queue.Run(ctx)
@@ -45,3 +100,16 @@ func (p *Processor) Stop() {
 	p.queue.Stop()
 	log.Println("acciral processor stopped")
 }
+
+func workerID(configured string) string {
+	if configured != "" {
+		return configured
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "gophermart"
+	}
+
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
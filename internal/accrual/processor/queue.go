@@ -3,44 +3,43 @@ package processor
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/config"
 )
 
 type Producer interface {
-	ListToProccess(ctx context.Context) []string
+	ListToProccess(ctx context.Context, limit int) []string
+	Release(ctx context.Context, numbers []string)
 }
 
 type Consumer interface {
-	Consume(ctx context.Context, sleepAll chan<- time.Duration, number string)
+	Consume(ctx context.Context, number string)
 }
 
 type MessageBroker struct {
-	producer    Producer
-	consumer    Consumer
-	config      config.Config
-	queueIn     chan string
-	queueOut    <-chan string
-	sleepSignal chan time.Duration
-	haltSignal  chan struct{}
-	stopped     chan struct{}
-	mainCtx     context.Context
-	mainCancel  context.CancelFunc
+	producer   Producer
+	consumer   Consumer
+	config     config.Config
+	queue      chan string
+	haltSignal chan struct{}
+	stopped    chan struct{}
+	mainCtx    context.Context
+	mainCancel context.CancelFunc
+	running    atomic.Int32
+	busy       atomic.Int32
 }
 
 func NewMessageBroker(p Producer, c Consumer, cfg config.Config) *MessageBroker {
 	b := &MessageBroker{
-		producer:    p,
-		consumer:    c,
-		config:      cfg,
-		queueIn:     make(chan string, int(cfg.RateLimit)),
-		sleepSignal: make(chan time.Duration, 1),
+		producer: p,
+		consumer: c,
+		config:   cfg,
+		queue:    make(chan string, int(cfg.RateLimit)),
 	}
 
 	b.mainCtx, b.mainCancel = context.WithCancel(context.Background())
-	q := newQueue(b.queueIn, b.sleepSignal)
-	b.queueOut = q.Out()
 
 	return b
 }
@@ -52,6 +51,16 @@ func (b *MessageBroker) Run(shutdownCxt context.Context) {
 	go b.process()
 }
 
+// QueueDepth число заказов, выданных производителем, но еще не взятых обработчиками.
+func (b *MessageBroker) QueueDepth() int {
+	return len(b.queue)
+}
+
+// Consumers число работающих обработчиков, после остановки 0.
+func (b *MessageBroker) Consumers() int {
+	return int(b.running.Load())
+}
+
 func (b *MessageBroker) Stop() {
 	select {
 	case <-b.mainCtx.Done():
@@ -83,20 +92,50 @@ func (b *MessageBroker) process() {
 	for {
 		select {
 		case <-b.haltSignal:
+			b.release(nil)
 			return
 		case <-time.After(produseInterval):
 		}
 
-		numbers := b.producer.ListToProccess(b.mainCtx)
-		for _, number := range numbers {
+		free := b.free()
+		if free <= 0 {
+			continue
+		}
+
+		numbers := b.producer.ListToProccess(b.mainCtx, free)
+		for i, number := range numbers {
 			select {
 			case <-b.haltSignal:
+				b.release(numbers[i:])
 				return
-			default:
+			case b.queue <- number:
 			}
+		}
+	}
+}
+
+// free берется не больше заказов, чем успеют разобрать свободные обработчики
+// и вместит очередь, чтобы аренда не истекала, пока заказ ждет обработчика.
+func (b *MessageBroker) free() int {
+	idle := int(b.config.RateLimit) - int(b.busy.Load())
+	return idle + cap(b.queue) - len(b.queue)
+}
 
-			b.queueIn <- number
+// release остаток пачки и ожидающие в очереди заказы сразу достанутся
+// другим экземплярам, не дожидаясь конца аренды.
+func (b *MessageBroker) release(numbers []string) {
+	for {
+		select {
+		case number := <-b.queue:
+			numbers = append(numbers, number)
+			continue
+		default:
 		}
+		break
+	}
+
+	if len(numbers) > 0 {
+		b.producer.Release(b.mainCtx, numbers)
 	}
 }
 
@@ -108,7 +147,9 @@ func (b *MessageBroker) runConsumers() {
 
 	for range count {
 		go func() {
+			b.running.Add(1)
 			b.consume()
+			b.running.Add(-1)
 			wg.Done()
 		}()
 	}
@@ -122,79 +163,11 @@ func (b *MessageBroker) consume() {
 		select {
 		case <-b.haltSignal:
 			return
-		case number := <-b.queueOut:
-			b.consumer.Consume(b.mainCtx, b.sleepSignal, number)
+		case number := <-b.queue:
+			b.busy.Add(1)
+			b.consumer.Consume(b.mainCtx, number)
+			b.busy.Add(-1)
 		case <-time.After(time.Millisecond):
 		}
 	}
 }
-
-type queue struct {
-	in    <-chan string
-	out   chan string
-	sleep <-chan time.Duration
-	mx    *sync.RWMutex
-}
-
-func newQueue(in <-chan string, sleep <-chan time.Duration) *queue {
-	q := &queue{
-		in:    in,
-		out:   make(chan string),
-		sleep: sleep,
-		mx:    &sync.RWMutex{},
-	}
-
-	go q.sleepHandler()
-	go q.process()
-
-	return q
-}
-
-func (q *queue) Out() <-chan string {
-	return q.out
-}
-
-func (q *queue) sleepHandler() {
-	q.mx.Lock()
-	until := time.Now()
-	interval := time.Millisecond
-	for {
-		now := time.Now()
-		select {
-		case interval = <-q.sleep:
-			u := now.Add(interval)
-			if until.Before(u) {
-				until = u
-			}
-
-			if q.mx.TryRLock() {
-				q.mx.RUnlock()
-				q.mx.Lock()
-			}
-		case <-time.After(interval):
-			if now.Before(until) {
-				interval = until.Sub(now)
-			} else {
-				interval = time.Millisecond
-
-				if q.mx.TryRLock() {
-					q.mx.RUnlock()
-				} else {
-					q.mx.Unlock()
-				}
-			}
-		}
-	}
-}
-
-func (q *queue) process() {
-	for {
-		select {
-		case <-time.After(10 * time.Millisecond):
-		case msg := <-q.in:
-			q.mx.RLock()
-			q.out <- msg
-			q.mx.RUnlock()
-		}
-	}
-}
@@ -0,0 +1,85 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/config"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/processor/mocks"
+)
+
+func TestMessageBroker_free(t *testing.T) {
+	tests := []struct {
+		name   string
+		busy   int32
+		queued []string
+		want   int
+	}{
+		{name: "idle", want: 6},
+		{name: "busy_consumers", busy: 2, want: 4},
+		{name: "queued_orders", busy: 1, queued: []string{"5062821234567892", "5062821234567819"}, want: 3},
+		{name: "full", busy: 3, queued: []string{"1", "2", "3"}, want: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := NewMessageBroker(nil, nil, config.Config{RateLimit: 3})
+			b.busy.Store(test.busy)
+			for _, number := range test.queued {
+				b.queue <- number
+			}
+
+			assert.Equal(t, test.want, b.free(), "Free capacity")
+		})
+	}
+}
+
+func TestMessageBroker_release(t *testing.T) {
+	tests := []struct {
+		name    string
+		pending []string
+		queued  []string
+		want    []string
+	}{
+		{
+			name:    "pending_and_queued",
+			pending: []string{"5062821234567892"},
+			queued:  []string{"5062821234567819", "5062821234567827"},
+			want:    []string{"5062821234567892", "5062821234567819", "5062821234567827"},
+		},
+		{
+			name:   "queued_only",
+			queued: []string{"5062821234567819"},
+			want:   []string{"5062821234567819"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			producer := mocks.NewMockProducer(ctrl)
+			producer.EXPECT().
+				Release(gomock.All(), test.want)
+
+			b := NewMessageBroker(producer, nil, config.Config{RateLimit: 3})
+			for _, number := range test.queued {
+				b.queue <- number
+			}
+
+			b.release(test.pending)
+			assert.Zero(t, b.QueueDepth(), "Queue is drained")
+		})
+	}
+
+	t.Run("nothing_to_release", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		producer := mocks.NewMockProducer(ctrl)
+		producer.EXPECT().
+			Release(gomock.All(), gomock.All()).
+			Times(0)
+
+		NewMessageBroker(producer, nil, config.Config{RateLimit: 3}).release(nil)
+	})
+}
@@ -0,0 +1,161 @@
+// Package ratelimit ограничивает частоту запросов к системе расчета начислений (token bucket).
+// Лимит подстраивается под ответы 429: Retry-After приостанавливает выдачу токенов,
+// а указанный в ответе лимит снижает скорость их пополнения. Без новых ответов 429
+// сниженная скорость постепенно возвращается к исходной.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// recoverAfter период без ответов 429, после которого сниженная скорость удваивается.
+const recoverAfter = time.Minute
+
+// State Rate в запросах в секунду, Throttled число полученных ответов 429.
+type State struct {
+	Rate        float64   `json:"rate"`
+	MaxRate     float64   `json:"max_rate"`
+	Burst       int       `json:"burst"`
+	Tokens      float64   `json:"tokens"`
+	PausedUntil time.Time `json:"paused_until,omitzero"`
+	Throttled   uint64    `json:"throttled"`
+}
+
+type Limiter struct {
+	mu          sync.Mutex
+	rate        float64
+	maxRate     float64
+	burst       int
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+	throttledAt time.Time
+	throttled   uint64
+	now         func() time.Time
+}
+
+// New создает заполненный bucket, rate не может превысить заданный при создании.
+func New(rate float64, burst int) *Limiter {
+	return &Limiter{
+		rate:    rate,
+		maxRate: rate,
+		burst:   burst,
+		tokens:  float64(burst),
+		last:    time.Now(),
+		now:     time.Now,
+	}
+}
+
+// Wait ждет свободный токен или отмену ctx.
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		wait := l.reserve()
+		if wait == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Pause останавливает выдачу токенов на d, после паузы bucket наполняется с нуля.
+func (l *Limiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := l.now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+	l.tokens = 0
+	l.last = l.pausedUntil
+	l.throttledAt = l.pausedUntil
+	l.throttled++
+}
+
+// SetRate устанавливает лимит, сообщенный системой расчета, но не выше исходного.
+func (l *Limiter) SetRate(rate float64) {
+	if rate <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.refill(now)
+	l.rate = min(rate, l.maxRate)
+	l.throttledAt = maxTime(now, l.pausedUntil)
+}
+
+func (l *Limiter) State() State {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.refill(now)
+
+	state := State{
+		Rate:      l.rate,
+		MaxRate:   l.maxRate,
+		Burst:     l.burst,
+		Tokens:    l.tokens,
+		Throttled: l.throttled,
+	}
+	if now.Before(l.pausedUntil) {
+		state.PausedUntil = l.pausedUntil
+	}
+
+	return state
+}
+
+// reserve забирает токен или возвращает время до появления следующего.
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+
+	l.refill(now)
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+
+	return max(time.Duration((1-l.tokens)/l.rate*float64(time.Second)), time.Nanosecond)
+}
+
+func (l *Limiter) refill(now time.Time) {
+	if !now.After(l.last) {
+		return
+	}
+
+	l.tokens = min(float64(l.burst), l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.recover(now)
+}
+
+// recover удваивает сниженную скорость за каждый recoverAfter без ответов 429.
+func (l *Limiter) recover(now time.Time) {
+	for l.rate < l.maxRate && now.Sub(l.throttledAt) >= recoverAfter {
+		l.rate = min(l.rate*2, l.maxRate)
+		l.throttledAt = l.throttledAt.Add(recoverAfter)
+	}
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLimiter(rate float64, burst int, now *time.Time) *Limiter {
+	l := New(rate, burst)
+	l.now = func() time.Time { return *now }
+	l.last = *now
+	return l
+}
+
+func TestLimiter_reserve(t *testing.T) {
+	now := time.Date(2025, 10, 11, 10, 0, 0, 0, time.UTC)
+	l := newTestLimiter(2, 3, &now)
+
+	for i := range 3 {
+		assert.Equal(t, time.Duration(0), l.reserve(), "Burst token %d", i)
+	}
+	assert.Equal(t, 500*time.Millisecond, l.reserve(), "Wait for next token")
+
+	now = now.Add(time.Second)
+	assert.Equal(t, time.Duration(0), l.reserve(), "Refilled token")
+	assert.Equal(t, time.Duration(0), l.reserve(), "Refilled token")
+	assert.Equal(t, 500*time.Millisecond, l.reserve(), "Bucket is empty again")
+
+	now = now.Add(time.Hour)
+	assert.Equal(t, float64(3), l.State().Tokens, "Refill is capped by burst")
+}
+
+func TestLimiter_Pause(t *testing.T) {
+	now := time.Date(2025, 10, 11, 10, 0, 0, 0, time.UTC)
+	l := newTestLimiter(2, 3, &now)
+
+	l.Pause(13 * time.Second)
+	l.Pause(5 * time.Second)
+
+	assert.Equal(t, 13*time.Second, l.reserve(), "Longest pause wins")
+	assert.Equal(t, State{
+		Rate:        2,
+		MaxRate:     2,
+		Burst:       3,
+		Tokens:      0,
+		PausedUntil: now.Add(13 * time.Second),
+		Throttled:   2,
+	}, l.State(), "Paused state")
+
+	now = now.Add(13 * time.Second)
+	assert.Equal(t, 500*time.Millisecond, l.reserve(), "Bucket refills from zero after pause")
+
+	now = now.Add(500 * time.Millisecond)
+	assert.Equal(t, time.Duration(0), l.reserve(), "Token after pause")
+}
+
+func TestLimiter_SetRate(t *testing.T) {
+	now := time.Date(2025, 10, 11, 10, 0, 0, 0, time.UTC)
+	l := newTestLimiter(10, 1, &now)
+
+	l.SetRate(2)
+	assert.Equal(t, float64(2), l.State().Rate, "Lowered rate")
+
+	l.SetRate(100)
+	assert.Equal(t, float64(10), l.State().Rate, "Rate is capped by configured")
+
+	l.SetRate(0)
+	assert.Equal(t, float64(10), l.State().Rate, "Zero rate ignored")
+}
+
+func TestLimiter_recover(t *testing.T) {
+	now := time.Date(2025, 10, 11, 10, 0, 0, 0, time.UTC)
+	l := newTestLimiter(10, 1, &now)
+
+	l.Pause(30 * time.Second)
+	l.SetRate(2)
+
+	now = now.Add(80 * time.Second)
+	assert.Equal(t, float64(2), l.State().Rate, "Rate is kept during quiet period after pause")
+
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, float64(4), l.State().Rate, "Rate doubled after quiet period")
+
+	l.SetRate(3)
+	now = now.Add(59 * time.Second)
+	assert.Equal(t, float64(3), l.State().Rate, "New 429 restarts quiet period")
+
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, float64(10), l.State().Rate, "Rate is restored up to configured")
+
+	now = now.Add(time.Hour)
+	assert.Equal(t, float64(10), l.State().Rate, "Rate does not exceed configured")
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	l := New(1000, 1)
+
+	assert.Nil(t, l.Wait(context.Background()), "Token from burst")
+	assert.Nil(t, l.Wait(context.Background()), "Token after refill")
+
+	l.Pause(time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, l.Wait(ctx), context.Canceled, "Cancelled while paused")
+}
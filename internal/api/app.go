@@ -8,24 +8,79 @@ import (
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/router"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/config"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/events"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/jwtkeys"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/password"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/poller"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository/pg"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/service"
 )
 
+const (
+	idempotencyCleanupInterval   = time.Minute
+	loginAttemptsCleanupInterval = time.Minute
+)
+
+// Processor состояние обработчика начислений для мониторинга и проб готовности.
+type Processor interface {
+	router.AccrualMonitor
+	service.ProcessorMonitor
+}
+
 type App struct {
-	config *config.Config
-	logger *logger.Logger
-	db     *pg.DB
+	config  *config.Config
+	logger  *logger.Logger
+	db      *pg.DB
+	keys    *jwtkeys.KeySet
+	accrual Processor
 }
 
-func NewApp(c *config.Config, db *pg.DB, l *logger.Logger) *App {
-	return &App{config: c, db: db, logger: l}
+func NewApp(
+	c *config.Config,
+	db *pg.DB,
+	k *jwtkeys.KeySet,
+	p Processor,
+	l *logger.Logger,
+) *App {
+	return &App{config: c, db: db, keys: k, accrual: p, logger: l}
 }
 
 func (a *App) Run(ctx context.Context) error {
-	srv := &http.Server{Addr: a.config.ServerAddr, Handler: a.newRouter()}
+	// Хаб останавливается по ctx раньше сервера и закрывает потоки событий,
+	// иначе они задержали бы Shutdown.
+	hub := events.NewHub(repository.NewEvents(a.db), a.logger)
+	hub.Run(ctx)
+	defer hub.Stop()
+
+	// Устаревшие ключи идемпотентности удаляются в фоне, а не при каждом запросе.
+	idempotency := service.NewIdempotency(
+		repository.NewIdempotency(a.db),
+		a.logger,
+		time.Duration(a.config.IdempotencyTTL)*time.Hour,
+	)
+	cleanup := poller.New("idempotency keys cleanup", idempotencyCleanupInterval, idempotency.Cleanup)
+	cleanup.Run(ctx)
+	defer cleanup.Stop()
+
+	// Забытые счетчики неудачных входов тоже удаляются в фоне, а не при каждой неудаче.
+	var attemptsRepository service.LoginAttemptRepository = repository.NewLoginAttempts(a.db)
+	if a.config.LoginAttempts == config.LoginAttemptsStoreMemory {
+		attemptsRepository = repository.NewMemoryLoginAttempts()
+	}
+	throttle := service.NewLoginThrottle(
+		attemptsRepository,
+		a.logger,
+		service.DefaultLoginPolicy,
+		service.DefaultIPPolicy,
+	)
+	attemptsCleanup := poller.New("login attempts cleanup", loginAttemptsCleanupInterval, throttle.Cleanup)
+	attemptsCleanup.Run(ctx)
+	defer attemptsCleanup.Stop()
+
+	health := service.NewHealth(repository.NewHealth(a.db), a.accrual, a.db.Migration())
+	srv := &http.Server{Addr: a.config.ServerAddr, Handler: a.newRouter(hub, health, idempotency, throttle)}
 	errChan := make(chan error)
 
 	go func() {
@@ -43,6 +98,14 @@ func (a *App) Run(ctx context.Context) error {
 	}
 
 	<-ctx.Done()
+	// Проба готовности начинает отвечать 503 до остановки сервера, и сервер еще
+	// ShutdownDrain секунд принимает запросы, чтобы балансировщик успел опросить пробу
+	// и перестал направлять сюда новые запросы.
+	health.Drain()
+	if drain := time.Duration(a.config.ShutdownDrain) * time.Second; drain > 0 {
+		log.Printf("draining http server for %s\n", drain)
+		time.Sleep(drain)
+	}
 	log.Println("shutting down http server gracefully")
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer func() {
@@ -53,22 +116,55 @@ func (a *App) Run(ctx context.Context) error {
 	return srv.Shutdown(shutdownCtx)
 }
 
-func (a *App) newRouter() http.Handler {
+func (a *App) newRouter(
+	hub service.EventHub,
+	health router.HealthService,
+	idempotency router.IdempotencyService,
+	throttle service.Throttler,
+) http.Handler {
 	userRepository := repository.NewUser(a.db)
 	orderRepository := repository.NewOrder(a.db)
 	balanceRepository := repository.NewBalance(a.db)
 	withdrawalsRepository := repository.NewWithdrawals(a.db)
+	tokenRepository := repository.NewToken(a.db)
+	webhookRepository := repository.NewWebhook(a.db)
+	adminOrderRepository := repository.NewAdminOrder(a.db)
 
-	authService := service.NewAuth(userRepository, a.logger, a.config.JWTsecret)
+	authService := service.NewAuth(
+		userRepository,
+		tokenRepository,
+		password.New(password.DefaultParams),
+		throttle,
+		a.logger,
+		a.keys,
+		service.TokenTTL{
+			Access:  time.Duration(a.config.AccessTokenTTL) * time.Minute,
+			Refresh: time.Duration(a.config.RefreshTokenTTL) * time.Hour,
+		},
+	)
 	orderService := service.NewOrder(orderRepository, a.logger)
 	balanceService := service.NewBalance(balanceRepository, a.logger)
-	withdrawalsService := service.NewWithdrawals(withdrawalsRepository, a.logger)
+	withdrawalsService := service.NewWithdrawals(
+		withdrawalsRepository,
+		a.logger,
+		a.config.WithdrawOrderCap,
+	)
+	webhookService := service.NewWebhook(webhookRepository, a.logger)
+	eventsService := service.NewEvents(hub, a.logger)
+	adminOrderService := service.NewAdminOrder(adminOrderRepository, a.logger)
 
 	return router.New(
 		authService,
 		orderService,
 		balanceService,
 		withdrawalsService,
+		idempotency,
+		webhookService,
+		eventsService,
+		a.accrual,
+		adminOrderService,
+		health,
+		a.config.AdminToken,
 		a.logger,
 	)
 }
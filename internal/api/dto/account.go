@@ -0,0 +1,10 @@
+package dto
+
+type PasswordChange struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+type AccountDeletion struct {
+	Password string `json:"password"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+import (
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/breaker"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/ratelimit"
+)
+
+// AccrualState состояние клиента системы расчета начислений для мониторинга.
+type AccrualState struct {
+	Limiter ratelimit.State `json:"limiter"`
+	Breaker breaker.State   `json:"breaker"`
+}
@@ -1,6 +1,45 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+)
+
 type Balance struct {
-	Current   float64 `json:"current"`
-	Withdrawn float64 `json:"withdrawn"`
+	Current   money.Amount `json:"current"`
+	Withdrawn money.Amount `json:"withdrawn"`
+}
+
+// LedgerEntry операция по балансу, Order заполнен для начислений и списаний,
+// Reason для ручных корректировок.
+type LedgerEntry struct {
+	Kind    string       `json:"kind"`
+	Amount  money.Amount `json:"amount"`
+	Order   string       `json:"order,omitempty"`
+	Reason  string       `json:"reason,omitempty"`
+	Created time.Time    `json:"created_at"`
+}
+
+type LedgerPage struct {
+	Entries    []LedgerEntry
+	NextCursor string
+}
+
+// BalanceAdjustmentReq положительная сумма начисляет баллы, отрицательная списывает.
+type BalanceAdjustmentReq struct {
+	Amount money.Amount `json:"amount"`
+	Reason string       `json:"reason"`
+}
+
+// BalanceAdjustment Current и Withdrawn баланс пользователя после корректировки.
+type BalanceAdjustment struct {
+	ID         uint64       `json:"id"`
+	UserID     uint64       `json:"user_id"`
+	Amount     money.Amount `json:"amount"`
+	Reason     string       `json:"reason"`
+	OperatorID uint64       `json:"operator_id"`
+	Current    money.Amount `json:"current"`
+	Withdrawn  money.Amount `json:"withdrawn"`
+	Created    time.Time    `json:"created_at"`
 }
@@ -0,0 +1,15 @@
+package dto
+
+import "github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+
+// Event событие потока /api/user/events, Type передается в поле event.
+type Event struct {
+	Type string
+	Data any
+}
+
+type OrderEvent struct {
+	Number  string        `json:"number"`
+	Status  string        `json:"status"`
+	Accrual *money.Amount `json:"accrual,omitempty"`
+}
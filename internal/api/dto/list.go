@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// ListQuery общие параметры постраничных списков.
+// Период задается полуинтервалом [From, To).
+type ListQuery struct {
+	Limit  int
+	Cursor string
+	Sort   string
+	From   time.Time
+	To     time.Time
+}
@@ -1,10 +1,57 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+)
 
 type Order struct {
-	Number   string    `json:"number"`
-	Status   string    `json:"status"`
-	Accrual  *float64  `json:"accrual,omitempty"`
-	Uploaded time.Time `json:"uploaded_at"`
+	Number   string        `json:"number"`
+	Status   string        `json:"status"`
+	Accrual  *money.Amount `json:"accrual,omitempty"`
+	Uploaded time.Time     `json:"uploaded_at"`
+}
+
+// OrderQuery параметры списка заказов, нулевые значения не ограничивают выборку.
+type OrderQuery struct {
+	ListQuery
+	Statuses []string
+}
+
+type OrderPage struct {
+	Orders     []Order
+	NextCursor string
+}
+
+// AdminOrder заказ со служебными полями обработки.
+type AdminOrder struct {
+	Number       string        `json:"number"`
+	UserID       uint64        `json:"user_id"`
+	Status       string        `json:"status"`
+	Accrual      *money.Amount `json:"accrual,omitempty"`
+	Attempts     int           `json:"attempts"`
+	LastError    string        `json:"last_error,omitempty"`
+	Uploaded     time.Time     `json:"uploaded_at"`
+	Updated      time.Time     `json:"updated_at"`
+	NextAttempt  *time.Time    `json:"next_attempt_at,omitempty"`
+	LeaseOwner   string        `json:"lease_owner,omitempty"`
+	LeaseExpires *time.Time    `json:"lease_expires_at,omitempty"`
+}
+
+// AdminOrderQuery параметры поиска заказов всех пользователей.
+type AdminOrderQuery struct {
+	ListQuery
+	Number   string
+	UserID   uint64
+	Statuses []string
+}
+
+type AdminOrderPage struct {
+	Orders     []AdminOrder
+	NextCursor string
+}
+
+type AdminOrderStatusReq struct {
+	Status string `json:"status"`
 }
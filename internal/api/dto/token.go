@@ -0,0 +1,18 @@
+package dto
+
+import "github.com/EshkinKot1980/gophermart-loyalty/internal/jwtkeys"
+
+type AuthTokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type RefreshToken struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type JWKS struct {
+	Keys []jwtkeys.JWK `json:"keys"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+type WebhookReq struct {
+	URL string `json:"url"`
+}
+
+// Webhook секрет для проверки подписи отдается только при создании.
+type Webhook struct {
+	ID      uint64    `json:"id"`
+	URL     string    `json:"url"`
+	Secret  string    `json:"secret,omitempty"`
+	Created time.Time `json:"created_at"`
+}
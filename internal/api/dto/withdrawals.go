@@ -1,14 +1,30 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+)
 
 type Withdrawals struct {
-	Order string  `json:"order"`
-	Sum   float64 `json:"sum"`
+	Order string       `json:"order"`
+	Sum   money.Amount `json:"sum"`
 }
 
 type WithdrawalsResp struct {
-	Order     string    `json:"order"`
-	Sum       float64   `json:"sum"`
-	Processed time.Time `json:"processed_at"`
+	Order     string       `json:"order"`
+	Sum       money.Amount `json:"sum"`
+	Processed time.Time    `json:"processed_at"`
+}
+
+// WithdrawalsQuery параметры списка списаний, нулевые значения не ограничивают выборку.
+type WithdrawalsQuery struct {
+	ListQuery
+	MinSum *money.Amount
+	MaxSum *money.Amount
+}
+
+type WithdrawalsPage struct {
+	Withdrawals []WithdrawalsResp
+	NextCursor  string
 }
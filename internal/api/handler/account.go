@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+)
+
+type AccountService interface {
+	ChangePassword(ctx context.Context, c dto.PasswordChange, ip string) error
+	DeleteAccount(ctx context.Context, c dto.AccountDeletion, ip string) error
+}
+
+type Account struct {
+	service AccountService
+}
+
+func NewAccount(srv AccountService) *Account {
+	return &Account{service: srv}
+}
+
+func (h *Account) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.PasswordChange
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	err := h.service.ChangePassword(r.Context(), req, clientIP(r))
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Account) Delete(w http.ResponseWriter, r *http.Request) {
+	var req dto.AccountDeletion
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	err := h.service.DeleteAccount(r.Context(), req, clientIP(r))
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func writeAccountError(w http.ResponseWriter, err error) {
+	var retryErr *srvErrors.RetryError
+	switch {
+	case errors.As(err, &retryErr):
+		tooManyRequests(w, retryErr)
+	case errors.Is(err, srvErrors.ErrAuthWrongPassword):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, srvErrors.ErrAuthInvalidCredentials):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, srvErrors.ErrUserNotFound):
+		http.Error(w, "", http.StatusUnauthorized)
+	default:
+		http.Error(w, statusText500, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/handler/mocks"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+)
+
+func TestAccount_ChangePassword(t *testing.T) {
+	request := dto.PasswordChange{OldPassword: "t1estP5assword", NewPassword: "n2ewP6assword"}
+	requestJSON := `{"old_password":"t1estP5assword","new_password":"n2ewP6assword"}`
+
+	type want struct {
+		code       int
+		body       string
+		retryAfter string
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		setup func(t *testing.T) AccountService
+		want  want
+	}{
+		{
+			name: "success",
+			body: requestJSON,
+			setup: func(t *testing.T) AccountService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAccountService(ctrl)
+				service.EXPECT().
+					ChangePassword(gomock.All(), request, "192.0.2.1").
+					Return(nil)
+				return service
+			},
+			want: want{
+				code: http.StatusOK,
+				body: "",
+			},
+		},
+		{
+			name: "negative_bad_json",
+			body: `not valid jsson`,
+			setup: func(t *testing.T) AccountService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAccountService(ctrl)
+				service.EXPECT().
+					ChangePassword(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return service
+			},
+			want: want{
+				code: http.StatusBadRequest,
+				body: "invalid request format",
+			},
+		},
+		{
+			name: "negative_wrong_old_password",
+			body: requestJSON,
+			setup: func(t *testing.T) AccountService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAccountService(ctrl)
+				service.EXPECT().
+					ChangePassword(gomock.All(), request, "192.0.2.1").
+					Return(errors.ErrAuthWrongPassword)
+				return service
+			},
+			want: want{
+				code: http.StatusForbidden,
+				body: errors.ErrAuthWrongPassword.Error(),
+			},
+		},
+		{
+			name: "negative_invalid_new_password",
+			body: requestJSON,
+			setup: func(t *testing.T) AccountService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAccountService(ctrl)
+				service.EXPECT().
+					ChangePassword(gomock.All(), request, "192.0.2.1").
+					Return(fmt.Errorf("%w: password is empty", errors.ErrAuthInvalidCredentials))
+				return service
+			},
+			want: want{
+				code: http.StatusBadRequest,
+				body: "invalid credentials: password is empty",
+			},
+		},
+		{
+			name: "negative_too_many_attempts",
+			body: requestJSON,
+			setup: func(t *testing.T) AccountService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAccountService(ctrl)
+				service.EXPECT().
+					ChangePassword(gomock.All(), request, "192.0.2.1").
+					Return(&errors.RetryError{Err: errors.ErrAuthTooManyAttempts, After: 1500 * time.Millisecond})
+				return service
+			},
+			want: want{
+				code:       http.StatusTooManyRequests,
+				body:       errors.ErrAuthTooManyAttempts.Error(),
+				retryAfter: "2",
+			},
+		},
+		{
+			name: "negative_user_not_found",
+			body: requestJSON,
+			setup: func(t *testing.T) AccountService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAccountService(ctrl)
+				service.EXPECT().
+					ChangePassword(gomock.All(), request, "192.0.2.1").
+					Return(errors.ErrUserNotFound)
+				return service
+			},
+			want: want{
+				code: http.StatusUnauthorized,
+				body: "",
+			},
+		},
+		{
+			name: "negative_server_error",
+			body: requestJSON,
+			setup: func(t *testing.T) AccountService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAccountService(ctrl)
+				service.EXPECT().
+					ChangePassword(gomock.All(), request, "192.0.2.1").
+					Return(errors.ErrUnexpected)
+				return service
+			},
+			want: want{
+				code: http.StatusInternalServerError,
+				body: statusText500,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewAccount(test.setup(t))
+
+			reqBody := []byte(test.body)
+			r := httptest.NewRequest(http.MethodPut, "/password", bytes.NewBuffer(reqBody))
+			r.RemoteAddr = "192.0.2.1:1234"
+
+			w := httptest.NewRecorder()
+			handler.ChangePassword(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want.code, res.StatusCode, "Response status code")
+			assert.Equal(t, test.want.retryAfter, res.Header.Get("Retry-After"), "Retry-After header")
+			resBody, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := strings.TrimSuffix(string(resBody), "\n")
+			assert.Equal(t, test.want.body, body, "Response body")
+		})
+	}
+}
+
+func TestAccount_Delete(t *testing.T) {
+	request := dto.AccountDeletion{Password: "t1estP5assword"}
+	requestJSON := `{"password":"t1estP5assword"}`
+
+	type want struct {
+		code int
+		body string
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		setup func(t *testing.T) AccountService
+		want  want
+	}{
+		{
+			name: "success",
+			body: requestJSON,
+			setup: func(t *testing.T) AccountService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAccountService(ctrl)
+				service.EXPECT().
+					DeleteAccount(gomock.All(), request, "192.0.2.1").
+					Return(nil)
+				return service
+			},
+			want: want{
+				code: http.StatusOK,
+				body: "",
+			},
+		},
+		{
+			name: "negative_bad_json",
+			body: "",
+			setup: func(t *testing.T) AccountService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAccountService(ctrl)
+				service.EXPECT().
+					DeleteAccount(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return service
+			},
+			want: want{
+				code: http.StatusBadRequest,
+				body: "invalid request format",
+			},
+		},
+		{
+			name: "negative_wrong_password",
+			body: requestJSON,
+			setup: func(t *testing.T) AccountService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAccountService(ctrl)
+				service.EXPECT().
+					DeleteAccount(gomock.All(), request, "192.0.2.1").
+					Return(errors.ErrAuthWrongPassword)
+				return service
+			},
+			want: want{
+				code: http.StatusForbidden,
+				body: errors.ErrAuthWrongPassword.Error(),
+			},
+		},
+		{
+			name: "negative_server_error",
+			body: requestJSON,
+			setup: func(t *testing.T) AccountService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAccountService(ctrl)
+				service.EXPECT().
+					DeleteAccount(gomock.All(), request, "192.0.2.1").
+					Return(errors.ErrUnexpected)
+				return service
+			},
+			want: want{
+				code: http.StatusInternalServerError,
+				body: statusText500,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewAccount(test.setup(t))
+
+			reqBody := []byte(test.body)
+			r := httptest.NewRequest(http.MethodDelete, "/", bytes.NewBuffer(reqBody))
+			r.RemoteAddr = "192.0.2.1:1234"
+
+			w := httptest.NewRecorder()
+			handler.Delete(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want.code, res.StatusCode, "Response status code")
+			resBody, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := strings.TrimSuffix(string(resBody), "\n")
+			assert.Equal(t, test.want.body, body, "Response body")
+		})
+	}
+}
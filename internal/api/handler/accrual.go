@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/breaker"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/ratelimit"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+)
+
+type AccrualMonitor interface {
+	LimiterState() ratelimit.State
+	BreakerState() breaker.State
+}
+
+type Accrual struct {
+	monitor AccrualMonitor
+	logger  Logger
+}
+
+func NewAccrual(m AccrualMonitor, l Logger) *Accrual {
+	return &Accrual{monitor: m, logger: l}
+}
+
+func (h *Accrual) State(w http.ResponseWriter, r *http.Request) {
+	state := dto.AccrualState{
+		Limiter: h.monitor.LimiterState(),
+		Breaker: h.monitor.BreakerState(),
+	}
+	newJSONwriter(w, r, h.logger).write(state, "accrual state", http.StatusOK)
+}
+
+// Health отвечает 503, пока circuit breaker открыт и заказы не отправляются на расчет.
+func (h *Accrual) Health(w http.ResponseWriter, r *http.Request) {
+	state := h.monitor.BreakerState()
+
+	status := http.StatusOK
+	if state.State == breaker.StateOpen {
+		status = http.StatusServiceUnavailable
+	}
+
+	newJSONwriter(w, r, h.logger).write(state, "accrual health", status)
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/breaker"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/ratelimit"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/handler/mocks"
+)
+
+func TestAccrual_State(t *testing.T) {
+	tests := []struct {
+		name    string
+		limiter ratelimit.State
+		breaker breaker.State
+		body    string
+	}{
+		{
+			name:    "healthy",
+			limiter: ratelimit.State{Rate: 10, MaxRate: 10, Burst: 10, Tokens: 7.5},
+			breaker: breaker.State{State: breaker.StateClosed},
+			body: `{"limiter":{"rate":10,"max_rate":10,"burst":10,"tokens":7.5,"throttled":0},` +
+				`"breaker":{"state":"closed","failures":0,"trips":0}}`,
+		},
+		{
+			name: "paused",
+			limiter: ratelimit.State{
+				Rate:        2,
+				MaxRate:     10,
+				Burst:       10,
+				PausedUntil: time.Date(2025, 10, 11, 10, 0, 0, 0, time.UTC),
+				Throttled:   3,
+			},
+			breaker: breaker.State{
+				State:    breaker.StateOpen,
+				Failures: 5,
+				OpenedAt: time.Date(2025, 10, 11, 9, 59, 50, 0, time.UTC),
+				RetryAt:  time.Date(2025, 10, 11, 10, 0, 20, 0, time.UTC),
+				Trips:    1,
+			},
+			body: `{"limiter":{"rate":2,"max_rate":10,"burst":10,"tokens":0,` +
+				`"paused_until":"2025-10-11T10:00:00Z","throttled":3},` +
+				`"breaker":{"state":"open","failures":5,"opened_at":"2025-10-11T09:59:50Z",` +
+				`"retry_at":"2025-10-11T10:00:20Z","trips":1}}`,
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			monitor := mocks.NewMockAccrualMonitor(gomock.NewController(t))
+			monitor.EXPECT().LimiterState().Return(test.limiter)
+			monitor.EXPECT().BreakerState().Return(test.breaker)
+			handler := NewAccrual(monitor, logger)
+
+			r := httptest.NewRequest(http.MethodGet, "/api/admin/accrual", nil)
+			w := httptest.NewRecorder()
+			handler.State(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, http.StatusOK, res.StatusCode, "Response status code")
+			resBody, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := strings.TrimSuffix(string(resBody), "\n")
+			assert.Equal(t, test.body, body, "Response body")
+		})
+	}
+}
+
+func TestAccrual_Health(t *testing.T) {
+	type want struct {
+		code int
+		body string
+	}
+
+	tests := []struct {
+		name  string
+		state breaker.State
+		want  want
+	}{
+		{
+			name:  "closed",
+			state: breaker.State{State: breaker.StateClosed, Failures: 2},
+			want: want{
+				code: http.StatusOK,
+				body: `{"state":"closed","failures":2,"trips":0}`,
+			},
+		},
+		{
+			name: "half_open",
+			state: breaker.State{
+				State:    breaker.StateHalfOpen,
+				OpenedAt: time.Date(2025, 10, 11, 9, 59, 50, 0, time.UTC),
+				Trips:    1,
+			},
+			want: want{
+				code: http.StatusOK,
+				body: `{"state":"half-open","failures":0,"opened_at":"2025-10-11T09:59:50Z","trips":1}`,
+			},
+		},
+		{
+			name: "negative_open",
+			state: breaker.State{
+				State:    breaker.StateOpen,
+				Failures: 5,
+				OpenedAt: time.Date(2025, 10, 11, 9, 59, 50, 0, time.UTC),
+				RetryAt:  time.Date(2025, 10, 11, 10, 0, 20, 0, time.UTC),
+				Trips:    1,
+			},
+			want: want{
+				code: http.StatusServiceUnavailable,
+				body: `{"state":"open","failures":5,"opened_at":"2025-10-11T09:59:50Z",` +
+					`"retry_at":"2025-10-11T10:00:20Z","trips":1}`,
+			},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			monitor := mocks.NewMockAccrualMonitor(gomock.NewController(t))
+			monitor.EXPECT().BreakerState().Return(test.state)
+			handler := NewAccrual(monitor, logger)
+
+			r := httptest.NewRequest(http.MethodGet, "/health/accrual", nil)
+			w := httptest.NewRecorder()
+			handler.Health(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want.code, res.StatusCode, "Response status code")
+			resBody, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := strings.TrimSuffix(string(resBody), "\n")
+			assert.Equal(t, test.want.body, body, "Response body")
+		})
+	}
+}
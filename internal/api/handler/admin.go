@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+)
+
+type AdminOrderService interface {
+	Search(ctx context.Context, q dto.AdminOrderQuery) (dto.AdminOrderPage, error)
+	Get(ctx context.Context, number string) (dto.AdminOrder, error)
+	Recheck(ctx context.Context, number string) (dto.AdminOrder, error)
+	SetStatus(ctx context.Context, number string, req dto.AdminOrderStatusReq) (dto.AdminOrder, error)
+}
+
+type Admin struct {
+	service AdminOrderService
+	logger  Logger
+}
+
+func NewAdmin(srv AdminOrderService, l Logger) *Admin {
+	return &Admin{service: srv, logger: l}
+}
+
+// Orders поддерживает параметры number, user_id, status и общие параметры списков.
+func (h *Admin) Orders(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	listQuery, err := parseListQuery(values)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := dto.AdminOrderQuery{
+		ListQuery: listQuery,
+		Number:    values.Get("number"),
+		Statuses:  listValues(values, "status"),
+	}
+	if s := values.Get("user_id"); s != "" {
+		query.UserID, err = strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid user_id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := h.service.Search(r.Context(), query)
+	if err != nil {
+		if errors.Is(err, srvErrors.ErrListInvalidQuery) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, statusText500, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	setNextPage(w, r, page.NextCursor)
+
+	if len(page.Orders) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	newJSONwriter(w, r, h.logger).write(page.Orders, "orders", http.StatusOK)
+}
+
+func (h *Admin) Order(w http.ResponseWriter, r *http.Request) {
+	order, err := h.service.Get(r.Context(), chi.URLParam(r, "number"))
+	if err != nil {
+		h.orderError(w, err)
+		return
+	}
+
+	newJSONwriter(w, r, h.logger).write(order, "order", http.StatusOK)
+}
+
+func (h *Admin) Recheck(w http.ResponseWriter, r *http.Request) {
+	order, err := h.service.Recheck(r.Context(), chi.URLParam(r, "number"))
+	if err != nil {
+		h.orderError(w, err)
+		return
+	}
+
+	newJSONwriter(w, r, h.logger).write(order, "order", http.StatusAccepted)
+}
+
+func (h *Admin) SetStatus(w http.ResponseWriter, r *http.Request) {
+	var req dto.AdminOrderStatusReq
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.service.SetStatus(r.Context(), chi.URLParam(r, "number"), req)
+	if err != nil {
+		h.orderError(w, err)
+		return
+	}
+
+	newJSONwriter(w, r, h.logger).write(order, "order", http.StatusOK)
+}
+
+func (h *Admin) orderError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, srvErrors.ErrOrderNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, srvErrors.ErrOrderStatusConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, srvErrors.ErrOrderInvalidStatus):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		http.Error(w, statusText500, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,375 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/handler/mocks"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+)
+
+var (
+	testAdminTime  = time.Date(2025, 10, 12, 10, 0, 0, 0, time.UTC)
+	testAdminOrder = dto.AdminOrder{
+		Number:      "5062821234567892",
+		UserID:      13,
+		Status:      "FAILED",
+		Attempts:    11,
+		LastError:   "internal server error",
+		Uploaded:    testAdminTime,
+		Updated:     testAdminTime,
+		NextAttempt: &testAdminTime,
+	}
+	testAdminOrderJSON = `{"number":"5062821234567892","user_id":13,"status":"FAILED","attempts":11,` +
+		`"last_error":"internal server error","uploaded_at":"2025-10-12T10:00:00Z",` +
+		`"updated_at":"2025-10-12T10:00:00Z","next_attempt_at":"2025-10-12T10:00:00Z"}`
+)
+
+func TestAdmin_Orders(t *testing.T) {
+	type want struct {
+		code int
+		body string
+		next string
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		setup func(t *testing.T) AdminOrderService
+		want  want
+	}{
+		{
+			name:  "success",
+			query: "?user_id=13&status=failed,new&limit=1",
+			setup: func(t *testing.T) AdminOrderService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAdminOrderService(ctrl)
+				service.EXPECT().
+					Search(gomock.All(), dto.AdminOrderQuery{
+						ListQuery: dto.ListQuery{Limit: 1},
+						UserID:    13,
+						Statuses:  []string{"failed", "new"},
+					}).
+					Return(dto.AdminOrderPage{Orders: []dto.AdminOrder{testAdminOrder}, NextCursor: "testCursor"}, nil)
+				return service
+			},
+			want: want{
+				code: http.StatusOK,
+				body: "[" + testAdminOrderJSON + "]",
+				next: "testCursor",
+			},
+		},
+		{
+			name:  "success_empty",
+			query: "?number=5062821234567819",
+			setup: func(t *testing.T) AdminOrderService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAdminOrderService(ctrl)
+				service.EXPECT().
+					Search(gomock.All(), dto.AdminOrderQuery{Number: "5062821234567819"}).
+					Return(dto.AdminOrderPage{}, nil)
+				return service
+			},
+			want: want{
+				code: http.StatusNoContent,
+				body: "",
+			},
+		},
+		{
+			name:  "negative_invalid_user_id",
+			query: "?user_id=admin",
+			setup: func(t *testing.T) AdminOrderService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAdminOrderService(ctrl)
+				service.EXPECT().
+					Search(gomock.All(), gomock.All()).
+					Times(0)
+				return service
+			},
+			want: want{
+				code: http.StatusBadRequest,
+				body: "invalid user_id",
+			},
+		},
+		{
+			name:  "negative_invalid_query",
+			query: "?status=lost",
+			setup: func(t *testing.T) AdminOrderService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAdminOrderService(ctrl)
+				service.EXPECT().
+					Search(gomock.All(), gomock.All()).
+					Return(dto.AdminOrderPage{}, errors.ErrListInvalidQuery)
+				return service
+			},
+			want: want{
+				code: http.StatusBadRequest,
+				body: errors.ErrListInvalidQuery.Error(),
+			},
+		},
+		{
+			name:  "negative_server_error",
+			query: "",
+			setup: func(t *testing.T) AdminOrderService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAdminOrderService(ctrl)
+				service.EXPECT().
+					Search(gomock.All(), gomock.All()).
+					Return(dto.AdminOrderPage{}, errors.ErrUnexpected)
+				return service
+			},
+			want: want{
+				code: http.StatusInternalServerError,
+				body: statusText500,
+			},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewAdmin(test.setup(t), logger)
+
+			r := httptest.NewRequest(http.MethodGet, "/api/admin/orders"+test.query, nil)
+			w := httptest.NewRecorder()
+			handler.Orders(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want.code, res.StatusCode, "Response status code")
+			assert.Equal(t, test.want.next, res.Header.Get("X-Next-Cursor"), "Next cursor")
+			resBody, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := strings.TrimSuffix(string(resBody), "\n")
+			assert.Equal(t, test.want.body, body, "Response body")
+		})
+	}
+}
+
+func TestAdmin_Order(t *testing.T) {
+	type want struct {
+		code int
+		body string
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want want
+	}{
+		{
+			name: "success",
+			err:  nil,
+			want: want{code: http.StatusOK, body: testAdminOrderJSON},
+		},
+		{
+			name: "negative_not_found",
+			err:  errors.ErrOrderNotFound,
+			want: want{code: http.StatusNotFound, body: errors.ErrOrderNotFound.Error()},
+		},
+		{
+			name: "negative_server_error",
+			err:  errors.ErrUnexpected,
+			want: want{code: http.StatusInternalServerError, body: statusText500},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := mocks.NewMockAdminOrderService(gomock.NewController(t))
+			service.EXPECT().
+				Get(gomock.All(), "5062821234567892").
+				Return(testAdminOrder, test.err)
+			handler := NewAdmin(service, logger)
+
+			r := adminOrderRequest(http.MethodGet, "", nil)
+			w := httptest.NewRecorder()
+			handler.Order(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want.code, res.StatusCode, "Response status code")
+			resBody, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := strings.TrimSuffix(string(resBody), "\n")
+			assert.Equal(t, test.want.body, body, "Response body")
+		})
+	}
+}
+
+func TestAdmin_Recheck(t *testing.T) {
+	type want struct {
+		code int
+		body string
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want want
+	}{
+		{
+			name: "success",
+			err:  nil,
+			want: want{code: http.StatusAccepted, body: testAdminOrderJSON},
+		},
+		{
+			name: "negative_final_status",
+			err:  errors.ErrOrderStatusConflict,
+			want: want{code: http.StatusConflict, body: errors.ErrOrderStatusConflict.Error()},
+		},
+		{
+			name: "negative_not_found",
+			err:  errors.ErrOrderNotFound,
+			want: want{code: http.StatusNotFound, body: errors.ErrOrderNotFound.Error()},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := mocks.NewMockAdminOrderService(gomock.NewController(t))
+			service.EXPECT().
+				Recheck(gomock.All(), "5062821234567892").
+				Return(testAdminOrder, test.err)
+			handler := NewAdmin(service, logger)
+
+			r := adminOrderRequest(http.MethodPost, "/recheck", nil)
+			w := httptest.NewRecorder()
+			handler.Recheck(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want.code, res.StatusCode, "Response status code")
+			resBody, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := strings.TrimSuffix(string(resBody), "\n")
+			assert.Equal(t, test.want.body, body, "Response body")
+		})
+	}
+}
+
+func TestAdmin_SetStatus(t *testing.T) {
+	type want struct {
+		code int
+		body string
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		setup func(t *testing.T) AdminOrderService
+		want  want
+	}{
+		{
+			name: "success",
+			body: `{"status":"NEW"}`,
+			setup: func(t *testing.T) AdminOrderService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAdminOrderService(ctrl)
+				service.EXPECT().
+					SetStatus(gomock.All(), "5062821234567892", dto.AdminOrderStatusReq{Status: "NEW"}).
+					Return(testAdminOrder, nil)
+				return service
+			},
+			want: want{code: http.StatusOK, body: testAdminOrderJSON},
+		},
+		{
+			name: "negative_bad_json",
+			body: `not valid jsson`,
+			setup: func(t *testing.T) AdminOrderService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAdminOrderService(ctrl)
+				service.EXPECT().
+					SetStatus(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return service
+			},
+			want: want{code: http.StatusBadRequest, body: "invalid request format"},
+		},
+		{
+			name: "negative_invalid_status",
+			body: `{"status":"PROCESSED"}`,
+			setup: func(t *testing.T) AdminOrderService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAdminOrderService(ctrl)
+				service.EXPECT().
+					SetStatus(gomock.All(), "5062821234567892", dto.AdminOrderStatusReq{Status: "PROCESSED"}).
+					Return(dto.AdminOrder{}, errors.ErrOrderInvalidStatus)
+				return service
+			},
+			want: want{code: http.StatusUnprocessableEntity, body: errors.ErrOrderInvalidStatus.Error()},
+		},
+		{
+			name: "negative_status_conflict",
+			body: `{"status":"INVALID"}`,
+			setup: func(t *testing.T) AdminOrderService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAdminOrderService(ctrl)
+				service.EXPECT().
+					SetStatus(gomock.All(), "5062821234567892", dto.AdminOrderStatusReq{Status: "INVALID"}).
+					Return(dto.AdminOrder{}, errors.ErrOrderStatusConflict)
+				return service
+			},
+			want: want{code: http.StatusConflict, body: errors.ErrOrderStatusConflict.Error()},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewAdmin(test.setup(t), logger)
+
+			r := adminOrderRequest(http.MethodPut, "/status", bytes.NewBufferString(test.body))
+			w := httptest.NewRecorder()
+			handler.SetStatus(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want.code, res.StatusCode, "Response status code")
+			resBody, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := strings.TrimSuffix(string(resBody), "\n")
+			assert.Equal(t, test.want.body, body, "Response body")
+		})
+	}
+}
+
+func adminOrderRequest(method, suffix string, body io.Reader) *http.Request {
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("number", "5062821234567892")
+	r := httptest.NewRequest(method, "/api/admin/orders/5062821234567892"+suffix, body)
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+}
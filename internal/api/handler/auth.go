@@ -4,23 +4,30 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
+	"net"
 	"net/http"
+	"strings"
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
 	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
 )
 
 type AuthService interface {
-	Register(ctx context.Context, c dto.Credentials) (token string, err error)
-	Login(ctx context.Context, c dto.Credentials) (token string, err error)
+	Register(ctx context.Context, c dto.Credentials) (dto.AuthTokens, error)
+	Login(ctx context.Context, c dto.Credentials, ip string) (dto.AuthTokens, error)
+	Refresh(ctx context.Context, refreshToken string) (dto.AuthTokens, error)
+	Logout(ctx context.Context, accessToken, refreshToken string) error
+	JWKS() dto.JWKS
 }
 
 type Auth struct {
 	service AuthService
+	logger  Logger
 }
 
-func NewAuth(srv AuthService) *Auth {
-	return &Auth{service: srv}
+func NewAuth(srv AuthService, l Logger) *Auth {
+	return &Auth{service: srv, logger: l}
 }
 
 func (h *Auth) Register(w http.ResponseWriter, r *http.Request) {
@@ -31,7 +38,7 @@ func (h *Auth) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.service.Register(r.Context(), credentials)
+	tokens, err := h.service.Register(r.Context(), credentials)
 	if err != nil {
 		switch {
 		case errors.Is(err, srvErrors.ErrAuthInvalidCredentials):
@@ -45,8 +52,7 @@ func (h *Auth) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Header().Set("Authorization", "Bearer "+token)
-	w.WriteHeader(http.StatusOK)
+	h.writeTokens(w, r, tokens)
 }
 
 func (h *Auth) Login(w http.ResponseWriter, r *http.Request) {
@@ -57,16 +63,91 @@ func (h *Auth) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.service.Login(r.Context(), credentials)
+	tokens, err := h.service.Login(r.Context(), credentials, clientIP(r))
+	if err != nil {
+		var retryErr *srvErrors.RetryError
+		switch {
+		case errors.As(err, &retryErr):
+			tooManyRequests(w, retryErr)
+		case errors.Is(err, srvErrors.ErrAuthInvalidCredentials):
+			http.Error(w, "", http.StatusUnauthorized)
+		default:
+			http.Error(w, statusText500, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.writeTokens(w, r, tokens)
+}
+
+func (h *Auth) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req dto.RefreshToken
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := h.service.Refresh(r.Context(), req.RefreshToken)
 	if err != nil {
-		if errors.Is(err, srvErrors.ErrAuthInvalidCredentials) {
+		switch {
+		case errors.Is(err, srvErrors.ErrAuthTokenExpired):
+			http.Error(w, "token expired", http.StatusUnauthorized)
+		case errors.Is(err, srvErrors.ErrAuthInvalidToken):
 			http.Error(w, "", http.StatusUnauthorized)
-		} else {
+		default:
+			http.Error(w, statusText500, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.writeTokens(w, r, tokens)
+}
+
+// Logout отзывает токен из заголовка Authorization,
+// refresh токен в теле запроса необязателен.
+func (h *Auth) Logout(w http.ResponseWriter, r *http.Request) {
+	var req dto.RefreshToken
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	accessToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	err = h.service.Logout(r.Context(), accessToken, req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, srvErrors.ErrAuthTokenExpired),
+			errors.Is(err, srvErrors.ErrAuthInvalidToken):
+			http.Error(w, "", http.StatusUnauthorized)
+		default:
 			http.Error(w, statusText500, http.StatusInternalServerError)
 		}
 		return
 	}
 
-	w.Header().Set("Authorization", "Bearer "+token)
 	w.WriteHeader(http.StatusOK)
 }
+
+func (h *Auth) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	newJSONwriter(w, r, h.logger).write(h.service.JWKS(), "jwks", http.StatusOK)
+}
+
+func (h *Auth) writeTokens(w http.ResponseWriter, r *http.Request, tokens dto.AuthTokens) {
+	w.Header().Set("Authorization", tokens.TokenType+" "+tokens.AccessToken)
+	newJSONwriter(w, r, h.logger).write(tokens, "tokens", http.StatusOK)
+}
+
+// clientIP берет адрес из соединения, а не из заголовков вроде X-Forwarded-For,
+// которые клиент может подделать.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
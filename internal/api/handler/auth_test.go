@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -15,22 +16,23 @@ import (
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/handler/mocks"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/jwtkeys"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
 )
 
 func TestAuth_Register(t *testing.T) {
-	token := "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9" +
-		".eyJleHAiOjE3NTg0NTk0OTMsImp0aSI6IjEifQ._mX-s6U9_iq4YhnQ5HOYbJAz7P8ly8BD_BufPYx2Kms"
-	authHeader := "Bearer " + token
+	tokens := testAuthTokens()
+	authHeader := "Bearer " + tokens.AccessToken
 
 	errLoginTooLong := fmt.Errorf(
-		"%w: password too long, max %d characters",
+		"%w: login too long, max %d characters",
 		errors.ErrAuthInvalidCredentials,
 		entity.UserMaxLoginLen,
 	)
 	errPasswordTooLong := fmt.Errorf(
-		"%w: password too long, max 72 bytes",
+		"%w: password too long, max %d bytes",
 		errors.ErrAuthInvalidCredentials,
+		entity.UserMaxPasswordLen,
 	)
 
 	type want struct {
@@ -53,13 +55,13 @@ func TestAuth_Register(t *testing.T) {
 				service := mocks.NewMockAuthService(ctrl)
 				service.EXPECT().
 					Register(gomock.All(), dto.Credentials{Login: "testLogin", Password: "t1estP5assword"}).
-					Return(token, nil)
+					Return(tokens, nil)
 				return service
 			},
 			want: want{
 				code:   http.StatusOK,
 				header: authHeader,
-				body:   "",
+				body:   testAuthTokensJSON,
 			},
 		},
 		{
@@ -86,7 +88,7 @@ func TestAuth_Register(t *testing.T) {
 				service := mocks.NewMockAuthService(ctrl)
 				service.EXPECT().
 					Register(gomock.All(), dto.Credentials{Password: "t1estP5assword"}).
-					Return("", errors.ErrAuthInvalidCredentials)
+					Return(dto.AuthTokens{}, errors.ErrAuthInvalidCredentials)
 				return service
 			},
 			want: want{
@@ -103,7 +105,7 @@ func TestAuth_Register(t *testing.T) {
 				service := mocks.NewMockAuthService(ctrl)
 				service.EXPECT().
 					Register(gomock.All(), dto.Credentials{Login: "testLogin"}).
-					Return("", errors.ErrAuthInvalidCredentials)
+					Return(dto.AuthTokens{}, errors.ErrAuthInvalidCredentials)
 				return service
 			},
 			want: want{
@@ -120,13 +122,13 @@ func TestAuth_Register(t *testing.T) {
 				service := mocks.NewMockAuthService(ctrl)
 				service.EXPECT().
 					Register(gomock.All(), dto.Credentials{Login: "veryLongLogin", Password: "t1estP5assword"}).
-					Return("", errLoginTooLong)
+					Return(dto.AuthTokens{}, errLoginTooLong)
 				return service
 			},
 			want: want{
 				code:   http.StatusBadRequest,
 				header: "",
-				body:   "invalid credentials: password too long, max 64 characters",
+				body:   "invalid credentials: login too long, max 64 characters",
 			},
 		},
 		{
@@ -137,13 +139,13 @@ func TestAuth_Register(t *testing.T) {
 				service := mocks.NewMockAuthService(ctrl)
 				service.EXPECT().
 					Register(gomock.All(), dto.Credentials{Login: "testLogin", Password: "veryLongPassword"}).
-					Return("", errPasswordTooLong)
+					Return(dto.AuthTokens{}, errPasswordTooLong)
 				return service
 			},
 			want: want{
 				code:   http.StatusBadRequest,
 				header: "",
-				body:   "invalid credentials: password too long, max 72 bytes",
+				body:   "invalid credentials: password too long, max 256 bytes",
 			},
 		},
 		{
@@ -154,7 +156,7 @@ func TestAuth_Register(t *testing.T) {
 				service := mocks.NewMockAuthService(ctrl)
 				service.EXPECT().
 					Register(gomock.All(), dto.Credentials{Login: "testLogin", Password: "t1estP5assword"}).
-					Return("", errors.ErrAuthUserAlreadyExists)
+					Return(dto.AuthTokens{}, errors.ErrAuthUserAlreadyExists)
 				return service
 			},
 			want: want{
@@ -171,7 +173,7 @@ func TestAuth_Register(t *testing.T) {
 				service := mocks.NewMockAuthService(ctrl)
 				service.EXPECT().
 					Register(gomock.All(), dto.Credentials{Login: "testLogin", Password: "t1estP5assword"}).
-					Return("", errors.ErrUnexpected)
+					Return(dto.AuthTokens{}, errors.ErrUnexpected)
 				return service
 			},
 			want: want{
@@ -182,10 +184,14 @@ func TestAuth_Register(t *testing.T) {
 		},
 	}
 
+	ctrl := gomock.NewController(t)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			service := test.setup(t)
-			handler := NewAuth(service)
+			handler := NewAuth(service, logger)
 
 			reqBody := []byte(test.body)
 			r := httptest.NewRequest(http.MethodPost, "/register", bytes.NewBuffer(reqBody))
@@ -210,14 +216,14 @@ func TestAuth_Register(t *testing.T) {
 }
 
 func TestAuth_Login(t *testing.T) {
-	token := "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9" +
-		".eyJleHAiOjE3NTg0NTk0OTMsImp0aSI6IjEifQ._mX-s6U9_iq4YhnQ5HOYbJAz7P8ly8BD_BufPYx2Kms"
-	authHeader := "Bearer " + token
+	tokens := testAuthTokens()
+	authHeader := "Bearer " + tokens.AccessToken
 
 	type want struct {
-		code   int
-		header string
-		body   string
+		code       int
+		header     string
+		retryAfter string
+		body       string
 	}
 
 	tests := []struct {
@@ -233,14 +239,14 @@ func TestAuth_Login(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				service := mocks.NewMockAuthService(ctrl)
 				service.EXPECT().
-					Login(gomock.All(), dto.Credentials{Login: "testLogin", Password: "t1estP5assword"}).
-					Return(token, nil)
+					Login(gomock.All(), dto.Credentials{Login: "testLogin", Password: "t1estP5assword"}, "192.0.2.1").
+					Return(tokens, nil)
 				return service
 			},
 			want: want{
 				code:   http.StatusOK,
 				header: authHeader,
-				body:   "",
+				body:   testAuthTokensJSON,
 			},
 		},
 		{
@@ -250,7 +256,7 @@ func TestAuth_Login(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				service := mocks.NewMockAuthService(ctrl)
 				service.EXPECT().
-					Login(gomock.All(), gomock.All()).Times(0)
+					Login(gomock.All(), gomock.All(), gomock.All()).Times(0)
 				return service
 			},
 			want: want{
@@ -266,8 +272,8 @@ func TestAuth_Login(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				service := mocks.NewMockAuthService(ctrl)
 				service.EXPECT().
-					Login(gomock.All(), dto.Credentials{Login: "badLogin", Password: "orPassword"}).
-					Return("", errors.ErrAuthInvalidCredentials)
+					Login(gomock.All(), dto.Credentials{Login: "badLogin", Password: "orPassword"}, "192.0.2.1").
+					Return(dto.AuthTokens{}, errors.ErrAuthInvalidCredentials)
 				return service
 			},
 			want: want{
@@ -276,6 +282,27 @@ func TestAuth_Login(t *testing.T) {
 				body:   "",
 			},
 		},
+		{
+			name: "negative_too_many_attempts",
+			body: `{"login":"testLogin", "password":"t1estP5assword"}`,
+			setup: func(t *testing.T) AuthService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAuthService(ctrl)
+				service.EXPECT().
+					Login(gomock.All(), dto.Credentials{Login: "testLogin", Password: "t1estP5assword"}, "192.0.2.1").
+					Return(dto.AuthTokens{}, &errors.RetryError{
+						Err:   errors.ErrAuthTooManyAttempts,
+						After: 1500 * time.Millisecond,
+					})
+				return service
+			},
+			want: want{
+				code:       http.StatusTooManyRequests,
+				header:     "",
+				retryAfter: "2",
+				body:       "too many login attempts",
+			},
+		},
 		{
 			name: "negative_server_error",
 			body: `{"login":"testLogin", "password":"t1estP5assword"}`,
@@ -283,8 +310,8 @@ func TestAuth_Login(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				service := mocks.NewMockAuthService(ctrl)
 				service.EXPECT().
-					Login(gomock.All(), dto.Credentials{Login: "testLogin", Password: "t1estP5assword"}).
-					Return("", errors.ErrUnexpected)
+					Login(gomock.All(), dto.Credentials{Login: "testLogin", Password: "t1estP5assword"}, "192.0.2.1").
+					Return(dto.AuthTokens{}, errors.ErrUnexpected)
 				return service
 			},
 			want: want{
@@ -295,10 +322,14 @@ func TestAuth_Login(t *testing.T) {
 		},
 	}
 
+	ctrl := gomock.NewController(t)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			service := test.setup(t)
-			handler := NewAuth(service)
+			handler := NewAuth(service, logger)
 
 			reqBody := []byte(test.body)
 			r := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(reqBody))
@@ -312,6 +343,247 @@ func TestAuth_Login(t *testing.T) {
 			assert.Equal(t, test.want.code, res.StatusCode, "Response status code")
 			resAuthHeader := res.Header.Get("Authorization")
 			assert.Equal(t, test.want.header, resAuthHeader, "Response Authorization Header")
+			assert.Equal(t, test.want.retryAfter, res.Header.Get("Retry-After"), "Response Retry-After Header")
+			resBody, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := strings.TrimSuffix(string(resBody), "\n")
+			assert.Equal(t, test.want.body, body, "Response body")
+		})
+	}
+}
+
+func TestAuth_Refresh(t *testing.T) {
+	tokens := testAuthTokens()
+
+	type want struct {
+		code   int
+		header string
+		body   string
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		setup func(t *testing.T) AuthService
+		want  want
+	}{
+		{
+			name: "success",
+			body: `{"refresh_token":"oldRefreshToken"}`,
+			setup: func(t *testing.T) AuthService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAuthService(ctrl)
+				service.EXPECT().
+					Refresh(gomock.All(), "oldRefreshToken").
+					Return(tokens, nil)
+				return service
+			},
+			want: want{
+				code:   http.StatusOK,
+				header: "Bearer " + tokens.AccessToken,
+				body:   testAuthTokensJSON,
+			},
+		},
+		{
+			name: "negative_bad_json",
+			body: `not valid jsson`,
+			setup: func(t *testing.T) AuthService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAuthService(ctrl)
+				service.EXPECT().
+					Refresh(gomock.All(), gomock.All()).Times(0)
+				return service
+			},
+			want: want{
+				code:   http.StatusBadRequest,
+				header: "",
+				body:   "invalid request format",
+			},
+		},
+		{
+			name: "negative_invalid_token",
+			body: `{"refresh_token":"oldRefreshToken"}`,
+			setup: func(t *testing.T) AuthService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAuthService(ctrl)
+				service.EXPECT().
+					Refresh(gomock.All(), "oldRefreshToken").
+					Return(dto.AuthTokens{}, errors.ErrAuthInvalidToken)
+				return service
+			},
+			want: want{
+				code:   http.StatusUnauthorized,
+				header: "",
+				body:   "",
+			},
+		},
+		{
+			name: "negative_token_expired",
+			body: `{"refresh_token":"oldRefreshToken"}`,
+			setup: func(t *testing.T) AuthService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAuthService(ctrl)
+				service.EXPECT().
+					Refresh(gomock.All(), "oldRefreshToken").
+					Return(dto.AuthTokens{}, errors.ErrAuthTokenExpired)
+				return service
+			},
+			want: want{
+				code:   http.StatusUnauthorized,
+				header: "",
+				body:   "token expired",
+			},
+		},
+		{
+			name: "negative_server_error",
+			body: `{"refresh_token":"oldRefreshToken"}`,
+			setup: func(t *testing.T) AuthService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAuthService(ctrl)
+				service.EXPECT().
+					Refresh(gomock.All(), "oldRefreshToken").
+					Return(dto.AuthTokens{}, errors.ErrUnexpected)
+				return service
+			},
+			want: want{
+				code:   http.StatusInternalServerError,
+				header: "",
+				body:   statusText500,
+			},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := test.setup(t)
+			handler := NewAuth(service, logger)
+
+			reqBody := []byte(test.body)
+			r := httptest.NewRequest(http.MethodPost, "/token/refresh", bytes.NewBuffer(reqBody))
+			r.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			handler.Refresh(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want.code, res.StatusCode, "Response status code")
+			resAuthHeader := res.Header.Get("Authorization")
+			assert.Equal(t, test.want.header, resAuthHeader, "Response Authorization Header")
+			resBody, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := strings.TrimSuffix(string(resBody), "\n")
+			assert.Equal(t, test.want.body, body, "Response body")
+		})
+	}
+}
+
+func TestAuth_Logout(t *testing.T) {
+	accessToken := testAuthTokens().AccessToken
+
+	type want struct {
+		code int
+		body string
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		setup func(t *testing.T) AuthService
+		want  want
+	}{
+		{
+			name: "success",
+			body: `{"refresh_token":"refreshToken"}`,
+			setup: func(t *testing.T) AuthService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAuthService(ctrl)
+				service.EXPECT().
+					Logout(gomock.All(), accessToken, "refreshToken").
+					Return(nil)
+				return service
+			},
+			want: want{
+				code: http.StatusOK,
+				body: "",
+			},
+		},
+		{
+			name: "success_without_body",
+			body: "",
+			setup: func(t *testing.T) AuthService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAuthService(ctrl)
+				service.EXPECT().
+					Logout(gomock.All(), accessToken, "").
+					Return(nil)
+				return service
+			},
+			want: want{
+				code: http.StatusOK,
+				body: "",
+			},
+		},
+		{
+			name: "negative_bad_json",
+			body: `not valid jsson`,
+			setup: func(t *testing.T) AuthService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAuthService(ctrl)
+				service.EXPECT().
+					Logout(gomock.All(), gomock.All(), gomock.All()).Times(0)
+				return service
+			},
+			want: want{
+				code: http.StatusBadRequest,
+				body: "invalid request format",
+			},
+		},
+		{
+			name: "negative_server_error",
+			body: "",
+			setup: func(t *testing.T) AuthService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockAuthService(ctrl)
+				service.EXPECT().
+					Logout(gomock.All(), accessToken, "").
+					Return(errors.ErrUnexpected)
+				return service
+			},
+			want: want{
+				code: http.StatusInternalServerError,
+				body: statusText500,
+			},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := test.setup(t)
+			handler := NewAuth(service, logger)
+
+			reqBody := []byte(test.body)
+			r := httptest.NewRequest(http.MethodPost, "/logout", bytes.NewBuffer(reqBody))
+			r.Header.Set("Authorization", "Bearer "+accessToken)
+
+			w := httptest.NewRecorder()
+			handler.Logout(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want.code, res.StatusCode, "Response status code")
 			resBody, err := io.ReadAll(res.Body)
 			if err != nil {
 				t.Fatal(err)
@@ -321,3 +593,49 @@ func TestAuth_Login(t *testing.T) {
 		})
 	}
 }
+
+const testAuthTokensJSON = `{"access_token":"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9` +
+	`.eyJleHAiOjE3NTg0NTk0OTMsImp0aSI6IjEifQ._mX-s6U9_iq4YhnQ5HOYbJAz7P8ly8BD_BufPYx2Kms",` +
+	`"refresh_token":"refreshToken","token_type":"Bearer","expires_in":900}`
+
+func testAuthTokens() dto.AuthTokens {
+	return dto.AuthTokens{
+		AccessToken: "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9" +
+			".eyJleHAiOjE3NTg0NTk0OTMsImp0aSI6IjEifQ._mX-s6U9_iq4YhnQ5HOYbJAz7P8ly8BD_BufPYx2Kms",
+		RefreshToken: "refreshToken",
+		TokenType:    "Bearer",
+		ExpiresIn:    900,
+	}
+}
+
+func TestAuth_JWKS(t *testing.T) {
+	jwks := dto.JWKS{Keys: []jwtkeys.JWK{
+		{Kty: "OKP", Crv: "Ed25519", Alg: "EdDSA", Use: "sig", Kid: "kid", X: "x"},
+	}}
+
+	ctrl := gomock.NewController(t)
+	service := mocks.NewMockAuthService(ctrl)
+	service.EXPECT().JWKS().Return(jwks)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	handler := NewAuth(service, logger)
+	r := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+	handler.JWKS(w, r)
+	res := w.Result()
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode, "Response status code")
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"), "Response Content-Type")
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(
+		t,
+		`{"keys":[{"kty":"OKP","crv":"Ed25519","alg":"EdDSA","use":"sig","kid":"kid","x":"x"}]}`,
+		string(resBody),
+		"Response body",
+	)
+}
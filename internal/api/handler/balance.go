@@ -2,13 +2,22 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
 )
 
 type BalanceService interface {
 	UserBalance(ctx context.Context) (dto.Balance, error)
+	History(ctx context.Context, q dto.ListQuery) (dto.LedgerPage, error)
+	Adjust(ctx context.Context, userID uint64, req dto.BalanceAdjustmentReq) (dto.BalanceAdjustment, error)
 }
 
 type Balance struct {
@@ -27,5 +36,71 @@ func (b *Balance) UserBalance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	newJSONwriter(w, b.logger).write(balance, "balance", http.StatusOK)
+	newJSONwriter(w, r, b.logger).write(balance, "balance", http.StatusOK)
+}
+
+// History поддерживает параметры limit, cursor, sort (asc, desc), from и to.
+func (b *Balance) History(w http.ResponseWriter, r *http.Request) {
+	query, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := b.service.History(r.Context(), query)
+	if err != nil {
+		if errors.Is(err, srvErrors.ErrListInvalidQuery) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, statusText500, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	setNextPage(w, r, page.NextCursor)
+
+	if len(page.Entries) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	newJSONwriter(w, r, b.logger).write(page.Entries, "balance history", http.StatusOK)
+}
+
+func (b *Balance) Adjust(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	var req dto.BalanceAdjustmentReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if errors.Is(err, money.ErrTooPrecise) {
+			http.Error(w, "amount must have at most two decimal places", http.StatusUnprocessableEntity)
+		} else {
+			http.Error(w, "invalid request format", http.StatusBadRequest)
+		}
+		return
+	}
+
+	adj, err := b.service.Adjust(r.Context(), userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, srvErrors.ErrAdjustmentInvalidAmount),
+			errors.Is(err, srvErrors.ErrAdjustmentInvalidReason):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		case errors.Is(err, srvErrors.ErrAdjustmentInsufficientFunds):
+			http.Error(w, "insufficient funds in the account", http.StatusConflict)
+		case errors.Is(err, srvErrors.ErrUserNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, srvErrors.ErrAdjustmentOperatorRequired):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, statusText500, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	newJSONwriter(w, r, b.logger).write(adj, "balance adjustment", http.StatusCreated)
 }
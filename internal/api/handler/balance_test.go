@@ -1,15 +1,21 @@
 package handler
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/handler/mocks"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -32,7 +38,7 @@ func TestBalance_UserBalance(t *testing.T) {
 				service := mocks.NewMockBalanceService(ctrl)
 				service.EXPECT().
 					UserBalance(gomock.All()).
-					Return(dto.Balance{Current: 1310.8, Withdrawn: 800}, nil)
+					Return(dto.Balance{Current: money.New(1310, 80), Withdrawn: money.New(800, 0)}, nil)
 				return service
 			},
 			want: want{
@@ -87,3 +93,251 @@ func TestBalance_UserBalance(t *testing.T) {
 		})
 	}
 }
+
+func TestBalance_History(t *testing.T) {
+	created := time.Date(2025, 10, 14, 10, 0, 0, 0, time.UTC)
+
+	type want struct {
+		code int
+		body string
+	}
+	tests := []struct {
+		name  string
+		query string
+		setup func(t *testing.T) BalanceService
+		want  want
+	}{
+		{
+			name:  "success",
+			query: "?limit=2",
+			setup: func(t *testing.T) BalanceService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockBalanceService(ctrl)
+				service.EXPECT().
+					History(gomock.All(), dto.ListQuery{Limit: 2}).
+					Return(dto.LedgerPage{Entries: []dto.LedgerEntry{
+						{Kind: "ADJUSTMENT", Amount: money.New(-50, 0), Reason: "mistake", Created: created},
+						{Kind: "ACCRUAL", Amount: money.New(50, 0), Order: "5062821234567892", Created: created},
+					}}, nil)
+				return service
+			},
+			want: want{
+				code: http.StatusOK,
+				body: `[{"kind":"ADJUSTMENT","amount":-50,"reason":"mistake","created_at":"2025-10-14T10:00:00Z"},` +
+					`{"kind":"ACCRUAL","amount":50,"order":"5062821234567892","created_at":"2025-10-14T10:00:00Z"}]`,
+			},
+		},
+		{
+			name:  "success_empty",
+			query: "",
+			setup: func(t *testing.T) BalanceService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockBalanceService(ctrl)
+				service.EXPECT().
+					History(gomock.All(), dto.ListQuery{}).
+					Return(dto.LedgerPage{}, nil)
+				return service
+			},
+			want: want{code: http.StatusNoContent, body: ""},
+		},
+		{
+			name:  "negative_invalid_query",
+			query: "?sort=up",
+			setup: func(t *testing.T) BalanceService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockBalanceService(ctrl)
+				service.EXPECT().
+					History(gomock.All(), gomock.All()).
+					Return(dto.LedgerPage{}, srvErrors.ErrListInvalidQuery)
+				return service
+			},
+			want: want{code: http.StatusBadRequest, body: srvErrors.ErrListInvalidQuery.Error()},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewBalance(test.setup(t), logger)
+
+			r := httptest.NewRequest(http.MethodGet, "/api/user/balance/history"+test.query, nil)
+			w := httptest.NewRecorder()
+			handler.History(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want.code, res.StatusCode, "Response status code")
+			resBody, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := strings.TrimSuffix(string(resBody), "\n")
+			assert.Equal(t, test.want.body, body, "Response body")
+		})
+	}
+}
+
+func TestBalance_Adjust(t *testing.T) {
+	created := time.Date(2025, 10, 14, 10, 0, 0, 0, time.UTC)
+
+	type want struct {
+		code int
+		body string
+	}
+	tests := []struct {
+		name   string
+		userID string
+		body   string
+		setup  func(t *testing.T) BalanceService
+		want   want
+	}{
+		{
+			name:   "success",
+			userID: "13",
+			body:   `{"amount":-50,"reason":"mistaken accrual"}`,
+			setup: func(t *testing.T) BalanceService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockBalanceService(ctrl)
+				service.EXPECT().
+					Adjust(gomock.All(), uint64(13), dto.BalanceAdjustmentReq{
+						Amount: money.New(-50, 0),
+						Reason: "mistaken accrual",
+					}).
+					Return(dto.BalanceAdjustment{
+						ID:         3,
+						UserID:     13,
+						Amount:     money.New(-50, 0),
+						Reason:     "mistaken accrual",
+						OperatorID: 7,
+						Current:    money.New(450, 0),
+						Withdrawn:  money.New(100, 0),
+						Created:    created,
+					}, nil)
+				return service
+			},
+			want: want{
+				code: http.StatusCreated,
+				body: `{"id":3,"user_id":13,"amount":-50,"reason":"mistaken accrual","operator_id":7,` +
+					`"current":450,"withdrawn":100,"created_at":"2025-10-14T10:00:00Z"}`,
+			},
+		},
+		{
+			name:   "negative_invalid_user_id",
+			userID: "user",
+			body:   `{"amount":50,"reason":"compensation"}`,
+			setup: func(t *testing.T) BalanceService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockBalanceService(ctrl)
+				service.EXPECT().
+					Adjust(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return service
+			},
+			want: want{code: http.StatusBadRequest, body: "invalid user id"},
+		},
+		{
+			name:   "negative_too_precise_amount",
+			userID: "13",
+			body:   `{"amount":0.001,"reason":"compensation"}`,
+			setup: func(t *testing.T) BalanceService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockBalanceService(ctrl)
+				service.EXPECT().
+					Adjust(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return service
+			},
+			want: want{code: http.StatusUnprocessableEntity, body: "amount must have at most two decimal places"},
+		},
+		{
+			name:   "negative_invalid_reason",
+			userID: "13",
+			body:   `{"amount":50}`,
+			setup: func(t *testing.T) BalanceService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockBalanceService(ctrl)
+				service.EXPECT().
+					Adjust(gomock.All(), uint64(13), gomock.All()).
+					Return(dto.BalanceAdjustment{}, srvErrors.ErrAdjustmentInvalidReason)
+				return service
+			},
+			want: want{code: http.StatusUnprocessableEntity, body: srvErrors.ErrAdjustmentInvalidReason.Error()},
+		},
+		{
+			name:   "negative_insufficient_funds",
+			userID: "13",
+			body:   `{"amount":-5000,"reason":"mistaken accrual"}`,
+			setup: func(t *testing.T) BalanceService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockBalanceService(ctrl)
+				service.EXPECT().
+					Adjust(gomock.All(), uint64(13), gomock.All()).
+					Return(dto.BalanceAdjustment{}, srvErrors.ErrAdjustmentInsufficientFunds)
+				return service
+			},
+			want: want{code: http.StatusConflict, body: "insufficient funds in the account"},
+		},
+		{
+			name:   "negative_user_not_found",
+			userID: "13",
+			body:   `{"amount":50,"reason":"compensation"}`,
+			setup: func(t *testing.T) BalanceService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockBalanceService(ctrl)
+				service.EXPECT().
+					Adjust(gomock.All(), uint64(13), gomock.All()).
+					Return(dto.BalanceAdjustment{}, srvErrors.ErrUserNotFound)
+				return service
+			},
+			want: want{code: http.StatusNotFound, body: srvErrors.ErrUserNotFound.Error()},
+		},
+		{
+			name:   "negative_without_operator",
+			userID: "13",
+			body:   `{"amount":50,"reason":"compensation"}`,
+			setup: func(t *testing.T) BalanceService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockBalanceService(ctrl)
+				service.EXPECT().
+					Adjust(gomock.All(), uint64(13), gomock.All()).
+					Return(dto.BalanceAdjustment{}, srvErrors.ErrAdjustmentOperatorRequired)
+				return service
+			},
+			want: want{code: http.StatusForbidden, body: srvErrors.ErrAdjustmentOperatorRequired.Error()},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewBalance(test.setup(t), logger)
+
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("id", test.userID)
+			r := httptest.NewRequest(
+				http.MethodPost,
+				"/api/admin/users/"+test.userID+"/balance/adjustments",
+				bytes.NewBufferString(test.body),
+			)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+			w := httptest.NewRecorder()
+			handler.Adjust(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want.code, res.StatusCode, "Response status code")
+			resBody, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := strings.TrimSuffix(string(resBody), "\n")
+			assert.Equal(t, test.want.body, body, "Response body")
+		})
+	}
+}
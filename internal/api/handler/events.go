@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+)
+
+// eventsHeartbeat комментарий в потоке не дает прокси закрыть простаивающее соединение.
+const eventsHeartbeat = 30 * time.Second
+
+type EventsService interface {
+	Subscribe(ctx context.Context) (<-chan dto.Event, func(), error)
+}
+
+type Events struct {
+	service EventsService
+	logger  Logger
+}
+
+func NewEvents(srv EventsService, l Logger) *Events {
+	return &Events{service: srv, logger: l}
+}
+
+// Stream отдает события в формате Server-Sent Events, пока клиент не отключится.
+func (h *Events) Stream(w http.ResponseWriter, r *http.Request) {
+	events, unsubscribe, err := h.service.Subscribe(r.Context())
+	if err != nil {
+		http.Error(w, statusText500, http.StatusInternalServerError)
+		return
+	}
+	defer unsubscribe()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err := rc.Flush(); err != nil {
+		h.logger.Error("failed to flush event stream", err, logger.Request(r.Context()))
+		return
+	}
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				h.logger.Error("failed to encode "+event.Type+" event to json", err, logger.Request(r.Context()))
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
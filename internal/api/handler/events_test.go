@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/handler/mocks"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+)
+
+func TestEvents_Stream(t *testing.T) {
+	type want struct {
+		code        int
+		contentType string
+		body        string
+	}
+
+	tests := []struct {
+		name  string
+		setup func(t *testing.T) EventsService
+		want  want
+	}{
+		{
+			name: "success",
+			setup: func(t *testing.T) EventsService {
+				events := make(chan dto.Event, 2)
+				events <- dto.Event{Type: "order", Data: dto.OrderEvent{Number: "5062821234567892", Status: "PROCESSING"}}
+				events <- dto.Event{Type: "balance", Data: dto.Balance{Current: money.New(500, 5), Withdrawn: money.New(42, 0)}}
+				close(events)
+
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockEventsService(ctrl)
+				service.EXPECT().
+					Subscribe(gomock.All()).
+					Return(events, func() {}, nil)
+				return service
+			},
+			want: want{
+				code:        http.StatusOK,
+				contentType: "text/event-stream",
+				body: "event: order\ndata: {\"number\":\"5062821234567892\",\"status\":\"PROCESSING\"}\n\n" +
+					"event: balance\ndata: {\"current\":500.05,\"withdrawn\":42}\n\n",
+			},
+		},
+		{
+			name: "negative_server_error",
+			setup: func(t *testing.T) EventsService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockEventsService(ctrl)
+				service.EXPECT().
+					Subscribe(gomock.All()).
+					Return(nil, nil, errors.ErrUnexpected)
+				return service
+			},
+			want: want{
+				code:        http.StatusInternalServerError,
+				contentType: "text/plain; charset=utf-8",
+				body:        statusText500 + "\n",
+			},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewEvents(test.setup(t), logger)
+
+			r := httptest.NewRequest(http.MethodGet, "/events", nil)
+			w := httptest.NewRecorder()
+			handler.Stream(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want.code, res.StatusCode, "Response status code")
+			assert.Equal(t, test.want.contentType, res.Header.Get("Content-Type"), "Content-Type header")
+			resBody, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.want.body, string(resBody), "Response body")
+		})
+	}
+}
@@ -2,28 +2,34 @@ package handler
 
 import (
 	"encoding/json"
+	"math"
 	"net/http"
+	"strconv"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
 )
 
 const statusText500 = "oops, something went wrong"
 
 type Logger interface {
-	Error(message string, err error)
+	Error(message string, err error, fields ...logger.Field)
 }
 
 type jsonWriter struct {
-	writer http.ResponseWriter
-	logger Logger
+	writer  http.ResponseWriter
+	request *http.Request
+	logger  Logger
 }
 
-func newJSONwriter(w http.ResponseWriter, l Logger) *jsonWriter {
-	return &jsonWriter{writer: w, logger: l}
+func newJSONwriter(w http.ResponseWriter, r *http.Request, l Logger) *jsonWriter {
+	return &jsonWriter{writer: w, request: r, logger: l}
 }
 
 func (jw *jsonWriter) write(value any, valueName string, stasusCode int) {
 	body, err := json.Marshal(value)
 	if err != nil {
-		jw.logger.Error("failed to encode "+valueName+" to json", err)
+		jw.logger.Error("failed to encode "+valueName+" to json", err, logger.Request(jw.request.Context()))
 		http.Error(jw.writer, statusText500, http.StatusInternalServerError)
 		return
 	}
@@ -33,6 +39,13 @@ func (jw *jsonWriter) write(value any, valueName string, stasusCode int) {
 
 	_, err = jw.writer.Write([]byte(body))
 	if err != nil {
-		jw.logger.Error("failed to write body", err)
+		jw.logger.Error("failed to write body", err, logger.Request(jw.request.Context()))
 	}
 }
+
+// tooManyRequests округляет время ожидания вверх до целых секунд для Retry-After.
+func tooManyRequests(w http.ResponseWriter, retryErr *srvErrors.RetryError) {
+	seconds := int64(math.Ceil(retryErr.After.Seconds()))
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	http.Error(w, retryErr.Error(), http.StatusTooManyRequests)
+}
@@ -11,6 +11,8 @@ import (
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/handler/mocks"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/requestid"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -22,6 +24,7 @@ func Test_jsonWriter_write(t *testing.T) {
 		body   string
 	}
 
+	requestField := logger.RequestID("req-42")
 	validLSON := `{"number":"5062821234567892","status":"NEW","uploaded_at":"0001-01-01T00:00:00Z"}`
 
 	tests := []struct {
@@ -60,7 +63,7 @@ func Test_jsonWriter_write(t *testing.T) {
 			logger: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
-				logger.EXPECT().Error("failed to encode order to json", gomock.All()).
+				logger.EXPECT().Error("failed to encode order to json", gomock.All(), requestField).
 					Times(1)
 				return logger
 			},
@@ -79,7 +82,7 @@ func Test_jsonWriter_write(t *testing.T) {
 			logger: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
-				logger.EXPECT().Error("failed to write body", gomock.All()).
+				logger.EXPECT().Error("failed to write body", gomock.All(), requestField).
 					Times(1)
 				return logger
 			},
@@ -101,7 +104,9 @@ func Test_jsonWriter_write(t *testing.T) {
 			w := test.witer
 			l := test.logger(t)
 
-			jw := newJSONwriter(w, l)
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r = r.WithContext(requestid.NewContext(r.Context(), "req-42"))
+			jw := newJSONwriter(w, r, l)
 			jw.write(test.value, test.valueName, test.stasusCode)
 
 			assert.Equal(t, test.want.code, w.Code, "Response status code")
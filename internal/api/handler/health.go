@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+)
+
+type HealthService interface {
+	Ready(ctx context.Context) (dto.Health, bool)
+}
+
+type Health struct {
+	service HealthService
+	logger  Logger
+}
+
+func NewHealth(s HealthService, l Logger) *Health {
+	return &Health{service: s, logger: l}
+}
+
+// Live отвечает 200, пока процесс обслуживает запросы, зависимости не проверяются.
+func (h *Health) Live(w http.ResponseWriter, r *http.Request) {
+	health := dto.Health{Status: dto.HealthStatusOK}
+	newJSONwriter(w, r, h.logger).write(health, "liveness", http.StatusOK)
+}
+
+// Ready отвечает 503, пока экземпляр не готов принимать запросы или останавливается.
+func (h *Health) Ready(w http.ResponseWriter, r *http.Request) {
+	health, ready := h.service.Ready(r.Context())
+
+	status := http.StatusOK
+	if !ready {
+		status = http.StatusServiceUnavailable
+	}
+
+	newJSONwriter(w, r, h.logger).write(health, "readiness", status)
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/handler/mocks"
+)
+
+func TestHealth_Live(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+	service := mocks.NewMockHealthService(ctrl)
+	service.EXPECT().Ready(gomock.Any()).Times(0)
+	handler := NewHealth(service, logger)
+
+	r := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	w := httptest.NewRecorder()
+	handler.Live(w, r)
+	res := w.Result()
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode, "Response status code")
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, `{"status":"ok"}`, strings.TrimSuffix(string(resBody), "\n"), "Response body")
+}
+
+func TestHealth_Ready(t *testing.T) {
+	tests := []struct {
+		name   string
+		health dto.Health
+		ready  bool
+		status int
+		body   string
+	}{
+		{
+			name: "ready",
+			health: dto.Health{
+				Status: dto.HealthStatusOK,
+				Components: map[string]dto.ComponentHealth{
+					"database":  {Status: dto.HealthStatusOK},
+					"processor": {Status: dto.HealthStatusOK, Details: dto.ProcessorHealth{Consumers: 10}},
+				},
+			},
+			ready:  true,
+			status: http.StatusOK,
+			body: `{"status":"ok","components":{"database":{"status":"ok"},` +
+				`"processor":{"status":"ok","details":{"consumers":10}}}}`,
+		},
+		{
+			name: "negative_not_ready",
+			health: dto.Health{
+				Status: dto.HealthStatusFail,
+				Components: map[string]dto.ComponentHealth{
+					"database": {Status: dto.HealthStatusFail, Error: "connection refused"},
+					"server":   {Status: dto.HealthStatusFail, Error: "server is shutting down"},
+				},
+			},
+			status: http.StatusServiceUnavailable,
+			body: `{"status":"fail","components":{"database":{"status":"fail","error":"connection refused"},` +
+				`"server":{"status":"fail","error":"server is shutting down"}}}`,
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := mocks.NewMockHealthService(gomock.NewController(t))
+			service.EXPECT().Ready(gomock.Any()).Return(test.health, test.ready)
+			handler := NewHealth(service, logger)
+
+			r := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			w := httptest.NewRecorder()
+			handler.Ready(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.status, res.StatusCode, "Response status code")
+			resBody, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := strings.TrimSuffix(string(resBody), "\n")
+			assert.Equal(t, test.body, body, "Response body")
+		})
+	}
+}
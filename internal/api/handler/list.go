@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+)
+
+const dateLayout = "2006-01-02"
+
+// parseListQuery разбирает limit, cursor, sort, from и to.
+// Корректность значений проверяет сервис.
+func parseListQuery(v url.Values) (q dto.ListQuery, err error) {
+	if s := v.Get("limit"); s != "" {
+		q.Limit, err = strconv.Atoi(s)
+		if err != nil {
+			return q, fmt.Errorf("invalid limit %q", s)
+		}
+	}
+
+	q.Cursor = v.Get("cursor")
+	q.Sort = strings.ToLower(v.Get("sort"))
+
+	if q.From, err = parseTime(v, "from"); err != nil {
+		return q, err
+	}
+	if q.To, err = parseTime(v, "to"); err != nil {
+		return q, err
+	}
+
+	return q, nil
+}
+
+// parseTime принимает RFC 3339 или дату, дата означает начало дня по UTC.
+func parseTime(v url.Values, name string) (time.Time, error) {
+	s := v.Get(name)
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(dateLayout, s); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid %s %q, expected RFC 3339 time or YYYY-MM-DD", name, s)
+}
+
+func parseSum(v url.Values, name string) (*money.Amount, error) {
+	s := v.Get(name)
+	if s == "" {
+		return nil, nil
+	}
+
+	sum, err := money.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q", name, s)
+	}
+
+	return &sum, nil
+}
+
+// listValues объединяет повторяющиеся параметры и значения через запятую.
+func listValues(v url.Values, name string) []string {
+	var list []string
+	for _, value := range v[name] {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// setNextPage передает курсор следующей страницы в заголовке X-Next-Cursor
+// и ссылку на нее в заголовке Link, остальные параметры запроса сохраняются.
+func setNextPage(w http.ResponseWriter, r *http.Request, cursor string) {
+	if cursor == "" {
+		return
+	}
+
+	query := r.URL.Query()
+	query.Set("cursor", cursor)
+	next := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+
+	w.Header().Set("X-Next-Cursor", cursor)
+	w.Header().Set("Link", "<"+next.String()+`>; rel="next"`)
+}
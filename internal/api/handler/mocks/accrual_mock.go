@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: accrual.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	breaker "github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/breaker"
+	ratelimit "github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/ratelimit"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAccrualMonitor is a mock of AccrualMonitor interface.
+type MockAccrualMonitor struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccrualMonitorMockRecorder
+}
+
+// MockAccrualMonitorMockRecorder is the mock recorder for MockAccrualMonitor.
+type MockAccrualMonitorMockRecorder struct {
+	mock *MockAccrualMonitor
+}
+
+// NewMockAccrualMonitor creates a new mock instance.
+func NewMockAccrualMonitor(ctrl *gomock.Controller) *MockAccrualMonitor {
+	mock := &MockAccrualMonitor{ctrl: ctrl}
+	mock.recorder = &MockAccrualMonitorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccrualMonitor) EXPECT() *MockAccrualMonitorMockRecorder {
+	return m.recorder
+}
+
+// BreakerState mocks base method.
+func (m *MockAccrualMonitor) BreakerState() breaker.State {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BreakerState")
+	ret0, _ := ret[0].(breaker.State)
+	return ret0
+}
+
+// BreakerState indicates an expected call of BreakerState.
+func (mr *MockAccrualMonitorMockRecorder) BreakerState() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BreakerState", reflect.TypeOf((*MockAccrualMonitor)(nil).BreakerState))
+}
+
+// LimiterState mocks base method.
+func (m *MockAccrualMonitor) LimiterState() ratelimit.State {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LimiterState")
+	ret0, _ := ret[0].(ratelimit.State)
+	return ret0
+}
+
+// LimiterState indicates an expected call of LimiterState.
+func (mr *MockAccrualMonitorMockRecorder) LimiterState() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LimiterState", reflect.TypeOf((*MockAccrualMonitor)(nil).LimiterState))
+}
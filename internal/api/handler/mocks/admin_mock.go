@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: admin.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAdminOrderService is a mock of AdminOrderService interface.
+type MockAdminOrderService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminOrderServiceMockRecorder
+}
+
+// MockAdminOrderServiceMockRecorder is the mock recorder for MockAdminOrderService.
+type MockAdminOrderServiceMockRecorder struct {
+	mock *MockAdminOrderService
+}
+
+// NewMockAdminOrderService creates a new mock instance.
+func NewMockAdminOrderService(ctrl *gomock.Controller) *MockAdminOrderService {
+	mock := &MockAdminOrderService{ctrl: ctrl}
+	mock.recorder = &MockAdminOrderServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminOrderService) EXPECT() *MockAdminOrderServiceMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockAdminOrderService) Get(ctx context.Context, number string) (dto.AdminOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, number)
+	ret0, _ := ret[0].(dto.AdminOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockAdminOrderServiceMockRecorder) Get(ctx, number interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockAdminOrderService)(nil).Get), ctx, number)
+}
+
+// Recheck mocks base method.
+func (m *MockAdminOrderService) Recheck(ctx context.Context, number string) (dto.AdminOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recheck", ctx, number)
+	ret0, _ := ret[0].(dto.AdminOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recheck indicates an expected call of Recheck.
+func (mr *MockAdminOrderServiceMockRecorder) Recheck(ctx, number interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recheck", reflect.TypeOf((*MockAdminOrderService)(nil).Recheck), ctx, number)
+}
+
+// Search mocks base method.
+func (m *MockAdminOrderService) Search(ctx context.Context, q dto.AdminOrderQuery) (dto.AdminOrderPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, q)
+	ret0, _ := ret[0].(dto.AdminOrderPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockAdminOrderServiceMockRecorder) Search(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockAdminOrderService)(nil).Search), ctx, q)
+}
+
+// SetStatus mocks base method.
+func (m *MockAdminOrderService) SetStatus(ctx context.Context, number string, req dto.AdminOrderStatusReq) (dto.AdminOrder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStatus", ctx, number, req)
+	ret0, _ := ret[0].(dto.AdminOrder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetStatus indicates an expected call of SetStatus.
+func (mr *MockAdminOrderServiceMockRecorder) SetStatus(ctx, number, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStatus", reflect.TypeOf((*MockAdminOrderService)(nil).SetStatus), ctx, number, req)
+}
@@ -35,6 +35,36 @@ func (m *MockBalanceService) EXPECT() *MockBalanceServiceMockRecorder {
 	return m.recorder
 }
 
+// Adjust mocks base method.
+func (m *MockBalanceService) Adjust(ctx context.Context, userID uint64, req dto.BalanceAdjustmentReq) (dto.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Adjust", ctx, userID, req)
+	ret0, _ := ret[0].(dto.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Adjust indicates an expected call of Adjust.
+func (mr *MockBalanceServiceMockRecorder) Adjust(ctx, userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Adjust", reflect.TypeOf((*MockBalanceService)(nil).Adjust), ctx, userID, req)
+}
+
+// History mocks base method.
+func (m *MockBalanceService) History(ctx context.Context, q dto.ListQuery) (dto.LedgerPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, q)
+	ret0, _ := ret[0].(dto.LedgerPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockBalanceServiceMockRecorder) History(ctx, q interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockBalanceService)(nil).History), ctx, q)
+}
+
 // UserBalance mocks base method.
 func (m *MockBalanceService) UserBalance(ctx context.Context) (dto.Balance, error) {
 	m.ctrl.T.Helper()
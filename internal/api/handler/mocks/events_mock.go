@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: events.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockEventsService is a mock of EventsService interface.
+type MockEventsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventsServiceMockRecorder
+}
+
+// MockEventsServiceMockRecorder is the mock recorder for MockEventsService.
+type MockEventsServiceMockRecorder struct {
+	mock *MockEventsService
+}
+
+// NewMockEventsService creates a new mock instance.
+func NewMockEventsService(ctrl *gomock.Controller) *MockEventsService {
+	mock := &MockEventsService{ctrl: ctrl}
+	mock.recorder = &MockEventsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventsService) EXPECT() *MockEventsServiceMockRecorder {
+	return m.recorder
+}
+
+// Subscribe mocks base method.
+func (m *MockEventsService) Subscribe(ctx context.Context) (<-chan dto.Event, func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx)
+	ret0, _ := ret[0].(<-chan dto.Event)
+	ret1, _ := ret[1].(func())
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockEventsServiceMockRecorder) Subscribe(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockEventsService)(nil).Subscribe), ctx)
+}
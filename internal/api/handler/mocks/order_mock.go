@@ -36,18 +36,18 @@ func (m *MockOrderService) EXPECT() *MockOrderServiceMockRecorder {
 }
 
 // List mocks base method.
-func (m *MockOrderService) List(ctx context.Context) ([]dto.Order, error) {
+func (m *MockOrderService) List(ctx context.Context, q dto.OrderQuery) (dto.OrderPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx)
-	ret0, _ := ret[0].([]dto.Order)
+	ret := m.ctrl.Call(m, "List", ctx, q)
+	ret0, _ := ret[0].(dto.OrderPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockOrderServiceMockRecorder) List(ctx interface{}) *gomock.Call {
+func (mr *MockOrderServiceMockRecorder) List(ctx, q interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockOrderService)(nil).List), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockOrderService)(nil).List), ctx, q)
 }
 
 // Upload mocks base method.
@@ -36,18 +36,18 @@ func (m *MockWithdrawalsService) EXPECT() *MockWithdrawalsServiceMockRecorder {
 }
 
 // List mocks base method.
-func (m *MockWithdrawalsService) List(ctx context.Context) ([]dto.WithdrawalsResp, error) {
+func (m *MockWithdrawalsService) List(ctx context.Context, q dto.WithdrawalsQuery) (dto.WithdrawalsPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx)
-	ret0, _ := ret[0].([]dto.WithdrawalsResp)
+	ret := m.ctrl.Call(m, "List", ctx, q)
+	ret0, _ := ret[0].(dto.WithdrawalsPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockWithdrawalsServiceMockRecorder) List(ctx interface{}) *gomock.Call {
+func (mr *MockWithdrawalsServiceMockRecorder) List(ctx, q interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockWithdrawalsService)(nil).List), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockWithdrawalsService)(nil).List), ctx, q)
 }
 
 // Withdraw mocks base method.
@@ -14,7 +14,7 @@ import (
 
 type OrderService interface {
 	Upload(ctx context.Context, orderNumber string) error
-	List(ctx context.Context) ([]dto.Order, error)
+	List(ctx context.Context, q dto.OrderQuery) (dto.OrderPage, error)
 }
 
 type Order struct {
@@ -55,16 +55,33 @@ func (o *Order) Create(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// List поддерживает параметры limit, cursor, sort (asc, desc), from, to и status,
+// статусов может быть несколько.
 func (o *Order) List(w http.ResponseWriter, r *http.Request) {
-	orders, err := o.service.List(r.Context())
+	values := r.URL.Query()
+	listQuery, err := parseListQuery(values)
 	if err != nil {
-		http.Error(w, statusText500, http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
+	query := dto.OrderQuery{ListQuery: listQuery, Statuses: listValues(values, "status")}
+
+	page, err := o.service.List(r.Context(), query)
+	if err != nil {
+		if errors.Is(err, srvErrors.ErrListInvalidQuery) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, statusText500, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	setNextPage(w, r, page.NextCursor)
 
-	if len(orders) == 0 {
+	if len(page.Orders) == 0 {
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
-	newJSONwriter(w, o.logger).write(orders, "orders", http.StatusOK)
+	newJSONwriter(w, r, o.logger).write(page.Orders, "orders", http.StatusOK)
 }
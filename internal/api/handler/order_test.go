@@ -8,10 +8,12 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/handler/mocks"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -193,7 +195,7 @@ func TestOrder_Create(t *testing.T) {
 }
 
 func TestOrder_List(t *testing.T) {
-	accrual := 99.99
+	accrual := money.New(99, 99)
 	orders := []dto.Order{
 		{
 			Number: "5062821234567892",
@@ -215,22 +217,25 @@ func TestOrder_List(t *testing.T) {
 	type want struct {
 		code   int
 		header string
+		link   string
 		body   string
 	}
 
 	tests := []struct {
-		name  string
-		setup func(t *testing.T) OrderService
-		want  want
+		name   string
+		target string
+		setup  func(t *testing.T) OrderService
+		want   want
 	}{
 		{
-			name: "success",
+			name:   "success",
+			target: "/api/user/orders",
 			setup: func(t *testing.T) OrderService {
 				ctrl := gomock.NewController(t)
 				service := mocks.NewMockOrderService(ctrl)
 				service.EXPECT().
-					List(gomock.All()).
-					Return(orders, nil)
+					List(gomock.All(), dto.OrderQuery{}).
+					Return(dto.OrderPage{Orders: orders}, nil)
 				return service
 			},
 			want: want{
@@ -240,13 +245,36 @@ func TestOrder_List(t *testing.T) {
 			},
 		},
 		{
-			name: "success_empty_list",
+			name:   "success_next_page",
+			target: "/api/user/orders?limit=2&status=INVALID,PROCESSED&from=2025-10-01",
+			setup: func(t *testing.T) OrderService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockOrderService(ctrl)
+				service.EXPECT().
+					List(gomock.All(), dto.OrderQuery{
+						ListQuery: dto.ListQuery{Limit: 2, From: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)},
+						Statuses:  []string{entity.OrderStatusInvalid, entity.OrderStatusProcessed},
+					}).
+					Return(dto.OrderPage{Orders: orders, NextCursor: "nextCursor"}, nil)
+				return service
+			},
+			want: want{
+				code:   http.StatusOK,
+				header: "application/json",
+				link: `</api/user/orders?cursor=nextCursor&from=2025-10-01&limit=2` +
+					`&status=INVALID%2CPROCESSED>; rel="next"`,
+				body: successBody,
+			},
+		},
+		{
+			name:   "success_empty_list",
+			target: "/api/user/orders",
 			setup: func(t *testing.T) OrderService {
 				ctrl := gomock.NewController(t)
 				service := mocks.NewMockOrderService(ctrl)
 				service.EXPECT().
-					List(gomock.All()).
-					Return([]dto.Order{}, nil)
+					List(gomock.All(), gomock.All()).
+					Return(dto.OrderPage{}, nil)
 				return service
 			},
 			want: want{
@@ -256,13 +284,48 @@ func TestOrder_List(t *testing.T) {
 			},
 		},
 		{
-			name: "negative_server_error",
+			name:   "negative_bad_limit",
+			target: "/api/user/orders?limit=ten",
+			setup: func(t *testing.T) OrderService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockOrderService(ctrl)
+				service.EXPECT().
+					List(gomock.All(), gomock.All()).
+					Times(0)
+				return service
+			},
+			want: want{
+				code:   http.StatusBadRequest,
+				header: "text/plain",
+				body:   `invalid limit "ten"`,
+			},
+		},
+		{
+			name:   "negative_invalid_query",
+			target: "/api/user/orders?status=LOST",
+			setup: func(t *testing.T) OrderService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockOrderService(ctrl)
+				service.EXPECT().
+					List(gomock.All(), dto.OrderQuery{Statuses: []string{"LOST"}}).
+					Return(dto.OrderPage{}, fmt.Errorf("%w: unknown order status", errors.ErrListInvalidQuery))
+				return service
+			},
+			want: want{
+				code:   http.StatusBadRequest,
+				header: "text/plain",
+				body:   "invalid list query: unknown order status",
+			},
+		},
+		{
+			name:   "negative_server_error",
+			target: "/api/user/orders",
 			setup: func(t *testing.T) OrderService {
 				ctrl := gomock.NewController(t)
 				service := mocks.NewMockOrderService(ctrl)
 				service.EXPECT().
-					List(gomock.All()).
-					Return([]dto.Order{}, errors.ErrUnexpected)
+					List(gomock.All(), gomock.All()).
+					Return(dto.OrderPage{}, errors.ErrUnexpected)
 				return service
 			},
 			want: want{
@@ -281,7 +344,7 @@ func TestOrder_List(t *testing.T) {
 			service := test.setup(t)
 			handler := NewOrder(service, logger)
 
-			r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			r := httptest.NewRequest(http.MethodGet, test.target, nil)
 			w := httptest.NewRecorder()
 			handler.List(w, r)
 			res := w.Result()
@@ -292,6 +355,7 @@ func TestOrder_List(t *testing.T) {
 				resContentType := w.Header().Get("Content-Type")
 				assert.Contains(t, resContentType, test.want.header, "Response Content-Type")
 			}
+			assert.Equal(t, test.want.link, res.Header.Get("Link"), "Response Link")
 
 			resBody, err := io.ReadAll(res.Body)
 			if err != nil {
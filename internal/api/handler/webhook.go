@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+)
+
+type WebhookService interface {
+	Create(ctx context.Context, req dto.WebhookReq) (dto.Webhook, error)
+	List(ctx context.Context) ([]dto.Webhook, error)
+	Delete(ctx context.Context, id uint64) error
+}
+
+type Webhook struct {
+	service WebhookService
+	logger  Logger
+}
+
+func NewWebhook(srv WebhookService, l Logger) *Webhook {
+	return &Webhook{service: srv, logger: l}
+}
+
+func (h *Webhook) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.WebhookReq
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := h.service.Create(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, srvErrors.ErrWebhookInvalidURL):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		case errors.Is(err, srvErrors.ErrWebhookAlreadyExists),
+			errors.Is(err, srvErrors.ErrWebhookLimitReached):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, statusText500, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	newJSONwriter(w, r, h.logger).write(webhook, "webhook", http.StatusCreated)
+}
+
+func (h *Webhook) List(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.service.List(r.Context())
+	if err != nil {
+		http.Error(w, statusText500, http.StatusInternalServerError)
+		return
+	}
+
+	if len(webhooks) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	newJSONwriter(w, r, h.logger).write(webhooks, "webhooks list", http.StatusOK)
+}
+
+func (h *Webhook) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid webhook id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.service.Delete(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, srvErrors.ErrWebhookNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, statusText500, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
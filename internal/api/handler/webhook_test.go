@@ -0,0 +1,352 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/handler/mocks"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+)
+
+func TestWebhook_Create(t *testing.T) {
+	created := time.Date(2025, 10, 9, 10, 0, 0, 0, time.UTC)
+	request := dto.WebhookReq{URL: "https://example.com/hook"}
+	requestJSON := `{"url":"https://example.com/hook"}`
+
+	type want struct {
+		code int
+		body string
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		setup func(t *testing.T) WebhookService
+		want  want
+	}{
+		{
+			name: "success",
+			body: requestJSON,
+			setup: func(t *testing.T) WebhookService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockWebhookService(ctrl)
+				service.EXPECT().
+					Create(gomock.All(), request).
+					Return(dto.Webhook{ID: 1, URL: request.URL, Secret: "testSecret", Created: created}, nil)
+				return service
+			},
+			want: want{
+				code: http.StatusCreated,
+				body: `{"id":1,"url":"https://example.com/hook","secret":"testSecret","created_at":"2025-10-09T10:00:00Z"}`,
+			},
+		},
+		{
+			name: "negative_bad_json",
+			body: `not valid jsson`,
+			setup: func(t *testing.T) WebhookService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockWebhookService(ctrl)
+				service.EXPECT().
+					Create(gomock.All(), gomock.All()).
+					Times(0)
+				return service
+			},
+			want: want{
+				code: http.StatusBadRequest,
+				body: "invalid request format",
+			},
+		},
+		{
+			name: "negative_invalid_url",
+			body: requestJSON,
+			setup: func(t *testing.T) WebhookService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockWebhookService(ctrl)
+				service.EXPECT().
+					Create(gomock.All(), request).
+					Return(dto.Webhook{}, fmt.Errorf("%w: absolute http or https url expected", errors.ErrWebhookInvalidURL))
+				return service
+			},
+			want: want{
+				code: http.StatusUnprocessableEntity,
+				body: errors.ErrWebhookInvalidURL.Error() + ": absolute http or https url expected",
+			},
+		},
+		{
+			name: "negative_already_exists",
+			body: requestJSON,
+			setup: func(t *testing.T) WebhookService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockWebhookService(ctrl)
+				service.EXPECT().
+					Create(gomock.All(), request).
+					Return(dto.Webhook{}, errors.ErrWebhookAlreadyExists)
+				return service
+			},
+			want: want{
+				code: http.StatusConflict,
+				body: errors.ErrWebhookAlreadyExists.Error(),
+			},
+		},
+		{
+			name: "negative_limit_reached",
+			body: requestJSON,
+			setup: func(t *testing.T) WebhookService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockWebhookService(ctrl)
+				service.EXPECT().
+					Create(gomock.All(), request).
+					Return(dto.Webhook{}, errors.ErrWebhookLimitReached)
+				return service
+			},
+			want: want{
+				code: http.StatusConflict,
+				body: errors.ErrWebhookLimitReached.Error(),
+			},
+		},
+		{
+			name: "negative_server_error",
+			body: requestJSON,
+			setup: func(t *testing.T) WebhookService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockWebhookService(ctrl)
+				service.EXPECT().
+					Create(gomock.All(), request).
+					Return(dto.Webhook{}, errors.ErrUnexpected)
+				return service
+			},
+			want: want{
+				code: http.StatusInternalServerError,
+				body: statusText500,
+			},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewWebhook(test.setup(t), logger)
+
+			r := httptest.NewRequest(http.MethodPost, "/webhooks", bytes.NewBuffer([]byte(test.body)))
+			w := httptest.NewRecorder()
+			handler.Create(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want.code, res.StatusCode, "Response status code")
+			resBody, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := strings.TrimSuffix(string(resBody), "\n")
+			assert.Equal(t, test.want.body, body, "Response body")
+		})
+	}
+}
+
+func TestWebhook_List(t *testing.T) {
+	created := time.Date(2025, 10, 9, 10, 0, 0, 0, time.UTC)
+
+	type want struct {
+		code int
+		body string
+	}
+
+	tests := []struct {
+		name  string
+		setup func(t *testing.T) WebhookService
+		want  want
+	}{
+		{
+			name: "success",
+			setup: func(t *testing.T) WebhookService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockWebhookService(ctrl)
+				service.EXPECT().
+					List(gomock.All()).
+					Return([]dto.Webhook{{ID: 1, URL: "https://example.com/hook", Created: created}}, nil)
+				return service
+			},
+			want: want{
+				code: http.StatusOK,
+				body: `[{"id":1,"url":"https://example.com/hook","created_at":"2025-10-09T10:00:00Z"}]`,
+			},
+		},
+		{
+			name: "empty",
+			setup: func(t *testing.T) WebhookService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockWebhookService(ctrl)
+				service.EXPECT().
+					List(gomock.All()).
+					Return(nil, nil)
+				return service
+			},
+			want: want{
+				code: http.StatusNoContent,
+				body: "",
+			},
+		},
+		{
+			name: "negative_server_error",
+			setup: func(t *testing.T) WebhookService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockWebhookService(ctrl)
+				service.EXPECT().
+					List(gomock.All()).
+					Return(nil, errors.ErrUnexpected)
+				return service
+			},
+			want: want{
+				code: http.StatusInternalServerError,
+				body: statusText500,
+			},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewWebhook(test.setup(t), logger)
+
+			r := httptest.NewRequest(http.MethodGet, "/webhooks", nil)
+			w := httptest.NewRecorder()
+			handler.List(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want.code, res.StatusCode, "Response status code")
+			resBody, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := strings.TrimSuffix(string(resBody), "\n")
+			assert.Equal(t, test.want.body, body, "Response body")
+		})
+	}
+}
+
+func TestWebhook_Delete(t *testing.T) {
+	type want struct {
+		code int
+		body string
+	}
+
+	tests := []struct {
+		name  string
+		id    string
+		setup func(t *testing.T) WebhookService
+		want  want
+	}{
+		{
+			name: "success",
+			id:   "7",
+			setup: func(t *testing.T) WebhookService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockWebhookService(ctrl)
+				service.EXPECT().
+					Delete(gomock.All(), uint64(7)).
+					Return(nil)
+				return service
+			},
+			want: want{
+				code: http.StatusOK,
+				body: "",
+			},
+		},
+		{
+			name: "negative_bad_id",
+			id:   "abc",
+			setup: func(t *testing.T) WebhookService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockWebhookService(ctrl)
+				service.EXPECT().
+					Delete(gomock.All(), gomock.All()).
+					Times(0)
+				return service
+			},
+			want: want{
+				code: http.StatusBadRequest,
+				body: "invalid webhook id",
+			},
+		},
+		{
+			name: "negative_not_found",
+			id:   "7",
+			setup: func(t *testing.T) WebhookService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockWebhookService(ctrl)
+				service.EXPECT().
+					Delete(gomock.All(), uint64(7)).
+					Return(errors.ErrWebhookNotFound)
+				return service
+			},
+			want: want{
+				code: http.StatusNotFound,
+				body: errors.ErrWebhookNotFound.Error(),
+			},
+		},
+		{
+			name: "negative_server_error",
+			id:   "7",
+			setup: func(t *testing.T) WebhookService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockWebhookService(ctrl)
+				service.EXPECT().
+					Delete(gomock.All(), uint64(7)).
+					Return(errors.ErrUnexpected)
+				return service
+			},
+			want: want{
+				code: http.StatusInternalServerError,
+				body: statusText500,
+			},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewWebhook(test.setup(t), logger)
+
+			routeCtx := chi.NewRouteContext()
+			routeCtx.URLParams.Add("id", test.id)
+			r := httptest.NewRequest(http.MethodDelete, "/webhooks/"+test.id, nil)
+			r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, routeCtx))
+
+			w := httptest.NewRecorder()
+			handler.Delete(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.want.code, res.StatusCode, "Response status code")
+			resBody, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := strings.TrimSuffix(string(resBody), "\n")
+			assert.Equal(t, test.want.body, body, "Response body")
+		})
+	}
+}
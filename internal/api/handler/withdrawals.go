@@ -5,14 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"net/url"
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
 	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
 )
 
 type WithdrawalsService interface {
 	Withdraw(ctx context.Context, w dto.Withdrawals) error
-	List(ctx context.Context) ([]dto.WithdrawalsResp, error)
+	List(ctx context.Context, q dto.WithdrawalsQuery) (dto.WithdrawalsPage, error)
 }
 
 type Withdrawals struct {
@@ -28,7 +30,11 @@ func (h *Withdrawals) Withdraw(w http.ResponseWriter, r *http.Request) {
 	var withdrawals dto.Withdrawals
 
 	if err := json.NewDecoder(r.Body).Decode(&withdrawals); err != nil {
-		http.Error(w, "invalid request format", http.StatusBadRequest)
+		if errors.Is(err, money.ErrTooPrecise) {
+			http.Error(w, "sum must have at most two decimal places", http.StatusUnprocessableEntity)
+		} else {
+			http.Error(w, "invalid request format", http.StatusBadRequest)
+		}
 		return
 	}
 
@@ -37,6 +43,8 @@ func (h *Withdrawals) Withdraw(w http.ResponseWriter, r *http.Request) {
 		switch {
 		case errors.Is(err, srvErrors.ErrWithdrawInsufficientFunds):
 			http.Error(w, "insufficient funds in the account", http.StatusPaymentRequired)
+		case errors.Is(err, srvErrors.ErrWithdrawOrderAlreadyPaid):
+			http.Error(w, "order already paid", http.StatusConflict)
 		case errors.Is(err, srvErrors.ErrWithdrawInvalidSum):
 			http.Error(w, "sum must be positive", http.StatusUnprocessableEntity)
 		case errors.Is(err, srvErrors.ErrOrderInvalidNumber):
@@ -51,16 +59,44 @@ func (h *Withdrawals) Withdraw(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// List поддерживает параметры limit, cursor, sort (asc, desc), from, to, min_sum и max_sum.
 func (h *Withdrawals) List(w http.ResponseWriter, r *http.Request) {
-	list, err := h.service.List(r.Context())
+	query, err := withdrawalsQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.List(r.Context(), query)
 	if err != nil {
-		http.Error(w, statusText500, http.StatusInternalServerError)
+		if errors.Is(err, srvErrors.ErrListInvalidQuery) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, statusText500, http.StatusInternalServerError)
+		}
+		return
 	}
 
-	if len(list) == 0 {
+	setNextPage(w, r, page.NextCursor)
+
+	if len(page.Withdrawals) == 0 {
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
-	newJSONwriter(w, h.logger).write(list, "withdrawals list", http.StatusOK)
+	newJSONwriter(w, r, h.logger).write(page.Withdrawals, "withdrawals list", http.StatusOK)
+}
+
+func withdrawalsQuery(values url.Values) (q dto.WithdrawalsQuery, err error) {
+	if q.ListQuery, err = parseListQuery(values); err != nil {
+		return q, err
+	}
+	if q.MinSum, err = parseSum(values, "min_sum"); err != nil {
+		return q, err
+	}
+	if q.MaxSum, err = parseSum(values, "max_sum"); err != nil {
+		return q, err
+	}
+
+	return q, nil
 }
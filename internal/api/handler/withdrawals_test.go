@@ -7,12 +7,14 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/handler/mocks"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
 )
 
@@ -36,7 +38,7 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				service := mocks.NewMockWithdrawalsService(ctrl)
 				service.EXPECT().
-					Withdraw(gomock.All(), dto.Withdrawals{Order: "5062821234567892", Sum: 700}).
+					Withdraw(gomock.All(), dto.Withdrawals{Order: "5062821234567892", Sum: money.New(700, 0)}).
 					Return(nil)
 				return service
 			},
@@ -70,7 +72,7 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				service := mocks.NewMockWithdrawalsService(ctrl)
 				service.EXPECT().
-					Withdraw(gomock.All(), dto.Withdrawals{Order: "5062821234567892", Sum: 700}).
+					Withdraw(gomock.All(), dto.Withdrawals{Order: "5062821234567892", Sum: money.New(700, 0)}).
 					Return(errors.ErrWithdrawInsufficientFunds)
 				return service
 			},
@@ -80,6 +82,23 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 				body:   "insufficient funds in the account",
 			},
 		},
+		{
+			name: "negative_order_already_paid",
+			body: `{"order":"5062821234567892", "sum":700}`,
+			setup: func(t *testing.T) WithdrawalsService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockWithdrawalsService(ctrl)
+				service.EXPECT().
+					Withdraw(gomock.All(), dto.Withdrawals{Order: "5062821234567892", Sum: money.New(700, 0)}).
+					Return(errors.ErrWithdrawOrderAlreadyPaid)
+				return service
+			},
+			want: want{
+				code:   http.StatusConflict,
+				header: "text/plain",
+				body:   "order already paid",
+			},
+		},
 		{
 			name: "negative_invalid_sum",
 			body: `{"order":"5062821234567892", "sum":0}`,
@@ -87,7 +106,7 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				service := mocks.NewMockWithdrawalsService(ctrl)
 				service.EXPECT().
-					Withdraw(gomock.All(), dto.Withdrawals{Order: "5062821234567892", Sum: 0}).
+					Withdraw(gomock.All(), dto.Withdrawals{Order: "5062821234567892", Sum: money.New(0, 0)}).
 					Return(errors.ErrWithdrawInvalidSum)
 				return service
 			},
@@ -97,6 +116,23 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 				body:   "sum must be positive",
 			},
 		},
+		{
+			name: "negative_too_precise_sum",
+			body: `{"order":"5062821234567892", "sum":0.004}`,
+			setup: func(t *testing.T) WithdrawalsService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockWithdrawalsService(ctrl)
+				service.EXPECT().
+					Withdraw(gomock.All(), gomock.All()).
+					Times(0)
+				return service
+			},
+			want: want{
+				code:   http.StatusUnprocessableEntity,
+				header: "text/plain",
+				body:   "sum must have at most two decimal places",
+			},
+		},
 		{
 			name: "negative_invalid_order",
 			body: `{"order":"5062821234567899", "sum":700}`,
@@ -104,7 +140,7 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				service := mocks.NewMockWithdrawalsService(ctrl)
 				service.EXPECT().
-					Withdraw(gomock.All(), dto.Withdrawals{Order: "5062821234567899", Sum: 700}).
+					Withdraw(gomock.All(), dto.Withdrawals{Order: "5062821234567899", Sum: money.New(700, 0)}).
 					Return(errors.ErrOrderInvalidNumber)
 				return service
 			},
@@ -121,7 +157,7 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				service := mocks.NewMockWithdrawalsService(ctrl)
 				service.EXPECT().
-					Withdraw(gomock.All(), dto.Withdrawals{Order: "5062821234567892", Sum: 700}).
+					Withdraw(gomock.All(), dto.Withdrawals{Order: "5062821234567892", Sum: money.New(700, 0)}).
 					Return(errors.ErrUnexpected)
 				return service
 			},
@@ -165,28 +201,32 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 }
 
 func TestWithdrawals_List(t *testing.T) {
-	list := []dto.WithdrawalsResp{{Order: "5062821234567892", Sum: 700}}
+	list := []dto.WithdrawalsResp{{Order: "5062821234567892", Sum: money.New(700, 0)}}
 	listBody := `[{"order":"5062821234567892","sum":700,"processed_at":"0001-01-01T00:00:00Z"}]`
+	minSum := money.New(100, 50)
 
 	type want struct {
 		code   int
 		header string
+		cursor string
 		body   string
 	}
 
 	tests := []struct {
-		name  string
-		setup func(t *testing.T) WithdrawalsService
-		want  want
+		name   string
+		target string
+		setup  func(t *testing.T) WithdrawalsService
+		want   want
 	}{
 		{
-			name: "success",
+			name:   "success",
+			target: "/api/user/withdrawals",
 			setup: func(t *testing.T) WithdrawalsService {
 				ctrl := gomock.NewController(t)
 				service := mocks.NewMockWithdrawalsService(ctrl)
 				service.EXPECT().
-					List(gomock.All()).
-					Return(list, nil)
+					List(gomock.All(), dto.WithdrawalsQuery{}).
+					Return(dto.WithdrawalsPage{Withdrawals: list}, nil)
 				return service
 			},
 			want: want{
@@ -196,13 +236,39 @@ func TestWithdrawals_List(t *testing.T) {
 			},
 		},
 		{
-			name: "success_empty_list",
+			name:   "success_next_page",
+			target: "/api/user/withdrawals?limit=1&sort=ASC&min_sum=100.50&to=2025-10-08T10:00:00Z",
 			setup: func(t *testing.T) WithdrawalsService {
 				ctrl := gomock.NewController(t)
 				service := mocks.NewMockWithdrawalsService(ctrl)
 				service.EXPECT().
-					List(gomock.All()).
-					Return([]dto.WithdrawalsResp{}, nil)
+					List(gomock.All(), dto.WithdrawalsQuery{
+						ListQuery: dto.ListQuery{
+							Limit: 1,
+							Sort:  dto.SortAsc,
+							To:    time.Date(2025, 10, 8, 10, 0, 0, 0, time.UTC),
+						},
+						MinSum: &minSum,
+					}).
+					Return(dto.WithdrawalsPage{Withdrawals: list, NextCursor: "nextCursor"}, nil)
+				return service
+			},
+			want: want{
+				code:   http.StatusOK,
+				header: "application/json",
+				cursor: "nextCursor",
+				body:   listBody,
+			},
+		},
+		{
+			name:   "success_empty_list",
+			target: "/api/user/withdrawals",
+			setup: func(t *testing.T) WithdrawalsService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockWithdrawalsService(ctrl)
+				service.EXPECT().
+					List(gomock.All(), gomock.All()).
+					Return(dto.WithdrawalsPage{}, nil)
 				return service
 			},
 			want: want{
@@ -212,13 +278,48 @@ func TestWithdrawals_List(t *testing.T) {
 			},
 		},
 		{
-			name: "negative_server_error",
+			name:   "negative_bad_sum",
+			target: "/api/user/withdrawals?max_sum=lots",
+			setup: func(t *testing.T) WithdrawalsService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockWithdrawalsService(ctrl)
+				service.EXPECT().
+					List(gomock.All(), gomock.All()).
+					Times(0)
+				return service
+			},
+			want: want{
+				code:   http.StatusBadRequest,
+				header: "text/plain",
+				body:   `invalid max_sum "lots"`,
+			},
+		},
+		{
+			name:   "negative_bad_date",
+			target: "/api/user/withdrawals?from=yesterday",
+			setup: func(t *testing.T) WithdrawalsService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockWithdrawalsService(ctrl)
+				service.EXPECT().
+					List(gomock.All(), gomock.All()).
+					Times(0)
+				return service
+			},
+			want: want{
+				code:   http.StatusBadRequest,
+				header: "text/plain",
+				body:   `invalid from "yesterday", expected RFC 3339 time or YYYY-MM-DD`,
+			},
+		},
+		{
+			name:   "negative_server_error",
+			target: "/api/user/withdrawals",
 			setup: func(t *testing.T) WithdrawalsService {
 				ctrl := gomock.NewController(t)
 				service := mocks.NewMockWithdrawalsService(ctrl)
 				service.EXPECT().
-					List(gomock.All()).
-					Return([]dto.WithdrawalsResp{}, errors.ErrUnexpected)
+					List(gomock.All(), gomock.All()).
+					Return(dto.WithdrawalsPage{}, errors.ErrUnexpected)
 				return service
 			},
 			want: want{
@@ -238,7 +339,7 @@ func TestWithdrawals_List(t *testing.T) {
 			service := test.setup(t)
 			handler := NewWithdrawals(service, logger)
 
-			r := httptest.NewRequest(http.MethodGet, "/withdrawals", nil)
+			r := httptest.NewRequest(http.MethodGet, test.target, nil)
 			w := httptest.NewRecorder()
 			handler.List(w, r)
 			res := w.Result()
@@ -249,6 +350,7 @@ func TestWithdrawals_List(t *testing.T) {
 				resContentType := w.Header().Get("Content-Type")
 				assert.Contains(t, resContentType, test.want.header, "Response Content-Type")
 			}
+			assert.Equal(t, test.want.cursor, res.Header.Get("X-Next-Cursor"), "Response next cursor")
 
 			resBody, err := io.ReadAll(res.Body)
 			if err != nil {
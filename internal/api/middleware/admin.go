@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+)
+
+// AdminAuth пропускает запросы с токеном администратора в заголовке Authorization
+// с ролью admin. Токен администратора не связан с пользователями и JWT,
+// остальные запросы передаются в fallback, обычно в Authorizer.Authorize.
+type AdminAuth struct {
+	token    []byte
+	fallback func(http.Handler) http.Handler
+}
+
+func NewAdminAuth(token string, fallback func(http.Handler) http.Handler) *AdminAuth {
+	return &AdminAuth{token: []byte(token), fallback: fallback}
+}
+
+func (a *AdminAuth) Authorize(next http.Handler) http.Handler {
+	fallback := a.fallback(next)
+
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || len(a.token) == 0 || subtle.ConstantTimeCompare([]byte(token), a.token) != 1 {
+			fallback.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), KeyUserRole, entity.UserRoleAdmin)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+
+	return http.HandlerFunc(fn)
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+)
+
+func TestAdminAuth_Authorize(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		header string
+		code   int
+	}{
+		{
+			name:   "success",
+			token:  "testAdminToken",
+			header: "Bearer testAdminToken",
+			code:   http.StatusOK,
+		},
+		{
+			name:   "fallback_without_header",
+			token:  "testAdminToken",
+			header: "",
+			code:   http.StatusUnauthorized,
+		},
+		{
+			name:   "fallback_without_bearer",
+			token:  "testAdminToken",
+			header: "testAdminToken",
+			code:   http.StatusUnauthorized,
+		},
+		{
+			name:   "fallback_wrong_token",
+			token:  "testAdminToken",
+			header: "Bearer testAdminTokem",
+			code:   http.StatusUnauthorized,
+		},
+		{
+			name:   "fallback_token_not_configured",
+			token:  "",
+			header: "Bearer ",
+			code:   http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				role, _ := UserRole(r.Context())
+				assert.Equal(t, entity.UserRoleAdmin, role, "Handler get role")
+				w.WriteHeader(http.StatusOK)
+			})
+			fallback := func(http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					http.Error(w, "", http.StatusUnauthorized)
+				})
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/api/admin/orders", nil)
+			if test.header != "" {
+				r.Header.Set("Authorization", test.header)
+			}
+			w := httptest.NewRecorder()
+			NewAdminAuth(test.token, fallback).Authorize(next).ServeHTTP(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.code, res.StatusCode, "Response status code")
+		})
+	}
+}
@@ -20,7 +20,10 @@ type Authorizer struct {
 
 type ContextKey string
 
-const KeyUserID ContextKey = "userID"
+const (
+	KeyUserID   ContextKey = "userID"
+	KeyUserRole ContextKey = "userRole"
+)
 
 func NewAuthorizer(srv AuthService) *Authorizer {
 	return &Authorizer{service: srv}
@@ -46,6 +49,7 @@ func (a *Authorizer) Authorize(next http.Handler) http.Handler {
 		}
 
 		ctx := context.WithValue(r.Context(), KeyUserID, user.ID)
+		ctx = context.WithValue(ctx, KeyUserRole, user.Role)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 
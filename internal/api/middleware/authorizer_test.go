@@ -23,6 +23,7 @@ func TestAuthorizer_Authorize(t *testing.T) {
 		code   int
 		body   string
 		userID uint64
+		role   string
 	}
 
 	tests := []struct {
@@ -39,13 +40,14 @@ func TestAuthorizer_Authorize(t *testing.T) {
 				service := mocks.NewMockAuthService(ctrl)
 				service.EXPECT().
 					User(gomock.All(), token).
-					Return(entity.User{ID: 13}, nil)
+					Return(entity.User{ID: 13, Role: entity.UserRoleSupport}, nil)
 				return service
 			},
 			want: want{
 				code:   http.StatusOK,
 				body:   "",
 				userID: 13,
+				role:   entity.UserRoleSupport,
 			},
 		},
 		{
@@ -108,6 +110,8 @@ func TestAuthorizer_Authorize(t *testing.T) {
 			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				userID := r.Context().Value(KeyUserID)
 				assert.Equal(t, test.want.userID, userID, "Handler get userID")
+				role, _ := UserRole(r.Context())
+				assert.Equal(t, test.want.role, role, "Handler get role")
 				w.WriteHeader(http.StatusOK)
 			})
 
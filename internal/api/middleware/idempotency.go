@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+)
+
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+type IdempotencyService interface {
+	Begin(ctx context.Context, key string, requestHash string) (entity.IdempotencyKey, bool, error)
+	Complete(ctx context.Context, key string, requestHash string, status int, contentType string, body []byte)
+	Release(ctx context.Context, key string, requestHash string)
+}
+
+type Idempotency struct {
+	service IdempotencyService
+}
+
+func NewIdempotency(srv IdempotencyService) *Idempotency {
+	return &Idempotency{service: srv}
+}
+
+// Handle должен стоять после Authorize, ключи хранятся в разрезе пользователей.
+func (i *Idempotency) Handle(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(HeaderIdempotencyKey)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := requestHash(r, body)
+		stored, replay, err := i.service.Begin(r.Context(), key, hash)
+		if err != nil {
+			switch {
+			case errors.Is(err, srvErrors.ErrIdempotencyKeyInvalid):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			case errors.Is(err, srvErrors.ErrIdempotencyKeyReused):
+				http.Error(w, err.Error(), http.StatusConflict)
+			case errors.Is(err, srvErrors.ErrIdempotencyRequestInProgress):
+				http.Error(w, err.Error(), http.StatusConflict)
+			default:
+				http.Error(w, "oops, something went wrong", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		if replay {
+			if stored.ContentType != "" {
+				w.Header().Set("Content-Type", stored.ContentType)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.StatusCode)
+			w.Write(stored.Body)
+			return
+		}
+
+		// Ответ отдается клиенту только после сохранения: иначе клиент, получивший его,
+		// мог бы повторить запрос раньше, чем ключ будет завершен, и получить 409.
+		rw := &idempotentResponseWriter{header: w.Header()}
+		next.ServeHTTP(rw, r)
+		if rw.status == 0 {
+			rw.status = http.StatusOK
+		}
+
+		// Клиент мог уже отключиться, но ответ все равно нужно сохранить для повтора
+		ctx := context.WithoutCancel(r.Context())
+		if rw.status >= http.StatusInternalServerError {
+			i.service.Release(ctx, key, hash)
+		} else {
+			i.service.Complete(ctx, key, hash, rw.status, w.Header().Get("Content-Type"), rw.body.Bytes())
+		}
+
+		w.WriteHeader(rw.status)
+		w.Write(rw.body.Bytes())
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.Path+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// idempotentResponseWriter накапливает ответ, заголовки пишутся сразу в исходный ResponseWriter.
+type idempotentResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *idempotentResponseWriter) Header() http.Header {
+	return r.header
+}
+
+func (r *idempotentResponseWriter) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+func (r *idempotentResponseWriter) WriteHeader(statusCode int) {
+	if r.status == 0 {
+		r.status = statusCode
+	}
+}
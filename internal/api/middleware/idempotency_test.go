@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/middleware/mocks"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+)
+
+func TestIdempotency_Handle(t *testing.T) {
+	key := "4b1d2c6e-5a1f-4f0e-9d6b-0f6b1c2a3d4e"
+	body := "5062821234567892"
+	hash := requestHash(httptest.NewRequest(http.MethodPost, "/api/user/orders", nil), []byte(body))
+
+	type want struct {
+		code       int
+		body       string
+		nextCalled bool
+	}
+
+	tests := []struct {
+		name   string
+		key    string
+		status int
+		setup  func(t *testing.T) IdempotencyService
+		want   want
+	}{
+		{
+			name:   "without_key",
+			key:    "",
+			status: http.StatusAccepted,
+			setup: func(t *testing.T) IdempotencyService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockIdempotencyService(ctrl)
+				service.EXPECT().Begin(gomock.All(), gomock.All(), gomock.All()).Times(0)
+				return service
+			},
+			want: want{code: http.StatusAccepted, body: "", nextCalled: true},
+		},
+		{
+			name:   "first_request",
+			key:    key,
+			status: http.StatusAccepted,
+			setup: func(t *testing.T) IdempotencyService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockIdempotencyService(ctrl)
+				service.EXPECT().
+					Begin(gomock.All(), key, hash).
+					Return(entity.IdempotencyKey{}, false, nil)
+				service.EXPECT().
+					Complete(gomock.All(), key, hash, http.StatusAccepted, "", []byte(nil))
+				return service
+			},
+			want: want{code: http.StatusAccepted, body: "", nextCalled: true},
+		},
+		{
+			name:   "replay",
+			key:    key,
+			status: http.StatusAccepted,
+			setup: func(t *testing.T) IdempotencyService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockIdempotencyService(ctrl)
+				service.EXPECT().
+					Begin(gomock.All(), key, hash).
+					Return(entity.IdempotencyKey{StatusCode: http.StatusConflict, Body: []byte("stored")}, true, nil)
+				service.EXPECT().Complete(gomock.All(), gomock.All(), gomock.All(), gomock.All(), gomock.All(), gomock.All()).Times(0)
+				return service
+			},
+			want: want{code: http.StatusConflict, body: "stored", nextCalled: false},
+		},
+		{
+			name:   "server_error_releases_key",
+			key:    key,
+			status: http.StatusInternalServerError,
+			setup: func(t *testing.T) IdempotencyService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockIdempotencyService(ctrl)
+				service.EXPECT().
+					Begin(gomock.All(), key, hash).
+					Return(entity.IdempotencyKey{}, false, nil)
+				service.EXPECT().Release(gomock.All(), key, hash)
+				return service
+			},
+			want: want{code: http.StatusInternalServerError, body: "", nextCalled: true},
+		},
+		{
+			name:   "negative_key_reused",
+			key:    key,
+			status: http.StatusAccepted,
+			setup: func(t *testing.T) IdempotencyService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockIdempotencyService(ctrl)
+				service.EXPECT().
+					Begin(gomock.All(), key, hash).
+					Return(entity.IdempotencyKey{}, false, errors.ErrIdempotencyKeyReused)
+				return service
+			},
+			want: want{
+				code:       http.StatusConflict,
+				body:       errors.ErrIdempotencyKeyReused.Error(),
+				nextCalled: false,
+			},
+		},
+		{
+			name:   "negative_in_progress",
+			key:    key,
+			status: http.StatusAccepted,
+			setup: func(t *testing.T) IdempotencyService {
+				ctrl := gomock.NewController(t)
+				service := mocks.NewMockIdempotencyService(ctrl)
+				service.EXPECT().
+					Begin(gomock.All(), key, hash).
+					Return(entity.IdempotencyKey{}, false, errors.ErrIdempotencyRequestInProgress)
+				return service
+			},
+			want: want{
+				code:       http.StatusConflict,
+				body:       errors.ErrIdempotencyRequestInProgress.Error(),
+				nextCalled: false,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			nextCalled := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				nextCalled = true
+				b, err := io.ReadAll(r.Body)
+				assert.NoError(t, err, "Read body in next handler")
+				assert.Equal(t, body, string(b), "Body in next handler")
+				w.WriteHeader(test.status)
+			})
+
+			r := httptest.NewRequest(http.MethodPost, "/api/user/orders", strings.NewReader(body))
+			r = r.WithContext(context.WithValue(r.Context(), KeyUserID, uint64(13)))
+			if test.key != "" {
+				r.Header.Set(HeaderIdempotencyKey, test.key)
+			}
+			w := httptest.NewRecorder()
+
+			NewIdempotency(test.setup(t)).Handle(next).ServeHTTP(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			resBody, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.want.code, res.StatusCode, "Response status code")
+			assert.Equal(t, test.want.body, strings.TrimSuffix(string(resBody), "\n"), "Response body")
+			assert.Equal(t, test.want.nextCalled, nextCalled, "Next handler called")
+		})
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/metrics"
 )
 
 type Logger struct {
@@ -15,7 +18,7 @@ type requestData = logger.RequestLogData
 type responseData = logger.ResponseLogData
 
 type HTTPloger interface {
-	RequestInfo(message string, req *requestData, resp *responseData)
+	RequestInfo(message string, req *requestData, resp *responseData, fields ...logger.Field)
 }
 
 func NewLogger(l HTTPloger) *Logger {
@@ -48,12 +51,21 @@ func (l *Logger) Log(next http.Handler) http.Handler {
 			responseData.Status = http.StatusOK
 		}
 
-		l.logger.RequestInfo("server api", requestData, responseData)
+		l.logger.RequestInfo("server api", requestData, responseData, logger.Request(r.Context()))
+		metrics.ObserveHTTPRequest(r.Method, routePattern(r), responseData.Status, requestData.Duration)
 	}
 
 	return http.HandlerFunc(fn)
 }
 
+// routePattern шаблон маршрута chi, известен только после обработки запроса роутером.
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		return rctx.RoutePattern()
+	}
+	return ""
+}
+
 type loggingResponseWriter struct {
 	http.ResponseWriter
 	responseData *responseData
@@ -69,3 +81,8 @@ func (r *loggingResponseWriter) WriteHeader(statusCode int) {
 	r.ResponseWriter.WriteHeader(statusCode)
 	r.responseData.Status = statusCode
 }
+
+// Unwrap нужен http.ResponseController, например для Flush в потоке событий.
+func (r *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
 )
 
 func TestLogger(t *testing.T) {
@@ -32,7 +34,7 @@ func makeLoggerMock(t *testing.T) httpLoggerMock {
 	return httpLoggerMock{t: t}
 }
 
-func (m httpLoggerMock) RequestInfo(message string, req *requestData, resp *responseData) {
+func (m httpLoggerMock) RequestInfo(message string, req *requestData, resp *responseData, fields ...logger.Field) {
 	assert.Equal(m.t, "server api", message, "Log message")
 	assert.Equal(m.t, "/path", req.URI, "Log request URI")
 	assert.Equal(m.t, http.MethodGet, req.Method, "Log request method")
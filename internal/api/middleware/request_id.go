@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/requestid"
+)
+
+// RequestID принимает X-Request-ID клиента или балансировщика, а если его нет
+// или он некорректен, генерирует новый. Идентификатор возвращается в ответе.
+func RequestID(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+	}
+
+	return http.HandlerFunc(fn)
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/requestid"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		generate bool
+	}{
+		{name: "accepted", header: "3f2c9a1e-7b4d-4c2a-9e1f-0a6b5c4d3e2f"},
+		{name: "generated", header: "", generate: true},
+		{name: "replaced_invalid", header: "bad id\r\n", generate: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = requestid.FromContext(r.Context())
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/api/user/orders", nil)
+			if test.header != "" {
+				r.Header.Set(requestid.Header, test.header)
+			}
+			w := httptest.NewRecorder()
+			RequestID(next).ServeHTTP(w, r)
+
+			if test.generate {
+				assert.True(t, requestid.Valid(got), "Generated request id")
+				assert.NotEqual(t, test.header, got, "Request id from header")
+			} else {
+				assert.Equal(t, test.header, got, "Request id in context")
+			}
+			assert.Equal(t, got, w.Header().Get(requestid.Header), "Request id in response")
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"slices"
+)
+
+// UserRole возвращает роль пользователя, сохраненную Authorizer.
+func UserRole(ctx context.Context) (string, bool) {
+	role, ok := ctx.Value(KeyUserRole).(string)
+	return role, ok && role != ""
+}
+
+// RequireRole пропускает только пользователей с одной из ролей roles.
+// Ставится после Authorizer.Authorize.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			role, ok := UserRole(r.Context())
+			if !ok {
+				http.Error(w, "", http.StatusUnauthorized)
+				return
+			}
+			if !slices.Contains(roles, role) {
+				http.Error(w, "", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+)
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name  string
+		role  string
+		roles []string
+		code  int
+	}{
+		{
+			name:  "success",
+			role:  entity.UserRoleAdmin,
+			roles: []string{entity.UserRoleAdmin},
+			code:  http.StatusOK,
+		},
+		{
+			name:  "success_one_of_roles",
+			role:  entity.UserRoleSupport,
+			roles: []string{entity.UserRoleAdmin, entity.UserRoleSupport},
+			code:  http.StatusOK,
+		},
+		{
+			name:  "negative_role_not_allowed",
+			role:  entity.UserRoleUser,
+			roles: []string{entity.UserRoleAdmin, entity.UserRoleSupport},
+			code:  http.StatusForbidden,
+		},
+		{
+			name:  "negative_without_role",
+			role:  "",
+			roles: []string{entity.UserRoleAdmin},
+			code:  http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.role != "" {
+				r = r.WithContext(context.WithValue(r.Context(), KeyUserRole, test.role))
+			}
+			w := httptest.NewRecorder()
+			RequireRole(test.roles...)(next).ServeHTTP(w, r)
+			res := w.Result()
+			defer res.Body.Close()
+
+			assert.Equal(t, test.code, res.StatusCode, "Response status code")
+		})
+	}
+}
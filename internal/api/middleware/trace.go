@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Trace создает серверный спан на запрос и принимает W3C trace context от клиента.
+// Шаблон маршрута известен только после обработки запроса роутером,
+// поэтому имя спана уточняется в конце запроса.
+func Trace(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+
+		if route := routePattern(r); route != "" {
+			span := trace.SpanFromContext(r.Context())
+			span.SetName(spanName(r))
+			span.SetAttributes(attribute.String("http.route", route))
+		}
+	}
+
+	return otelhttp.NewHandler(
+		http.HandlerFunc(fn),
+		"http.server",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return spanName(r)
+		}),
+	)
+}
+
+func spanName(r *http.Request) string {
+	if route := routePattern(r); route != "" {
+		return r.Method + " " + route
+	}
+	return r.Method
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/handler"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/middleware"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/metrics"
 )
 
 type Logger interface {
@@ -13,31 +15,60 @@ type Logger interface {
 }
 type AuthService interface {
 	handler.AuthService
+	handler.AccountService
 	middleware.AuthService
 }
 type OrderService = handler.OrderService
 type BalanceService = handler.BalanceService
 type WithdrawalsService = handler.WithdrawalsService
+type IdempotencyService = middleware.IdempotencyService
+type WebhookService = handler.WebhookService
+type EventsService = handler.EventsService
+type AccrualMonitor = handler.AccrualMonitor
+type AdminOrderService = handler.AdminOrderService
+type HealthService = handler.HealthService
 
 func New(
 	a AuthService,
 	o OrderService,
 	b BalanceService,
 	w WithdrawalsService,
+	i IdempotencyService,
+	wh WebhookService,
+	e EventsService,
+	am AccrualMonitor,
+	ao AdminOrderService,
+	hs HealthService,
+	adminToken string,
 	l Logger,
 ) *chi.Mux {
 	logger := middleware.NewLogger(l)
 	authorizer := middleware.NewAuthorizer(a)
+	idempotency := middleware.NewIdempotency(i)
 
-	authHandler := handler.NewAuth(a)
+	authHandler := handler.NewAuth(a, l)
+	accountHandler := handler.NewAccount(a)
 	orderHandler := handler.NewOrder(o, l)
 	balanceHandler := handler.NewBalance(b, l)
 	withdrawalsHandler := handler.NewWithdrawals(w, l)
+	webhookHandler := handler.NewWebhook(wh, l)
+	eventsHandler := handler.NewEvents(e, l)
+	accrualHandler := handler.NewAccrual(am, l)
+	adminHandler := handler.NewAdmin(ao, l)
+	healthHandler := handler.NewHealth(hs, l)
 
 	router := chi.NewRouter()
+	router.Use(middleware.Trace)
+	router.Use(middleware.RequestID)
 	router.Use(logger.Log)
 	router.Use(middleware.GzipDecompress)
 
+	router.Get("/.well-known/jwks.json", authHandler.JWKS)
+	router.Get("/health/accrual", accrualHandler.Health)
+	router.Get("/healthz", healthHandler.Live)
+	router.Get("/readyz", healthHandler.Ready)
+	router.Handle("/metrics", metrics.Handler())
+
 	router.Route("/api/user", func(r chi.Router) {
 		r.Route("/register", func(r chi.Router) {
 			r.Post("/", authHandler.Register)
@@ -45,12 +76,26 @@ func New(
 		r.Route("/login", func(r chi.Router) {
 			r.Post("/", authHandler.Login)
 		})
+		r.Route("/token/refresh", func(r chi.Router) {
+			r.Post("/", authHandler.Refresh)
+		})
 
 		r.Group(func(r chi.Router) {
 			r.Use(authorizer.Authorize)
 
+			r.Route("/logout", func(r chi.Router) {
+				r.Post("/", authHandler.Logout)
+			})
+			r.Route("/password", func(r chi.Router) {
+				r.Put("/", accountHandler.ChangePassword)
+			})
+			r.Delete("/", accountHandler.Delete)
+
 			r.Route("/orders", func(r chi.Router) {
-				r.Post("/", orderHandler.Create)
+				r.Group(func(r chi.Router) {
+					r.Use(idempotency.Handle)
+					r.Post("/", orderHandler.Create)
+				})
 				r.Group(func(r chi.Router) {
 					r.Use(middleware.GzipCompress)
 					r.Get("/", orderHandler.List)
@@ -59,7 +104,9 @@ func New(
 
 			r.Route("/balance", func(r chi.Router) {
 				r.Get("/", balanceHandler.UserBalance)
+				r.With(middleware.GzipCompress).Get("/history", balanceHandler.History)
 				r.Route("/withdraw", func(r chi.Router) {
+					r.Use(idempotency.Handle)
 					r.Post("/", withdrawalsHandler.Withdraw)
 				})
 			})
@@ -68,6 +115,45 @@ func New(
 				r.Use(middleware.GzipCompress)
 				r.Get("/", withdrawalsHandler.List)
 			})
+
+			r.Route("/webhooks", func(r chi.Router) {
+				r.Post("/", webhookHandler.Create)
+				r.Get("/", webhookHandler.List)
+				r.Delete("/{id}", webhookHandler.Delete)
+			})
+
+			r.Get("/events", eventsHandler.Stream)
+		})
+	})
+
+	// Заказы и состояние очереди расчета могут смотреть поддержка и администраторы,
+	// менять заказы — только администраторы.
+	// Баланс корректируют и поддержка, и администраторы.
+	adminAuth := middleware.NewAdminAuth(adminToken, authorizer.Authorize)
+	router.Route("/api/admin", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(adminAuth.Authorize)
+			r.Use(middleware.RequireRole(entity.UserRoleAdmin, entity.UserRoleSupport))
+
+			r.Get("/accrual", accrualHandler.State)
+			r.Route("/orders", func(r chi.Router) {
+				r.Get("/", adminHandler.Orders)
+				r.Get("/{number}", adminHandler.Order)
+
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireRole(entity.UserRoleAdmin))
+					r.Post("/{number}/recheck", adminHandler.Recheck)
+					r.Put("/{number}/status", adminHandler.SetStatus)
+				})
+			})
+		})
+
+		// Корректировка записывает оператора, поэтому служебный токен здесь не принимается.
+		r.Group(func(r chi.Router) {
+			r.Use(authorizer.Authorize)
+			r.Use(middleware.RequireRole(entity.UserRoleAdmin, entity.UserRoleSupport))
+
+			r.Post("/users/{id}/balance/adjustments", balanceHandler.Adjust)
 		})
 	})
 
@@ -7,17 +7,51 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	accrual "github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/config"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
 )
 
-var ErrNotNaturalNumber = errors.New("value must be a natural number")
+const (
+	WithdrawPolicyReject  = "reject"
+	WithdrawPolicyPartial = "partial"
+
+	LoginAttemptsStorePostgres = "postgres"
+	LoginAttemptsStoreMemory   = "memory"
+
+	TraceExporterNone   = "none"
+	TraceExporterStdout = "stdout"
+	TraceExporterOTLP   = "otlp"
+)
+
+var (
+	ErrNotNaturalNumber      = errors.New("value must be a natural number")
+	ErrNotUnsignedNumber     = errors.New("value must be a non-negative integer")
+	ErrUnknownWithdrawPolicy = errors.New("value must be one of: reject, partial")
+	ErrUnknownAttemptsStore  = errors.New("value must be one of: postgres, memory")
+	ErrPercentOutOfRange     = errors.New("value must be a percent from 0 to 100")
+	ErrUnknownTraceExporter  = errors.New("value must be one of: none, stdout, otlp")
+	ErrUnknownLogEnv         = errors.New("value must be one of: development, production")
+	ErrUnknownLogLevel       = errors.New("value must be one of: debug, info, warn, error")
+)
 
 type Config struct {
-	ServerAddr  string
-	DatabaseDSN string
-	JWTsecret   string
-	AccrualGfg  *accrual.Config
+	ServerAddr       string
+	DatabaseDSN      string
+	JWTSigningKey    string
+	JWTVerifyKeys    []string
+	AccessTokenTTL   uint64
+	RefreshTokenTTL  uint64
+	IdempotencyTTL   uint64
+	WithdrawOrderCap uint64
+	LoginAttempts    string
+	OutboxSink       string
+	AdminToken       string
+	TraceExporter    string
+	ShutdownDrain    uint64
+	AccrualGfg       *accrual.Config
+	Log              *logger.Config
 }
 
 func Load() (*Config, error) {
@@ -25,11 +59,40 @@ func Load() (*Config, error) {
 		serverAddr   = newStringVal(":8080")
 		dbDSN        = newStringVal("")
 		accrualAddr  = newStringVal("")
-		secret       = newStringVal("J3gdkl8v3hPJ8")
+		signingKey   = newStringVal("")
+		verifyKeys   = newStringVal("")
+		accessTTL    = newNaturalVal(15)
+		refreshTTL   = newNaturalVal(720)
 		rateLimit    = newNaturalVal(10)
+		rps          = newNaturalVal(10)
+		burst        = newNaturalVal(10)
+		brFailures   = newNaturalVal(5)
+		brSuccesses  = newNaturalVal(2)
+		brTimeout    = newNaturalVal(30)
 		pollInterval = newNaturalVal(1)
 		processDelay = newNaturalVal(10)
 		retryCount   = newNaturalVal(3)
+		failedRetry  = newNaturalVal(10)
+		maxDelay     = newNaturalVal(3600)
+		jitter       = newUintVal(20)
+		batchSize    = newNaturalVal(50)
+		leaseTTL     = newNaturalVal(60)
+		workerID     = newStringVal("")
+		idemTTL      = newNaturalVal(24)
+		wPolicy      = newStringVal(WithdrawPolicyReject)
+		wCap         = newNaturalVal(3)
+		attempts     = newStringVal(LoginAttemptsStorePostgres)
+		outboxSink   = newStringVal("log")
+		adminToken   = newStringVal("")
+		traceExp     = newStringVal(TraceExporterNone)
+		drainDelay   = newUintVal(5)
+		logEnv       = newStringVal(logger.EnvDevelopment)
+		logLevel     = newStringVal("info")
+		logFile      = newStringVal("")
+		logMaxSize   = newNaturalVal(100)
+		logBackups   = newNaturalVal(5)
+		logMaxAge    = newNaturalVal(30)
+		logSampling  = newNaturalVal(100)
 	)
 
 	flagSet := flag.NewFlagSet("", flag.ContinueOnError)
@@ -37,11 +100,40 @@ func Load() (*Config, error) {
 	flagSet.Var(serverAddr, "a", "address to serve")
 	flagSet.Var(dbDSN, "d", "database dsn")
 	flagSet.Var(accrualAddr, "r", "accrual system address")
-	flagSet.Var(secret, "s", "jwt secret")
+	flagSet.Var(signingKey, "k", "path to PEM private key for signing jwt, RSA or Ed25519")
+	flagSet.Var(verifyKeys, "kv", "comma separated paths to PEM keys still accepted for jwt verification")
+	flagSet.Var(accessTTL, "at", "access token lifetime in minutes")
+	flagSet.Var(refreshTTL, "rt", "refresh token lifetime in hours")
 	flagSet.Var(rateLimit, "rl", "accrual system rate limit, limit of simultaneous requests")
+	flagSet.Var(rps, "rps", "accrual system requests per second, lowered by the limit reported in 429 responses")
+	flagSet.Var(burst, "rb", "accrual system requests burst")
+	flagSet.Var(brFailures, "bf", "accrual system failures in a row that open circuit breaker")
+	flagSet.Var(brSuccesses, "bs", "successful probe requests in a row that close circuit breaker")
+	flagSet.Var(brTimeout, "bt", "seconds circuit breaker stays open before probe requests")
 	flagSet.Var(pollInterval, "pi", "accrual system db poll interval in seconds")
-	flagSet.Var(processDelay, "pd", "accrual system process delay in seconds")
+	flagSet.Var(processDelay, "pd", "accrual system process delay in seconds, doubled on every failed attempt")
 	flagSet.Var(retryCount, "rc", "accrual system retry count for unregistered orders")
+	flagSet.Var(failedRetry, "fr", "accrual system retry count on errors, then the order becomes FAILED")
+	flagSet.Var(maxDelay, "rm", "max delay between accrual system retries in seconds")
+	flagSet.Var(jitter, "rj", "random decrease of the retry delay in percent, 0 disables it")
+	flagSet.Var(batchSize, "qb", "max orders claimed for processing at once")
+	flagSet.Var(leaseTTL, "ql", "order processing lease in seconds, then another instance may claim it")
+	flagSet.Var(workerID, "wid", "instance id in the order queue, hostname and pid by default")
+	flagSet.Var(idemTTL, "it", "idempotency keys retention in hours")
+	flagSet.Var(wPolicy, "wp", "repeated withdrawals for the same order: reject or partial")
+	flagSet.Var(wCap, "wc", "max partial withdrawals for the same order with partial policy")
+	flagSet.Var(attempts, "la", "failed login attempts storage: postgres or memory (single replica only)")
+	flagSet.Var(outboxSink, "os", "accrual events sink: log, file:<path> or http(s) url")
+	flagSet.Var(adminToken, "adm", "service bearer token for /api/admin, grants the admin role")
+	flagSet.Var(traceExp, "te", "trace exporter: none, stdout or otlp (configured by OTEL_EXPORTER_OTLP_* env)")
+	flagSet.Var(drainDelay, "sd", "seconds /readyz answers 503 before the http server stops on shutdown")
+	flagSet.Var(logEnv, "le", "log format: development (console) or production (json)")
+	flagSet.Var(logLevel, "ll", "log level: debug, info, warn or error")
+	flagSet.Var(logFile, "lf", "log file path with rotation, stderr by default")
+	flagSet.Var(logMaxSize, "lfs", "log file size in megabytes before rotation")
+	flagSet.Var(logBackups, "lfb", "rotated log files to keep")
+	flagSet.Var(logMaxAge, "lfa", "days to keep rotated log files")
+	flagSet.Var(logSampling, "ls", "request logs per second written in full, then every n-th")
 
 	if err := flagSet.Parse(os.Args[1:]); err != nil {
 		return &Config{}, fmt.Errorf("failed to parse flags")
@@ -52,9 +144,33 @@ func Load() (*Config, error) {
 		serverAddr.Set(envAddr)
 	}
 
-	envSecret, ok := os.LookupEnv("JWT_SECRET")
-	if ok && !secret.isset {
-		secret.Set(envSecret)
+	envSigningKey, ok := os.LookupEnv("JWT_SIGNING_KEY")
+	if ok && !signingKey.isset {
+		signingKey.Set(envSigningKey)
+	}
+
+	envVerifyKeys, ok := os.LookupEnv("JWT_VERIFY_KEYS")
+	if ok && !verifyKeys.isset {
+		verifyKeys.Set(envVerifyKeys)
+	}
+	if signingKey.value == "" && verifyKeys.value != "" {
+		return &Config{}, fmt.Errorf("jwt verify keys require a signing key")
+	}
+
+	envAccessTTL, ok := os.LookupEnv("ACCESS_TOKEN_TTL")
+	if ok && !accessTTL.isSet {
+		err := accessTTL.Set(envAccessTTL)
+		if err != nil {
+			return &Config{}, fmt.Errorf("ACCESS_TOKEN_TTL %w", err)
+		}
+	}
+
+	envRefreshTTL, ok := os.LookupEnv("REFRESH_TOKEN_TTL")
+	if ok && !refreshTTL.isSet {
+		err := refreshTTL.Set(envRefreshTTL)
+		if err != nil {
+			return &Config{}, fmt.Errorf("REFRESH_TOKEN_TTL %w", err)
+		}
 	}
 
 	envDSN, ok := os.LookupEnv("DATABASE_URI")
@@ -97,6 +213,33 @@ func Load() (*Config, error) {
 		}
 	}
 
+	envFailedRetry, ok := os.LookupEnv("ACCRUAL_FAILED_RETRY_COUNT")
+	if ok && !failedRetry.isSet {
+		err := failedRetry.Set(envFailedRetry)
+		if err != nil {
+			return &Config{}, fmt.Errorf("ACCRUAL_FAILED_RETRY_COUNT %w", err)
+		}
+	}
+
+	envMaxDelay, ok := os.LookupEnv("ACCRUAL_RETRY_MAX_DELAY")
+	if ok && !maxDelay.isSet {
+		err := maxDelay.Set(envMaxDelay)
+		if err != nil {
+			return &Config{}, fmt.Errorf("ACCRUAL_RETRY_MAX_DELAY %w", err)
+		}
+	}
+
+	envJitter, ok := os.LookupEnv("ACCRUAL_RETRY_JITTER")
+	if ok && !jitter.isSet {
+		err := jitter.Set(envJitter)
+		if err != nil {
+			return &Config{}, fmt.Errorf("ACCRUAL_RETRY_JITTER %w", err)
+		}
+	}
+	if jitter.value > 100 {
+		return &Config{}, fmt.Errorf("retry jitter %w", ErrPercentOutOfRange)
+	}
+
 	envRateLimit, ok := os.LookupEnv("ACCRUAL_RATE_LIMIT")
 	if ok && !rateLimit.isSet {
 		err := rateLimit.Set(envRateLimit)
@@ -105,22 +248,246 @@ func Load() (*Config, error) {
 		}
 	}
 
+	envBatchSize, ok := os.LookupEnv("ACCRUAL_QUEUE_BATCH_SIZE")
+	if ok && !batchSize.isSet {
+		err := batchSize.Set(envBatchSize)
+		if err != nil {
+			return &Config{}, fmt.Errorf("ACCRUAL_QUEUE_BATCH_SIZE %w", err)
+		}
+	}
+
+	envLeaseTTL, ok := os.LookupEnv("ACCRUAL_QUEUE_LEASE")
+	if ok && !leaseTTL.isSet {
+		err := leaseTTL.Set(envLeaseTTL)
+		if err != nil {
+			return &Config{}, fmt.Errorf("ACCRUAL_QUEUE_LEASE %w", err)
+		}
+	}
+
+	envWorkerID, ok := os.LookupEnv("ACCRUAL_WORKER_ID")
+	if ok && !workerID.isset {
+		workerID.Set(envWorkerID)
+	}
+
+	envRPS, ok := os.LookupEnv("ACCRUAL_RPS")
+	if ok && !rps.isSet {
+		err := rps.Set(envRPS)
+		if err != nil {
+			return &Config{}, fmt.Errorf("ACCRUAL_RPS %w", err)
+		}
+	}
+
+	envBurst, ok := os.LookupEnv("ACCRUAL_BURST")
+	if ok && !burst.isSet {
+		err := burst.Set(envBurst)
+		if err != nil {
+			return &Config{}, fmt.Errorf("ACCRUAL_BURST %w", err)
+		}
+	}
+
+	envBrFailures, ok := os.LookupEnv("ACCRUAL_BREAKER_FAILURES")
+	if ok && !brFailures.isSet {
+		err := brFailures.Set(envBrFailures)
+		if err != nil {
+			return &Config{}, fmt.Errorf("ACCRUAL_BREAKER_FAILURES %w", err)
+		}
+	}
+
+	envBrSuccesses, ok := os.LookupEnv("ACCRUAL_BREAKER_SUCCESSES")
+	if ok && !brSuccesses.isSet {
+		err := brSuccesses.Set(envBrSuccesses)
+		if err != nil {
+			return &Config{}, fmt.Errorf("ACCRUAL_BREAKER_SUCCESSES %w", err)
+		}
+	}
+
+	envBrTimeout, ok := os.LookupEnv("ACCRUAL_BREAKER_TIMEOUT")
+	if ok && !brTimeout.isSet {
+		err := brTimeout.Set(envBrTimeout)
+		if err != nil {
+			return &Config{}, fmt.Errorf("ACCRUAL_BREAKER_TIMEOUT %w", err)
+		}
+	}
+
+	envIdemTTL, ok := os.LookupEnv("IDEMPOTENCY_KEY_TTL")
+	if ok && !idemTTL.isSet {
+		err := idemTTL.Set(envIdemTTL)
+		if err != nil {
+			return &Config{}, fmt.Errorf("IDEMPOTENCY_KEY_TTL %w", err)
+		}
+	}
+
+	envPolicy, ok := os.LookupEnv("WITHDRAW_ORDER_POLICY")
+	if ok && !wPolicy.isset {
+		wPolicy.Set(envPolicy)
+	}
+
+	envCap, ok := os.LookupEnv("WITHDRAW_ORDER_MAX_PAYMENTS")
+	if ok && !wCap.isSet {
+		err := wCap.Set(envCap)
+		if err != nil {
+			return &Config{}, fmt.Errorf("WITHDRAW_ORDER_MAX_PAYMENTS %w", err)
+		}
+	}
+
+	var withdrawCap uint64
+	switch wPolicy.value {
+	case WithdrawPolicyReject:
+		withdrawCap = 1
+	case WithdrawPolicyPartial:
+		withdrawCap = wCap.value
+	default:
+		return &Config{}, fmt.Errorf("withdraw order policy %w", ErrUnknownWithdrawPolicy)
+	}
+
+	envAttempts, ok := os.LookupEnv("LOGIN_ATTEMPTS_STORE")
+	if ok && !attempts.isset {
+		attempts.Set(envAttempts)
+	}
+	if attempts.value != LoginAttemptsStorePostgres && attempts.value != LoginAttemptsStoreMemory {
+		return &Config{}, fmt.Errorf("login attempts store %w", ErrUnknownAttemptsStore)
+	}
+
+	envOutboxSink, ok := os.LookupEnv("OUTBOX_SINK")
+	if ok && !outboxSink.isset {
+		outboxSink.Set(envOutboxSink)
+	}
+
+	envAdminToken, ok := os.LookupEnv("ADMIN_API_TOKEN")
+	if ok && !adminToken.isset {
+		adminToken.Set(envAdminToken)
+	}
+
+	envTraceExp, ok := os.LookupEnv("TRACE_EXPORTER")
+	if ok && !traceExp.isset {
+		traceExp.Set(envTraceExp)
+	}
+	switch traceExp.value {
+	case TraceExporterNone, TraceExporterStdout, TraceExporterOTLP:
+	default:
+		return &Config{}, fmt.Errorf("trace exporter %w", ErrUnknownTraceExporter)
+	}
+
+	envDrainDelay, ok := os.LookupEnv("SHUTDOWN_DRAIN_DELAY")
+	if ok && !drainDelay.isSet {
+		err := drainDelay.Set(envDrainDelay)
+		if err != nil {
+			return &Config{}, fmt.Errorf("SHUTDOWN_DRAIN_DELAY %w", err)
+		}
+	}
+
+	envLogEnv, ok := os.LookupEnv("LOG_ENV")
+	if ok && !logEnv.isset {
+		logEnv.Set(envLogEnv)
+	}
+	if logEnv.value != logger.EnvDevelopment && logEnv.value != logger.EnvProduction {
+		return &Config{}, fmt.Errorf("log env %w", ErrUnknownLogEnv)
+	}
+
+	envLogLevel, ok := os.LookupEnv("LOG_LEVEL")
+	if ok && !logLevel.isset {
+		logLevel.Set(envLogLevel)
+	}
+	switch logLevel.value {
+	case "debug", "info", "warn", "error":
+	default:
+		return &Config{}, fmt.Errorf("log level %w", ErrUnknownLogLevel)
+	}
+
+	envLogFile, ok := os.LookupEnv("LOG_FILE")
+	if ok && !logFile.isset {
+		logFile.Set(envLogFile)
+	}
+
+	envLogMaxSize, ok := os.LookupEnv("LOG_FILE_MAX_SIZE")
+	if ok && !logMaxSize.isSet {
+		err := logMaxSize.Set(envLogMaxSize)
+		if err != nil {
+			return &Config{}, fmt.Errorf("LOG_FILE_MAX_SIZE %w", err)
+		}
+	}
+
+	envLogBackups, ok := os.LookupEnv("LOG_FILE_MAX_BACKUPS")
+	if ok && !logBackups.isSet {
+		err := logBackups.Set(envLogBackups)
+		if err != nil {
+			return &Config{}, fmt.Errorf("LOG_FILE_MAX_BACKUPS %w", err)
+		}
+	}
+
+	envLogMaxAge, ok := os.LookupEnv("LOG_FILE_MAX_AGE")
+	if ok && !logMaxAge.isSet {
+		err := logMaxAge.Set(envLogMaxAge)
+		if err != nil {
+			return &Config{}, fmt.Errorf("LOG_FILE_MAX_AGE %w", err)
+		}
+	}
+
+	envLogSampling, ok := os.LookupEnv("LOG_REQUEST_SAMPLING")
+	if ok && !logSampling.isSet {
+		err := logSampling.Set(envLogSampling)
+		if err != nil {
+			return &Config{}, fmt.Errorf("LOG_REQUEST_SAMPLING %w", err)
+		}
+	}
+
 	config := Config{
-		ServerAddr:  serverAddr.value,
-		DatabaseDSN: dbDSN.value,
-		JWTsecret:   secret.value,
+		ServerAddr:       serverAddr.value,
+		DatabaseDSN:      dbDSN.value,
+		JWTSigningKey:    signingKey.value,
+		JWTVerifyKeys:    splitList(verifyKeys.value),
+		AccessTokenTTL:   accessTTL.value,
+		RefreshTokenTTL:  refreshTTL.value,
+		IdempotencyTTL:   idemTTL.value,
+		WithdrawOrderCap: withdrawCap,
+		LoginAttempts:    attempts.value,
+		OutboxSink:       outboxSink.value,
+		AdminToken:       adminToken.value,
+		TraceExporter:    traceExp.value,
+		ShutdownDrain:    drainDelay.value,
 		AccrualGfg: &accrual.Config{
 			AccrualAddr:         accrualAddr.value,
 			RateLimit:           rateLimit.value,
+			RequestsPerSecond:   rps.value,
+			Burst:               burst.value,
+			BreakerFailures:     brFailures.value,
+			BreakerSuccesses:    brSuccesses.value,
+			BreakerTimeout:      brTimeout.value,
 			PollInterval:        pollInterval.value,
 			ProcessDelay:        processDelay.value,
 			UnregisteredRetries: retryCount.value,
+			FailedRetries:       failedRetry.value,
+			MaxRetryDelay:       maxDelay.value,
+			RetryJitter:         jitter.value,
+			BatchSize:           batchSize.value,
+			LeaseDuration:       leaseTTL.value,
+			WorkerID:            workerID.value,
+		},
+		Log: &logger.Config{
+			Env:             logEnv.value,
+			Level:           logLevel.value,
+			File:            logFile.value,
+			MaxSize:         logMaxSize.value,
+			MaxBackups:      logBackups.value,
+			MaxAge:          logMaxAge.value,
+			RequestSampling: logSampling.value,
 		},
 	}
 
 	return &config, nil
 }
 
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
 type stringVal struct {
 	value string
 	isset bool
@@ -169,3 +536,27 @@ func (n *naturalVal) Set(flagValue string) error {
 	n.isSet = true
 	return nil
 }
+
+// uintVal в отличие от naturalVal допускает 0.
+type uintVal struct {
+	value uint64
+	isSet bool
+}
+
+func newUintVal(v uint64) *uintVal {
+	return &uintVal{value: v}
+}
+
+func (u *uintVal) String() string {
+	return strconv.FormatUint(u.value, 10)
+}
+
+func (u *uintVal) Set(flagValue string) error {
+	v, err := strconv.ParseUint(flagValue, 10, 64)
+	if err != nil {
+		return ErrNotUnsignedNumber
+	}
+	u.value = v
+	u.isSet = true
+	return nil
+}
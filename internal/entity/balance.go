@@ -1,7 +1,19 @@
 package entity
 
+import "github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+
 type Balance struct {
-	UserID  uint64  `db:"user_id"`
-	Balance float64 `db:"balance"`
-	Debited float64 `db:"debited"`
+	UserID  uint64       `db:"user_id"`
+	Balance money.Amount `db:"balance"`
+	Debited money.Amount `db:"debited"`
+}
+
+// BalanceReconciliation результат сверки сохраненного баланса с журналом операций.
+type BalanceReconciliation struct {
+	Cached Balance
+	Ledger Balance
+}
+
+func (r BalanceReconciliation) Consistent() bool {
+	return r.Cached == r.Ledger
 }
@@ -0,0 +1,30 @@
+package entity
+
+import "github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+
+// UserEventsChannel канал LISTEN/NOTIFY, по которому реплики узнают об изменениях.
+const UserEventsChannel = "user_events"
+
+const (
+	UserEventOrder   = "order"
+	UserEventBalance = "balance"
+)
+
+// UserEvent изменение данных пользователя, в зависимости от Type заполнено Order или Balance.
+type UserEvent struct {
+	UserID  uint64        `json:"user_id"`
+	Type    string        `json:"type"`
+	Order   *OrderEvent   `json:"order,omitempty"`
+	Balance *BalanceEvent `json:"balance,omitempty"`
+}
+
+type OrderEvent struct {
+	Number  string       `json:"number"`
+	Status  string       `json:"status"`
+	Accrual money.Amount `json:"accrual"`
+}
+
+type BalanceEvent struct {
+	Balance money.Amount `json:"balance"`
+	Debited money.Amount `json:"debited"`
+}
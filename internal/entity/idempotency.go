@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+const IdempotencyKeyMaxLen = 255
+
+type IdempotencyKey struct {
+	UserID      uint64    `db:"user_id"`
+	Key         string    `db:"key"`
+	RequestHash string    `db:"request_hash"`
+	StatusCode  int       `db:"status_code"`
+	ContentType string    `db:"content_type"`
+	Body        []byte    `db:"body"`
+	Created     time.Time `db:"created_at"`
+}
+
+// Completed возвращает false, пока исходный запрос еще выполняется.
+func (k IdempotencyKey) Completed() bool {
+	return k.StatusCode != 0
+}
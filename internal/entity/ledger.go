@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+)
+
+const (
+	LedgerKindAccrual    = "ACCRUAL"
+	LedgerKindWithdrawal = "WITHDRAWAL"
+	LedgerKindAdjustment = "ADJUSTMENT"
+	LedgerKindForfeit    = "FORFEIT"
+)
+
+const BalanceAdjustmentMaxReasonLen = 500
+
+// LedgerEntry Reason заполняется только при чтении истории для корректировок.
+type LedgerEntry struct {
+	ID          uint64       `db:"id"`
+	UserID      uint64       `db:"user_id"`
+	Kind        string       `db:"kind"`
+	Amount      money.Amount `db:"amount"`
+	OrderNumber string       `db:"order_num"`
+	Reason      string       `db:"reason"`
+	Created     time.Time    `db:"created_at"`
+}
+
+// LedgerCursor позиция последней показанной записи журнала.
+type LedgerCursor struct {
+	Created time.Time `json:"t"`
+	ID      uint64    `json:"id"`
+}
+
+// LedgerFilter отбор записей журнала пользователя, To не входит в период.
+type LedgerFilter struct {
+	From      time.Time
+	To        time.Time
+	After     *LedgerCursor
+	Ascending bool
+	Limit     int
+}
+
+// BalanceAdjustment ручная корректировка баланса, положительная сумма начисляет баллы.
+type BalanceAdjustment struct {
+	ID         uint64
+	UserID     uint64
+	Amount     money.Amount
+	Reason     string
+	OperatorID uint64
+	Created    time.Time
+}
@@ -0,0 +1,10 @@
+package entity
+
+import "time"
+
+type LoginAttempts struct {
+	Key         string     `db:"key"`
+	Failures    int        `db:"failures"`
+	LockedUntil *time.Time `db:"locked_until"`
+	Updated     time.Time  `db:"updated_at"`
+}
@@ -1,19 +1,86 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+)
 
 const (
 	OrderStatusNew        = "NEW"
 	OrderStatusProcessing = "PROCESSING"
 	OrderStatusInvalid    = "INVALID"
 	OrderStatusProcessed  = "PROCESSED"
+	// OrderStatusFailed заказ исчерпал попытки из-за ошибок системы расчета,
+	// пользователю он показывается как PROCESSING.
+	OrderStatusFailed = "FAILED"
 )
 
+// OrderStatuses статусы, видимые пользователю.
+var OrderStatuses = []string{OrderStatusNew, OrderStatusProcessing, OrderStatusInvalid, OrderStatusProcessed}
+
 type Order struct {
-	Number   string    `db:"number"`
-	UserID   uint64    `db:"user_id"`
-	Status   string    `db:"status"`
-	Accrual  float64   `db:"accrual"`
-	Uploaded time.Time `db:"uploaded_at"`
-	Updated  time.Time `db:"updated_at"`
+	Number   string       `db:"number"`
+	UserID   uint64       `db:"user_id"`
+	Status   string       `db:"status"`
+	Accrual  money.Amount `db:"accrual"`
+	Uploaded time.Time    `db:"uploaded_at"`
+	Updated  time.Time    `db:"updated_at"`
+}
+
+// OrderLease заказы выдаются на обработку экземпляру Owner не больше Limit за раз.
+// Если экземпляр не отчитался за Duration, заказ может забрать другой.
+type OrderLease struct {
+	Owner    string
+	Limit    int
+	Duration time.Duration
+}
+
+// Backoff задержка перед повторным опросом заказа: Base * 2^attempts, но не больше Max,
+// случайно уменьшенная на долю до Jitter, чтобы повторы не шли одной волной.
+type Backoff struct {
+	Base   time.Duration
+	Max    time.Duration
+	Jitter float64
+}
+
+// OrderFailure после Retries неудачных попыток подряд заказ переводится в FinalStatus.
+type OrderFailure struct {
+	Reason      string
+	FinalStatus string
+	Retries     uint64
+}
+
+// OrderDetails заказ со служебными полями обработки для администратора.
+type OrderDetails struct {
+	Order
+	Attempts     int        `db:"attempts"`
+	LastError    *string    `db:"last_error"`
+	NextAttempt  *time.Time `db:"next_attempt_at"`
+	LeaseOwner   *string    `db:"lease_owner"`
+	LeaseExpires *time.Time `db:"lease_expires_at"`
+}
+
+// OrderCursor позиция последнего показанного заказа.
+type OrderCursor struct {
+	Uploaded time.Time `json:"t"`
+	Number   string    `json:"n"`
+}
+
+// OrderFilter отбор заказов пользователя. Нулевые From и To не ограничивают период,
+// To не входит в период.
+type OrderFilter struct {
+	Statuses  []string
+	From      time.Time
+	To        time.Time
+	After     *OrderCursor
+	Ascending bool
+	Limit     int
+}
+
+// AdminOrderFilter отбор заказов всех пользователей, нулевые Number и UserID не ограничивают выборку.
+type AdminOrderFilter struct {
+	OrderFilter
+	Number string
+	UserID uint64
 }
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+)
+
+const (
+	OutboxOrderStatusChanged = "order.status_changed"
+	OutboxBalanceAccrued     = "balance.accrued"
+	OutboxBalanceAdjusted    = "balance.adjusted"
+)
+
+type OutboxEvent struct {
+	ID       uint64          `db:"id"`
+	EventID  string          `db:"event_id"`
+	Type     string          `db:"type"`
+	Payload  json.RawMessage `db:"payload"`
+	Attempts int             `db:"attempts"`
+	Created  time.Time       `db:"created_at"`
+}
+
+type OrderStatusChanged struct {
+	UserID     uint64       `json:"user_id"`
+	Number     string       `json:"number"`
+	Status     string       `json:"status"`
+	PrevStatus string       `json:"prev_status"`
+	Accrual    money.Amount `json:"accrual"`
+}
+
+// BalanceAccrued Balance и Debited баланс пользователя после начисления.
+type BalanceAccrued struct {
+	UserID  uint64       `json:"user_id"`
+	Number  string       `json:"number"`
+	Amount  money.Amount `json:"amount"`
+	Balance money.Amount `json:"balance"`
+	Debited money.Amount `json:"debited"`
+}
+
+// BalanceAdjusted Balance и Debited баланс пользователя после корректировки.
+type BalanceAdjusted struct {
+	UserID       uint64       `json:"user_id"`
+	AdjustmentID uint64       `json:"adjustment_id"`
+	Amount       money.Amount `json:"amount"`
+	Reason       string       `json:"reason"`
+	OperatorID   uint64       `json:"operator_id"`
+	Balance      money.Amount `json:"balance"`
+	Debited      money.Amount `json:"debited"`
+}
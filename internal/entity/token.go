@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// RefreshToken хранится в базе только в виде хеша.
+type RefreshToken struct {
+	ID      uint64     `db:"id"`
+	UserID  uint64     `db:"user_id"`
+	Hash    string     `db:"hash"`
+	Expires time.Time  `db:"expires_at"`
+	Revoked *time.Time `db:"revoked_at"`
+	Created time.Time  `db:"created_at"`
+}
+
+func (t RefreshToken) IsRevoked() bool {
+	return t.Revoked != nil
+}
@@ -2,11 +2,22 @@ package entity
 
 import "time"
 
-const UserMaxLoginLen = 64
+const (
+	UserMaxLoginLen    = 64
+	UserMaxPasswordLen = 256
+)
+
+const (
+	UserRoleUser    = "user"
+	UserRoleSupport = "support"
+	UserRoleAdmin   = "admin"
+)
 
 type User struct {
-	ID      uint64    `db:"id"`
-	Login   string    `db:"login"`
-	Hash    string    `db:"hash"`
-	Created time.Time `db:"created_at"`
+	ID               uint64     `db:"id"`
+	Login            string     `db:"login"`
+	Hash             string     `db:"hash"`
+	Role             string     `db:"role"`
+	Created          time.Time  `db:"created_at"`
+	TokensValidAfter *time.Time `db:"tokens_valid_after"`
 }
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+)
+
+const WebhookEventOrderStatusChanged = "order.status_changed"
+
+type Webhook struct {
+	ID      uint64    `db:"id"`
+	UserID  uint64    `db:"user_id"`
+	URL     string    `db:"url"`
+	Secret  string    `db:"secret"`
+	Created time.Time `db:"created_at"`
+}
+
+// WebhookDelivery событие изменения статуса заказа, ожидающее отправки на один вебхук.
+type WebhookDelivery struct {
+	ID          uint64       `db:"id"`
+	EventID     string       `db:"event_id"`
+	OrderNumber string       `db:"order_num"`
+	Status      string       `db:"status"`
+	Accrual     money.Amount `db:"accrual"`
+	Attempts    int          `db:"attempts"`
+	Created     time.Time    `db:"created_at"`
+	URL         string       `db:"url"`
+	Secret      string       `db:"secret"`
+}
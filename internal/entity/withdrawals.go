@@ -1,11 +1,33 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+)
 
 type Withdrawals struct {
-	ID          uint64    `db:"id"`
-	UserID      uint64    `db:"user_id"`
-	OrderNumber string    `db:"order_num"`
-	Sum         float64   `db:"sum"`
-	Processed   time.Time `db:"processed_at"`
+	ID          uint64       `db:"id"`
+	UserID      uint64       `db:"user_id"`
+	OrderNumber string       `db:"order_num"`
+	Sum         money.Amount `db:"sum"`
+	Processed   time.Time    `db:"processed_at"`
+}
+
+// WithdrawalsCursor позиция последнего показанного списания.
+type WithdrawalsCursor struct {
+	Processed time.Time `json:"t"`
+	ID        uint64    `json:"id"`
+}
+
+// WithdrawalsFilter отбор списаний пользователя. Нулевые From и To не ограничивают период,
+// To не входит в период.
+type WithdrawalsFilter struct {
+	From      time.Time
+	To        time.Time
+	MinSum    *money.Amount
+	MaxSum    *money.Amount
+	After     *WithdrawalsCursor
+	Ascending bool
+	Limit     int
 }
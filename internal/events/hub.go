@@ -0,0 +1,139 @@
+// Package events раздает подписчикам изменения заказов и баланса, полученные через LISTEN/NOTIFY,
+// поэтому подписчик узнает о событии независимо от того, какая реплика его породила.
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+)
+
+const (
+	subscriberBuffer = 16
+	reconnectDelay   = 5 * time.Second
+)
+
+type Repository interface {
+	Listen(ctx context.Context, handle func(entity.UserEvent)) error
+}
+
+type Logger interface {
+	Error(message string, err error, fields ...logger.Field)
+}
+
+type subscriber chan entity.UserEvent
+
+type Hub struct {
+	repository  Repository
+	logger      Logger
+	mu          sync.Mutex
+	subscribers map[uint64]map[subscriber]struct{}
+	cancel      context.CancelFunc
+	stopped     chan struct{}
+}
+
+func NewHub(r Repository, l Logger) *Hub {
+	return &Hub{
+		repository:  r,
+		logger:      l,
+		subscribers: make(map[uint64]map[subscriber]struct{}),
+	}
+}
+
+func (h *Hub) Run(ctx context.Context) {
+	ctx, h.cancel = context.WithCancel(ctx)
+	h.stopped = make(chan struct{})
+
+	go func() {
+		defer close(h.stopped)
+		defer h.closeAll()
+
+		for {
+			err := h.repository.Listen(ctx, h.publish)
+			if ctx.Err() != nil {
+				return
+			}
+			h.logger.Error("failed to listen user events", err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reconnectDelay):
+			}
+		}
+	}()
+
+	log.Println("user events hub started")
+}
+
+// Stop закрывает каналы всех подписчиков.
+func (h *Hub) Stop() {
+	h.cancel()
+	<-h.stopped
+	log.Println("user events hub stopped")
+}
+
+// Subscribe возвращает канал событий пользователя и функцию отписки.
+// Канал закрывается, если подписчик не успевает читать события,
+// тогда клиенту нужно переподключиться и заново запросить данные.
+func (h *Hub) Subscribe(userID uint64) (<-chan entity.UserEvent, func()) {
+	sub := make(subscriber, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[subscriber]struct{})
+	}
+	h.subscribers[userID][sub] = struct{}{}
+	h.mu.Unlock()
+
+	return sub, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(userID, sub)
+	}
+}
+
+func (h *Hub) publish(event entity.UserEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subscribers[event.UserID] {
+		select {
+		case sub <- event:
+		default:
+			h.remove(event.UserID, sub)
+		}
+	}
+}
+
+// remove вызывается под h.mu, канал закрывается только при удалении из подписчиков.
+func (h *Hub) remove(userID uint64, sub subscriber) {
+	subs, ok := h.subscribers[userID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subscribers, userID)
+	}
+	close(sub)
+}
+
+func (h *Hub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for userID, subs := range h.subscribers {
+		for sub := range subs {
+			h.remove(userID, sub)
+		}
+	}
+}
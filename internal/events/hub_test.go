@@ -0,0 +1,101 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/events/mocks"
+)
+
+func testOrderEvent(userID uint64) entity.UserEvent {
+	return entity.UserEvent{
+		UserID: userID,
+		Type:   entity.UserEventOrder,
+		Order:  &entity.OrderEvent{Number: "5062821234567892", Status: entity.OrderStatusProcessing},
+	}
+}
+
+func TestHub_publish(t *testing.T) {
+	hub := NewHub(nil, nil)
+
+	first, unsubscribeFirst := hub.Subscribe(13)
+	defer unsubscribeFirst()
+	second, unsubscribeSecond := hub.Subscribe(13)
+	defer unsubscribeSecond()
+	other, unsubscribeOther := hub.Subscribe(14)
+	defer unsubscribeOther()
+
+	hub.publish(testOrderEvent(13))
+
+	assert.Equal(t, testOrderEvent(13), <-first, "First subscriber event")
+	assert.Equal(t, testOrderEvent(13), <-second, "Second subscriber event")
+	assert.Len(t, other, 0, "Other user has no events")
+}
+
+func TestHub_publish_slowSubscriber(t *testing.T) {
+	hub := NewHub(nil, nil)
+	events, unsubscribe := hub.Subscribe(13)
+	defer unsubscribe()
+
+	for range subscriberBuffer + 1 {
+		hub.publish(testOrderEvent(13))
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+	assert.Equal(t, subscriberBuffer, received, "Buffered events before disconnect")
+	assert.Empty(t, hub.subscribers, "Slow subscriber removed")
+}
+
+func TestHub_Subscribe_unsubscribe(t *testing.T) {
+	hub := NewHub(nil, nil)
+	events, unsubscribe := hub.Subscribe(13)
+
+	unsubscribe()
+	unsubscribe()
+
+	_, ok := <-events
+	assert.False(t, ok, "Channel closed")
+	assert.Empty(t, hub.subscribers, "Subscriber removed")
+}
+
+func TestHub_Run(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repository := mocks.NewMockRepository(ctrl)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	hub := NewHub(repository, logger)
+	events, unsubscribe := hub.Subscribe(13)
+	defer unsubscribe()
+
+	repository.EXPECT().
+		Listen(gomock.All(), gomock.All()).
+		DoAndReturn(func(ctx context.Context, handle func(entity.UserEvent)) error {
+			handle(testOrderEvent(13))
+			<-ctx.Done()
+			return fmt.Errorf("any error: %w", ctx.Err())
+		})
+
+	hub.Run(context.Background())
+
+	select {
+	case event := <-events:
+		assert.Equal(t, testOrderEvent(13), event, "Event from repository")
+	case <-time.After(time.Second):
+		require.Fail(t, "event not received")
+	}
+
+	hub.Stop()
+	_, ok := <-events
+	assert.False(t, ok, "Channel closed on stop")
+}
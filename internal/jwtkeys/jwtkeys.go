@@ -0,0 +1,273 @@
+// Package jwtkeys хранит ключи для подписи и проверки JWT.
+// Токены подписываются одним ключом, а проверяются любым ключом из набора,
+// что позволяет менять ключ подписи, не инвалидируя уже выданные токены.
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const minRSABits = 2048
+
+var (
+	ErrInvalidPEM       = errors.New("invalid PEM data")
+	ErrUnsupportedKey   = errors.New("unsupported key type, only RSA and Ed25519 are allowed")
+	ErrWeakKey          = errors.New("RSA key must be at least 2048 bits")
+	ErrNoPrivateKey     = errors.New("signing key must be a private key")
+	ErrUnknownKeyID     = errors.New("unknown key id")
+	ErrUnexpectedMethod = errors.New("unexpected signing method")
+)
+
+type Key struct {
+	ID      string
+	method  jwt.SigningMethod
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// JWK публичный ключ в формате RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv,omitempty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// ParsePEM разбирает приватный (PKCS#8, PKCS#1) или публичный (PKIX) ключ.
+// Из публичного ключа получается ключ только для проверки подписи.
+func ParsePEM(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+
+	var (
+		parsed any
+		err    error
+	)
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: unexpected block type %q", ErrInvalidPEM, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPEM, err)
+	}
+
+	return newKey(parsed)
+}
+
+func LoadFile(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	key, err := ParsePEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key file %s: %w", path, err)
+	}
+
+	return key, nil
+}
+
+// Load загружает ключ подписи и ключи проверки из файлов.
+// Если путь к ключу подписи пустой, создается эфемерный ключ.
+func Load(signingPath string, verifyPaths []string) (*KeySet, error) {
+	var (
+		signing *Key
+		err     error
+	)
+	if signingPath == "" {
+		signing, err = Generate()
+	} else {
+		signing, err = LoadFile(signingPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	verify := make([]*Key, 0, len(verifyPaths))
+	for _, path := range verifyPaths {
+		key, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		verify = append(verify, key)
+	}
+
+	return NewKeySet(signing, verify...)
+}
+
+// Generate создает эфемерный Ed25519 ключ, который живет до перезапуска.
+func Generate() (*Key, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	return newKey(private)
+}
+
+func newKey(parsed any) (*Key, error) {
+	key := &Key{}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.private, key.public = k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.public = k
+	case ed25519.PrivateKey:
+		key.private, key.public = k, k.Public()
+	case ed25519.PublicKey:
+		key.public = k
+	default:
+		return nil, ErrUnsupportedKey
+	}
+
+	switch pub := key.public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSABits {
+			return nil, ErrWeakKey
+		}
+		key.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.method = jwt.SigningMethodEdDSA
+	}
+
+	key.ID = thumbprint(key.JWK())
+
+	return key, nil
+}
+
+func (k *Key) JWK() JWK {
+	jwk := JWK{Alg: k.method.Alg(), Use: "sig", Kid: k.ID}
+
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+
+	return jwk
+}
+
+// thumbprint вычисляет идентификатор ключа по RFC 7638:
+// SHA-256 от обязательных полей JWK, упорядоченных по имени.
+func thumbprint(jwk JWK) string {
+	var members any
+	if jwk.Kty == "RSA" {
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	} else {
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+
+	// Маршалинг структуры из строк не может завершиться ошибкой.
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+	ordered []*Key
+	methods []string
+}
+
+// NewKeySet создает набор из ключа подписи и дополнительных ключей проверки,
+// например ключей, которыми подписывались токены до ротации.
+func NewKeySet(signing *Key, verify ...*Key) (*KeySet, error) {
+	if signing.private == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	ks := &KeySet{signing: signing, keys: make(map[string]*Key)}
+	for _, k := range append([]*Key{signing}, verify...) {
+		if _, ok := ks.keys[k.ID]; ok {
+			continue
+		}
+		ks.keys[k.ID] = k
+		ks.ordered = append(ks.ordered, k)
+
+		if alg := k.method.Alg(); !slices.Contains(ks.methods, alg) {
+			ks.methods = append(ks.methods, alg)
+		}
+	}
+
+	return ks, nil
+}
+
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.method, claims)
+	token.Header["kid"] = ks.signing.ID
+
+	return token.SignedString(ks.signing.private)
+}
+
+// Keyfunc выбирает ключ проверки по заголовку kid.
+func (ks *KeySet) Keyfunc(t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+
+	if t.Method.Alg() != key.method.Alg() {
+		return nil, ErrUnexpectedMethod
+	}
+
+	return key.public, nil
+}
+
+// Methods возвращает алгоритмы всех ключей набора для jwt.WithValidMethods.
+func (ks *KeySet) Methods() []string {
+	return ks.methods
+}
+
+func (ks *KeySet) JWKS() []JWK {
+	jwks := make([]JWK, 0, len(ks.ordered))
+	for _, k := range ks.ordered {
+		jwks = append(jwks, k.JWK())
+	}
+
+	return jwks
+}
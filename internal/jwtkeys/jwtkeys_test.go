@@ -0,0 +1,192 @@
+package jwtkeys
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePEM(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err, "Generate RSA key")
+	weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.Nil(t, err, "Generate weak RSA key")
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.Nil(t, err, "Generate Ed25519 key")
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err, "Generate ECDSA key")
+
+	tests := []struct {
+		name    string
+		data    []byte
+		alg     string
+		canSign bool
+		wantErr error
+	}{
+		{
+			name:    "rsa_pkcs8",
+			data:    testPKCS8(t, rsaKey),
+			alg:     "RS256",
+			canSign: true,
+		},
+		{
+			name: "rsa_pkcs1",
+			data: pem.EncodeToMemory(&pem.Block{
+				Type:  "RSA PRIVATE KEY",
+				Bytes: x509.MarshalPKCS1PrivateKey(rsaKey),
+			}),
+			alg:     "RS256",
+			canSign: true,
+		},
+		{
+			name: "rsa_public",
+			data: testPKIX(t, &rsaKey.PublicKey),
+			alg:  "RS256",
+		},
+		{
+			name:    "ed25519_pkcs8",
+			data:    testPKCS8(t, edPrivate),
+			alg:     "EdDSA",
+			canSign: true,
+		},
+		{
+			name: "ed25519_public",
+			data: testPKIX(t, edPublic),
+			alg:  "EdDSA",
+		},
+		{
+			name:    "negative_weak_rsa",
+			data:    testPKCS8(t, weakKey),
+			wantErr: ErrWeakKey,
+		},
+		{
+			name:    "negative_ecdsa",
+			data:    testPKCS8(t, ecKey),
+			wantErr: ErrUnsupportedKey,
+		},
+		{
+			name:    "negative_not_pem",
+			data:    []byte("not a key"),
+			wantErr: ErrInvalidPEM,
+		},
+		{
+			name:    "negative_certificate",
+			data:    pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{1}}),
+			wantErr: ErrInvalidPEM,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, err := ParsePEM(test.data)
+			assert.ErrorIs(t, err, test.wantErr, "Parse error")
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, test.alg, key.method.Alg(), "Signing method")
+			assert.Equal(t, test.canSign, key.private != nil, "Has private key")
+			assert.NotEmpty(t, key.ID, "Key id")
+		})
+	}
+}
+
+func TestKey_ID(t *testing.T) {
+	// Пример из RFC 8037, приложение A.3.
+	x, err := base64.RawURLEncoding.DecodeString("11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo")
+	require.Nil(t, err, "Decode public key")
+
+	key, err := newKey(ed25519.PublicKey(x))
+	require.Nil(t, err, "Create key")
+	assert.Equal(t, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", key.ID, "RFC 7638 thumbprint")
+
+	jwk := key.JWK()
+	assert.Equal(t, "OKP", jwk.Kty, "Key type")
+	assert.Equal(t, "Ed25519", jwk.Crv, "Curve")
+	assert.Equal(t, key.ID, jwk.Kid, "Key id")
+}
+
+func TestKeySet(t *testing.T) {
+	oldKey, err := Generate()
+	require.Nil(t, err, "Generate old key")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err, "Generate RSA key")
+	currentKey, err := ParsePEM(testPKCS8(t, rsaKey))
+	require.Nil(t, err, "Parse new key")
+	strangerKey, err := Generate()
+	require.Nil(t, err, "Generate stranger key")
+
+	oldSet, err := NewKeySet(oldKey)
+	require.Nil(t, err, "Old key set")
+	rotatedSet, err := NewKeySet(currentKey, oldKey)
+	require.Nil(t, err, "Rotated key set")
+	strangerSet, err := NewKeySet(strangerKey)
+	require.Nil(t, err, "Stranger key set")
+
+	claims := jwt.RegisteredClaims{
+		Subject:   "13",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+	oldToken, err := oldSet.Sign(claims)
+	require.Nil(t, err, "Sign with old key")
+	newToken, err := rotatedSet.Sign(claims)
+	require.Nil(t, err, "Sign with new key")
+	strangerToken, err := strangerSet.Sign(claims)
+	require.Nil(t, err, "Sign with stranger key")
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "new_key", token: newToken},
+		{name: "old_key_after_rotation", token: oldToken},
+		{name: "negative_unknown_key", token: strangerToken, wantErr: ErrUnknownKeyID},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := jwt.Parse(test.token, rotatedSet.Keyfunc, jwt.WithValidMethods(rotatedSet.Methods()))
+			assert.ErrorIs(t, err, test.wantErr, "Verify error")
+		})
+	}
+
+	jt, _, err := jwt.NewParser().ParseUnverified(newToken, &jwt.RegisteredClaims{})
+	require.Nil(t, err, "Parse header")
+	assert.Equal(t, currentKey.ID, jt.Header["kid"], "Token kid header")
+	assert.Equal(t, "RS256", jt.Method.Alg(), "Token algorithm")
+
+	assert.ElementsMatch(t, []string{"RS256", "EdDSA"}, rotatedSet.Methods(), "Valid methods")
+	jwks := rotatedSet.JWKS()
+	require.Len(t, jwks, 2, "Published keys")
+	assert.Equal(t, currentKey.ID, jwks[0].Kid, "Signing key is published first")
+	assert.Equal(t, oldKey.ID, jwks[1].Kid, "Verification key is published")
+
+	publicOnly, err := ParsePEM(testPKIX(t, &rsaKey.PublicKey))
+	require.Nil(t, err, "Parse public key")
+	_, err = NewKeySet(publicOnly)
+	assert.ErrorIs(t, err, ErrNoPrivateKey, "Public key can not sign")
+}
+
+func testPKCS8(t *testing.T, key any) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.Nil(t, err, "Marshal private key")
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func testPKIX(t *testing.T, key any) []byte {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.Nil(t, err, "Marshal public key")
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
@@ -1,14 +1,42 @@
 package logger
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"os"
 	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/requestid"
+)
+
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
 )
 
+// Config File пустой — вывод в stderr, иначе файл с ротацией по MaxSize мегабайт,
+// хранятся MaxBackups архивов не старше MaxAge дней.
+// RequestSampling первые N логов запросов в секунду пишутся, дальше каждый N-й.
+type Config struct {
+	Env             string
+	Level           string
+	File            string
+	MaxSize         uint64
+	MaxBackups      uint64
+	MaxAge          uint64
+	RequestSampling uint64
+}
+
+type Field = zap.Field
+
 type Logger struct {
-	logger *zap.Logger
+	logger   *zap.Logger
+	requests *zap.Logger
 }
 
 type RequestLogData struct {
@@ -22,30 +50,120 @@ type ResponseLogData struct {
 	Size   int
 }
 
-// В дальнейшем здесь будет конфигурация зависящая от окружения
-func New() (*Logger, error) {
-	l, err := zap.NewDevelopment(zap.AddCallerSkip(1))
+type EventLogData struct {
+	ID      string
+	Type    string
+	Payload string
+}
+
+func New(cfg Config) (*Logger, error) {
+	level, err := zapcore.ParseLevel(cfg.Level)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Logger{logger: l}, nil
+	var (
+		encoder zapcore.Encoder
+		options = []zap.Option{zap.AddCaller(), zap.AddCallerSkip(1)}
+	)
+	if cfg.Env == EnvProduction {
+		encCfg := zap.NewProductionEncoderConfig()
+		encCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+		encoder = zapcore.NewJSONEncoder(encCfg)
+		options = append(options, zap.AddStacktrace(zapcore.ErrorLevel))
+	} else {
+		encCfg := zap.NewDevelopmentEncoderConfig()
+		encCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encCfg)
+		options = append(options, zap.Development(), zap.AddStacktrace(zapcore.WarnLevel))
+	}
+
+	core := zapcore.NewCore(encoder, zapcore.AddSync(output(cfg)), level)
+	sampled := zapcore.NewSamplerWithOptions(
+		core,
+		time.Second,
+		int(cfg.RequestSampling),
+		int(cfg.RequestSampling),
+	)
+
+	return &Logger{
+		logger:   zap.New(core, options...),
+		requests: zap.New(sampled, options...),
+	}, nil
+}
+
+func output(cfg Config) io.Writer {
+	if cfg.File == "" {
+		return os.Stderr
+	}
+
+	return &lumberjack.Logger{
+		Filename:   cfg.File,
+		MaxSize:    int(cfg.MaxSize),
+		MaxBackups: int(cfg.MaxBackups),
+		MaxAge:     int(cfg.MaxAge),
+	}
+}
+
+func OrderNumber(number string) Field {
+	return zap.String("order", number)
+}
+
+func UserID(id uint64) Field {
+	return zap.Uint64("user_id", id)
+}
+
+// RequestID пустой идентификатор в лог не попадает.
+func RequestID(id string) Field {
+	if id == "" {
+		return zap.Skip()
+	}
+	return zap.String("request_id", id)
+}
+
+// Request идентификатор запроса из контекста.
+func Request(ctx context.Context) Field {
+	return RequestID(requestid.FromContext(ctx))
+}
+
+// With возвращает логгер, добавляющий поля ко всем записям.
+func (l *Logger) With(fields ...Field) *Logger {
+	return &Logger{logger: l.logger.With(fields...), requests: l.requests.With(fields...)}
 }
 
 func (l *Logger) Sync() {
 	l.logger.Sync()
 }
 
-func (l *Logger) Error(message string, err error) {
-	l.logger.Error(message, zap.Error(err))
+func (l *Logger) Error(message string, err error, fields ...Field) {
+	l.logger.Error(message, append(fields, zap.Error(err))...)
+}
+
+func (l *Logger) Warn(message string, err error, fields ...Field) {
+	l.logger.Warn(message, append(fields, zap.Error(err))...)
 }
 
-func (l *Logger) Warn(message string, err error) {
-	l.logger.Warn(message, zap.Error(err))
+func (l *Logger) Info(message string, fields ...Field) {
+	l.logger.Info(message, fields...)
 }
 
-func (l *Logger) RequestInfo(message string, req *RequestLogData, resp *ResponseLogData) {
-	l.logger.Info(message, zap.Object("request", req), zap.Object("response", resp))
+func (l *Logger) Debug(message string, fields ...Field) {
+	l.logger.Debug(message, fields...)
+}
+
+// RequestInfo пишет через сэмплер, чтобы поток запросов не вытеснял остальные логи.
+// Ответы 5xx и 429 пишутся всегда: именно они нужны при разборе сбоев.
+func (l *Logger) RequestInfo(message string, req *RequestLogData, resp *ResponseLogData, fields ...Field) {
+	fields = append(fields, zap.Object("request", req), zap.Object("response", resp))
+	if resp.Status >= http.StatusInternalServerError || resp.Status == http.StatusTooManyRequests {
+		l.logger.Info(message, fields...)
+		return
+	}
+	l.requests.Info(message, fields...)
+}
+
+func (l *Logger) EventInfo(message string, event *EventLogData) {
+	l.logger.Info(message, zap.Object("event", event))
 }
 
 func (o *RequestLogData) MarshalLogObject(enc zapcore.ObjectEncoder) error {
@@ -60,3 +178,10 @@ func (o *ResponseLogData) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 	enc.AddInt("size", o.Size)
 	return nil
 }
+
+func (o *EventLogData) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("id", o.ID)
+	enc.AddString("type", o.Type)
+	enc.AddString("payload", o.Payload)
+	return nil
+}
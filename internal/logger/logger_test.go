@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_production(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "gophermart.log")
+	l, err := New(Config{Env: EnvProduction, Level: "warn", File: file, MaxSize: 1, MaxBackups: 1, MaxAge: 1, RequestSampling: 2})
+	require.NoError(t, err)
+
+	l.Info("skipped by level")
+	l.Error("failed to upload order", errors.New("boom"), OrderNumber("5062821234567892"), UserID(13))
+	l.Sync()
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 1, "Log lines")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry), "JSON log entry")
+	assert.Equal(t, "error", entry["level"], "Level")
+	assert.Equal(t, "failed to upload order", entry["msg"], "Message")
+	assert.Equal(t, "boom", entry["error"], "Error field")
+	assert.Equal(t, "5062821234567892", entry["order"], "Order field")
+	assert.Equal(t, float64(13), entry["user_id"], "User id field")
+}
+
+func TestLogger_RequestInfo_sampling(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "gophermart.log")
+	l, err := New(Config{Env: EnvProduction, Level: "info", File: file, MaxSize: 1, MaxBackups: 1, MaxAge: 1, RequestSampling: 2})
+	require.NoError(t, err)
+
+	// Первые 2 записи в секунду пишутся, дальше каждая 2-я: 1, 2, 4.
+	for range 5 {
+		l.RequestInfo("server api", &RequestLogData{URI: "/api/user/orders"}, &ResponseLogData{Status: 200})
+	}
+	l.Sync()
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(content)), "\n"), 3, "Sampled request logs")
+}
+
+func TestLogger_RequestInfo_errorsNotSampled(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "gophermart.log")
+	l, err := New(Config{Env: EnvProduction, Level: "info", File: file, MaxSize: 1, MaxBackups: 1, MaxAge: 1, RequestSampling: 2})
+	require.NoError(t, err)
+
+	for _, status := range []int{200, 200, 200, 500, 503, 429} {
+		l.RequestInfo("server api", &RequestLogData{URI: "/api/user/orders"}, &ResponseLogData{Status: status})
+	}
+	l.Sync()
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	// Третий ответ 200 отбрасывает сэмплер, ошибки пишутся все.
+	require.Len(t, lines, 5, "Sampled request logs and all error logs")
+
+	for i, want := range []float64{200, 200, 500, 503, 429} {
+		var entry struct {
+			Response struct {
+				Status float64 `json:"status"`
+			} `json:"response"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(lines[i]), &entry), "JSON log entry")
+		assert.Equal(t, want, entry.Response.Status, "Response status")
+	}
+}
+
+func TestNew_invalidLevel(t *testing.T) {
+	_, err := New(Config{Env: EnvDevelopment, Level: "verbose", RequestSampling: 1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Сбор по таблице orders не должен задерживать ответ /metrics.
+const ordersScrapeTimeout = 2 * time.Second
+
+type OrderCounter interface {
+	CountByStatus(ctx context.Context) (map[string]int64, error)
+}
+
+// RegisterQueue публикует число заказов, ожидающих обработчиков очереди.
+func RegisterQueue(depth func() int) {
+	registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "accrual",
+		Name:      "queue_depth",
+		Help:      "Orders waiting in the accrual processor queue.",
+	}, func() float64 {
+		return float64(depth())
+	}))
+}
+
+// RegisterOrders публикует число заказов по статусам, заказы считаются при каждом сборе.
+func RegisterOrders(c OrderCounter) {
+	registry.MustRegister(&ordersCollector{
+		counter: c,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "orders"),
+			"Orders by status including FAILED.",
+			[]string{"status"},
+			nil,
+		),
+	})
+}
+
+type ordersCollector struct {
+	counter OrderCounter
+	desc    *prometheus.Desc
+}
+
+func (c *ordersCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *ordersCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), ordersScrapeTimeout)
+	defer cancel()
+
+	counts, err := c.counter.CountByStatus(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+
+	for status, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), status)
+	}
+}
+
+// RegisterDBPool публикует статистику пула соединений с БД.
+func RegisterDBPool(pool *pgxpool.Pool) {
+	registry.MustRegister(newPoolCollector(pool))
+}
+
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns     *prometheus.Desc
+	idleConns         *prometheus.Desc
+	constructingConns *prometheus.Desc
+	totalConns        *prometheus.Desc
+	maxConns          *prometheus.Desc
+	acquires          *prometheus.Desc
+	acquireSeconds    *prometheus.Desc
+	emptyAcquires     *prometheus.Desc
+	canceledAcquires  *prometheus.Desc
+	newConns          *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool) *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+
+	return &poolCollector{
+		pool:              pool,
+		acquiredConns:     desc("acquired_conns", "Connections currently acquired from the pool."),
+		idleConns:         desc("idle_conns", "Idle connections in the pool."),
+		constructingConns: desc("constructing_conns", "Connections being established."),
+		totalConns:        desc("total_conns", "All connections of the pool."),
+		maxConns:          desc("max_conns", "Maximum size of the pool."),
+		acquires:          desc("acquires_total", "Successful connection acquires."),
+		acquireSeconds:    desc("acquire_seconds_total", "Total time spent acquiring connections."),
+		emptyAcquires:     desc("empty_acquires_total", "Acquires that waited for a free connection."),
+		canceledAcquires:  desc("canceled_acquires_total", "Acquires canceled by context."),
+		newConns:          desc("new_conns_total", "Connections opened by the pool."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+
+	gauge := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v)
+	}
+	counter := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v)
+	}
+
+	gauge(c.acquiredConns, float64(s.AcquiredConns()))
+	gauge(c.idleConns, float64(s.IdleConns()))
+	gauge(c.constructingConns, float64(s.ConstructingConns()))
+	gauge(c.totalConns, float64(s.TotalConns()))
+	gauge(c.maxConns, float64(s.MaxConns()))
+	counter(c.acquires, float64(s.AcquireCount()))
+	counter(c.acquireSeconds, s.AcquireDuration().Seconds())
+	counter(c.emptyAcquires, float64(s.EmptyAcquireCount()))
+	counter(c.canceledAcquires, float64(s.CanceledAcquireCount()))
+	counter(c.newConns, float64(s.NewConnsCount()))
+}
@@ -0,0 +1,125 @@
+// Package metrics собирает метрики сервиса для Prometheus.
+// Метрики регистрируются в собственном реестре и отдаются Handler.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "gophermart"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by route, method and response status code.",
+	}, []string{"method", "route", "code"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	accrualResponses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "accrual",
+		Name:      "responses_total",
+		Help:      "Accrual system responses by status code, error when no response was received.",
+	}, []string{"code"})
+
+	accrualThrottled = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "accrual",
+		Name:      "throttled_total",
+		Help:      "429 responses of the accrual system that paused the requests.",
+	})
+
+	accrualThrottledSeconds = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "accrual",
+		Name:      "throttled_seconds_total",
+		Help:      "Total pause requested by the accrual system in Retry-After.",
+	})
+
+	pointsAccrued = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "loyalty",
+		Name:      "points_accrued_total",
+		Help:      "Points accrued for processed orders.",
+	})
+
+	pointsWithdrawn = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "loyalty",
+		Name:      "points_withdrawn_total",
+		Help:      "Points withdrawn to pay for orders.",
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		accrualResponses,
+		accrualThrottled,
+		accrualThrottledSeconds,
+		pointsAccrued,
+		pointsWithdrawn,
+	)
+}
+
+func Handler() http.Handler {
+	// Ошибка подсчета заказов не должна скрывать остальные метрики.
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		Registry:      registry,
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+// ObserveHTTPRequest route шаблон маршрута chi, а не URI, чтобы число серий не зависело от параметров.
+func ObserveHTTPRequest(method, route string, code int, d time.Duration) {
+	if route == "" {
+		route = "unmatched"
+	}
+
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(code)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(d.Seconds())
+}
+
+// AccrualResponse code 0 означает, что ответ не получен.
+func AccrualResponse(code int) {
+	label := "error"
+	if code != 0 {
+		label = strconv.Itoa(code)
+	}
+
+	accrualResponses.WithLabelValues(label).Inc()
+}
+
+func AccrualThrottled(pause time.Duration) {
+	accrualThrottled.Inc()
+	accrualThrottledSeconds.Add(pause.Seconds())
+}
+
+// PointsAccrued и PointsWithdrawn принимают сумму в баллах.
+func PointsAccrued(points float64) {
+	pointsAccrued.Add(points)
+}
+
+func PointsWithdrawn(points float64) {
+	pointsWithdrawn.Add(points)
+}
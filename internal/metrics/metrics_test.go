@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderCounterMock struct {
+	counts map[string]int64
+	err    error
+}
+
+func (m orderCounterMock) CountByStatus(context.Context) (map[string]int64, error) {
+	return m.counts, m.err
+}
+
+func TestObserveHTTPRequest(t *testing.T) {
+	ObserveHTTPRequest(http.MethodGet, "/api/user/orders", http.StatusOK, 30*time.Millisecond)
+	ObserveHTTPRequest(http.MethodGet, "", http.StatusNotFound, time.Millisecond)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/api/user/orders", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(httpRequests.WithLabelValues("GET", "unmatched", "404")))
+}
+
+func TestAccrualResponse(t *testing.T) {
+	AccrualResponse(0)
+	AccrualResponse(http.StatusTooManyRequests)
+	AccrualThrottled(13 * time.Second)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(accrualResponses.WithLabelValues("error")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(accrualResponses.WithLabelValues("429")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(accrualThrottled))
+	assert.Equal(t, 13.0, testutil.ToFloat64(accrualThrottledSeconds))
+}
+
+func TestOrdersCollector(t *testing.T) {
+	tests := []struct {
+		name    string
+		counter orderCounterMock
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "success",
+			counter: orderCounterMock{counts: map[string]int64{"NEW": 3, "FAILED": 1}},
+			want: `
+# HELP gophermart_orders Orders by status including FAILED.
+# TYPE gophermart_orders gauge
+gophermart_orders{status="FAILED"} 1
+gophermart_orders{status="NEW"} 3
+`,
+		},
+		{
+			name:    "negative_db_error",
+			counter: orderCounterMock{err: errors.New("any error")},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reg := prometheus.NewPedanticRegistry()
+			reg.MustRegister(&ordersCollector{
+				counter: test.counter,
+				desc: prometheus.NewDesc(
+					"gophermart_orders",
+					"Orders by status including FAILED.",
+					[]string{"status"},
+					nil,
+				),
+			})
+
+			err := testutil.GatherAndCompare(reg, strings.NewReader(test.want), "gophermart_orders")
+			if test.wantErr {
+				assert.NotNil(t, err, "Gather error")
+			} else {
+				assert.Nil(t, err, "Gather orders")
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	PointsAccrued(729.98)
+
+	r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, r)
+	res := w.Result()
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode, "Response status code")
+	body, err := io.ReadAll(res.Body)
+	require.Nil(t, err, "Read body")
+	assert.Contains(t, string(body), "gophermart_loyalty_points_accrued_total 729.98", "Accrued points")
+	assert.Contains(t, string(body), "go_goroutines", "Go runtime metrics")
+}
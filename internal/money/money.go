@@ -0,0 +1,179 @@
+// Package money реализует денежные суммы с фиксированной точкой.
+// Суммы хранятся в сотых долях балла, что соответствует NUMERIC(10, 2) в БД,
+// поэтому ошибки округления float64 не попадают в балансы.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	scale      = 2
+	centsInOne = 100
+)
+
+var (
+	ErrInvalidFormat = errors.New("invalid money format")
+	ErrTooPrecise    = errors.New("money amount has more than two fractional digits")
+	ErrOutOfRange    = errors.New("money amount out of range")
+)
+
+// Amount сумма в сотых долях балла.
+type Amount int64
+
+// New создает сумму из целой и дробной (в сотых) частей.
+func New(units int64, cents int64) Amount {
+	return Amount(units*centsInOne + cents)
+}
+
+// Parse разбирает десятичную запись суммы, например "599.99" или "1e2".
+// Возвращает ErrTooPrecise, если в записи больше двух знаков после запятой.
+func Parse(s string) (Amount, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidFormat, s)
+	}
+
+	return fromRat(r)
+}
+
+// ParseRound разбирает сумму как Parse, но лишние знаки после запятой округляет
+// до сотых, половина — от нуля. Только для сумм из внешних систем, не для ввода пользователя.
+func ParseRound(s string) (Amount, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidFormat, s)
+	}
+
+	r.Mul(r, big.NewRat(centsInOne, 1))
+	num := new(big.Int).Abs(r.Num())
+	cents, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if rem.Lsh(rem, 1).Cmp(r.Denom()) >= 0 {
+		cents.Add(cents, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		cents.Neg(cents)
+	}
+
+	return fromRat(new(big.Rat).SetFrac(cents, big.NewInt(centsInOne)))
+}
+
+func fromRat(r *big.Rat) (Amount, error) {
+	r.Mul(r, big.NewRat(centsInOne, 1))
+	if !r.IsInt() {
+		return 0, ErrTooPrecise
+	}
+
+	cents := r.Num()
+	if !cents.IsInt64() {
+		return 0, ErrOutOfRange
+	}
+
+	return Amount(cents.Int64()), nil
+}
+
+func (a Amount) Add(b Amount) Amount {
+	return a + b
+}
+
+func (a Amount) Sub(b Amount) Amount {
+	return a - b
+}
+
+// Float64 сумма в баллах, только для метрик и отчетов, не для расчетов.
+func (a Amount) Float64() float64 {
+	return float64(a) / centsInOne
+}
+
+// String возвращает запись с двумя знаками после запятой: "400.00".
+func (a Amount) String() string {
+	sign := ""
+	v := int64(a)
+	if v < 0 {
+		sign = "-"
+	}
+	abs := absUint(v)
+
+	return fmt.Sprintf("%s%d.%02d", sign, abs/centsInOne, abs%centsInOne)
+}
+
+// MarshalJSON записывает сумму числом без незначащих нулей: 400, 599.9, 599.99.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	s := a.String()
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || s == "-" {
+		s = "0"
+	}
+
+	return []byte(s), nil
+}
+
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+
+	// Суммы принимаются только числами, как и в float64
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidFormat, s)
+	}
+
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+
+	*a = v
+	return nil
+}
+
+// ScanNumeric реализует pgtype.NumericScanner.
+func (a *Amount) ScanNumeric(n pgtype.Numeric) error {
+	if !n.Valid {
+		*a = 0
+		return nil
+	}
+	if n.NaN || n.InfinityModifier != pgtype.Finite {
+		return fmt.Errorf("%w: not a finite number", ErrInvalidFormat)
+	}
+
+	r := new(big.Rat).SetInt(n.Int)
+	exp := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absUint(int64(n.Exp)))), nil)
+	if n.Exp < 0 {
+		r.Quo(r, new(big.Rat).SetInt(exp))
+	} else {
+		r.Mul(r, new(big.Rat).SetInt(exp))
+	}
+
+	v, err := fromRat(r)
+	if err != nil {
+		return err
+	}
+
+	*a = v
+	return nil
+}
+
+// NumericValue реализует pgtype.NumericValuer.
+func (a Amount) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(int64(a)), Exp: -scale, Valid: true}, nil
+}
+
+func absUint(v int64) uint64 {
+	if v == math.MinInt64 {
+		return uint64(math.MaxInt64) + 1
+	}
+	if v < 0 {
+		return uint64(-v)
+	}
+	return uint64(v)
+}
@@ -0,0 +1,162 @@
+package money
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Amount
+		wantErr error
+	}{
+		{name: "integer", value: "700", want: New(700, 0)},
+		{name: "two_digits", value: "599.99", want: New(599, 99)},
+		{name: "one_digit", value: "0.1", want: New(0, 10)},
+		{name: "trailing_zeros", value: "1.500", want: New(1, 50)},
+		{name: "exponent", value: "1.5e2", want: New(150, 0)},
+		{name: "negative", value: "-13.13", want: New(-13, -13)},
+		{name: "negative_too_precise", value: "0.004", wantErr: ErrTooPrecise},
+		{name: "negative_invalid_format", value: "12,5", wantErr: ErrInvalidFormat},
+		{name: "negative_out_of_range", value: "1e30", wantErr: ErrOutOfRange},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Parse(test.value)
+			assert.ErrorIs(t, err, test.wantErr, "Parse error")
+			assert.Equal(t, test.want, got, "Parsed amount")
+		})
+	}
+}
+
+func TestParseRound(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Amount
+		wantErr error
+	}{
+		{name: "two_digits", value: "599.99", want: New(599, 99)},
+		{name: "round_down", value: "729.984", want: New(729, 98)},
+		{name: "round_up", value: "729.987", want: New(729, 99)},
+		{name: "half_away_from_zero", value: "0.005", want: New(0, 1)},
+		{name: "negative_half", value: "-0.005", want: New(0, -1)},
+		{name: "negative_invalid_format", value: "12,5", wantErr: ErrInvalidFormat},
+		{name: "negative_out_of_range", value: "1e30", wantErr: ErrOutOfRange},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseRound(test.value)
+			assert.ErrorIs(t, err, test.wantErr, "ParseRound error")
+			assert.Equal(t, test.want, got, "Parsed amount")
+		})
+	}
+}
+
+func TestAmount_String(t *testing.T) {
+	assert.Equal(t, "400.00", New(400, 0).String())
+	assert.Equal(t, "0.05", New(0, 5).String())
+	assert.Equal(t, "-13.13", New(-13, -13).String())
+}
+
+func TestAmount_Float64(t *testing.T) {
+	assert.Equal(t, 400.0, New(400, 0).Float64())
+	assert.Equal(t, 0.05, New(0, 5).Float64())
+	assert.Equal(t, -13.13, New(-13, -13).Float64())
+}
+
+func TestAmount_JSON(t *testing.T) {
+	type payload struct {
+		Sum Amount `json:"sum"`
+	}
+
+	tests := []struct {
+		name    string
+		json    string
+		want    Amount
+		encoded string
+		wantErr error
+	}{
+		{name: "integer", json: `{"sum":400}`, want: New(400, 0), encoded: `{"sum":400}`},
+		{name: "fraction", json: `{"sum":599.90}`, want: New(599, 90), encoded: `{"sum":599.9}`},
+		{name: "zero", json: `{"sum":0}`, want: 0, encoded: `{"sum":0}`},
+		{name: "null", json: `{"sum":null}`, want: 0, encoded: `{"sum":0}`},
+		{name: "negative_too_precise", json: `{"sum":0.004}`, wantErr: ErrTooPrecise},
+		{name: "negative_string", json: `{"sum":"10"}`, wantErr: ErrInvalidFormat},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var p payload
+			err := json.Unmarshal([]byte(test.json), &p)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr, "Unmarshal error")
+				return
+			}
+			require.NoError(t, err, "Unmarshal error")
+			assert.Equal(t, test.want, p.Sum, "Unmarshaled amount")
+
+			encoded, err := json.Marshal(p)
+			require.NoError(t, err, "Marshal error")
+			assert.Equal(t, test.encoded, string(encoded), "Marshaled amount")
+		})
+	}
+}
+
+func TestAmount_ScanNumeric(t *testing.T) {
+	tests := []struct {
+		name    string
+		numeric pgtype.Numeric
+		want    Amount
+		wantErr error
+	}{
+		{
+			name:    "scale_2",
+			numeric: pgtype.Numeric{Int: big.NewInt(59999), Exp: -2, Valid: true},
+			want:    New(599, 99),
+		},
+		{
+			name:    "positive_exponent",
+			numeric: pgtype.Numeric{Int: big.NewInt(7), Exp: 2, Valid: true},
+			want:    New(700, 0),
+		},
+		{
+			name:    "null",
+			numeric: pgtype.Numeric{},
+			want:    0,
+		},
+		{
+			name:    "negative_too_precise",
+			numeric: pgtype.Numeric{Int: big.NewInt(1), Exp: -3, Valid: true},
+			wantErr: ErrTooPrecise,
+		},
+		{
+			name:    "negative_nan",
+			numeric: pgtype.Numeric{NaN: true, Valid: true},
+			wantErr: ErrInvalidFormat,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got Amount
+			err := got.ScanNumeric(test.numeric)
+			assert.ErrorIs(t, err, test.wantErr, "Scan error")
+			assert.Equal(t, test.want, got, "Scanned amount")
+		})
+	}
+}
+
+func TestAmount_NumericValue(t *testing.T) {
+	n, err := New(-13, -13).NumericValue()
+	require.NoError(t, err)
+
+	var got Amount
+	require.NoError(t, got.ScanNumeric(n))
+	assert.Equal(t, New(-13, -13), got, "Round trip through pgtype.Numeric")
+}
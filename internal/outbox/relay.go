@@ -0,0 +1,136 @@
+// Package outbox публикует события начисления баллов из таблицы outbox во внешний приемник.
+// Событие публикуется хотя бы один раз, повторы получатель отбрасывает по Message.ID.
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/poller"
+)
+
+type Repository interface {
+	ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]entity.OutboxEvent, error)
+	MarkPublished(ctx context.Context, id uint64) error
+	Retry(ctx context.Context, id uint64, reason string, at time.Time) error
+	Release(ctx context.Context, id uint64, at time.Time) error
+}
+
+type Logger interface {
+	Error(message string, err error, fields ...logger.Field)
+	Warn(message string, err error, fields ...logger.Field)
+}
+
+type EventLogger interface {
+	EventInfo(message string, event *logger.EventLogData)
+}
+
+// Config Timeout ограничивает публикацию одного события. Неопубликованное событие
+// повторяется бесконечно с задержкой от BaseDelay до MaxDelay.
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	Timeout      time.Duration
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+}
+
+var DefaultConfig = Config{
+	PollInterval: time.Second,
+	BatchSize:    50,
+	Timeout:      5 * time.Second,
+	BaseDelay:    time.Second,
+	MaxDelay:     5 * time.Minute,
+}
+
+// Relay в отличие от диспетчера вебхуков публикует строго по порядку и не отбрасывает события.
+type Relay struct {
+	repository Repository
+	sink       Sink
+	logger     Logger
+	config     Config
+	now        func() time.Time
+	poller     *poller.Poller
+}
+
+func NewRelay(r Repository, s Sink, l Logger, cfg Config) *Relay {
+	relay := &Relay{
+		repository: r,
+		sink:       s,
+		logger:     l,
+		config:     cfg,
+		now:        time.Now,
+	}
+	relay.poller = poller.New("outbox relay", cfg.PollInterval, relay.relay)
+
+	return relay
+}
+
+func (r *Relay) Run(ctx context.Context) {
+	r.poller.Run(ctx)
+}
+
+// Stop дожидается окончания публикации текущего события.
+func (r *Relay) Stop() {
+	r.poller.Stop()
+}
+
+// relay публикует события по порядку и останавливается на первой ошибке,
+// чтобы более поздние события не обгоняли неопубликованное. Остаток пачки откладывается
+// вместе с ним, а следующие пачки ClaimEvents не выдаст, пока оно не будет опубликовано.
+func (r *Relay) relay(ctx context.Context) {
+	events, err := r.repository.ClaimEvents(ctx, r.config.BatchSize, poller.Lease(r.config.BatchSize, r.config.Timeout))
+	if err != nil {
+		r.logger.Error("failed to claim outbox events", err)
+		return
+	}
+
+	// Отметки о публикации сохраняются и после сигнала остановки.
+	storeCtx := context.WithoutCancel(ctx)
+
+	for i, event := range events {
+		if ctx.Err() != nil {
+			r.release(storeCtx, events[i:], r.now())
+			return
+		}
+
+		publishCtx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+		err := r.sink.Publish(publishCtx, message(event))
+		cancel()
+
+		if err != nil {
+			if ctx.Err() != nil {
+				r.release(storeCtx, events[i:], r.now())
+				return
+			}
+
+			r.logger.Warn("failed to publish outbox event", err)
+			at := r.now().Add(r.delay(event.Attempts + 1))
+			if err := r.repository.Retry(storeCtx, event.ID, err.Error(), at); err != nil {
+				r.logger.Error("failed to reschedule outbox event", err)
+			}
+			r.release(storeCtx, events[i+1:], at)
+			return
+		}
+
+		if err := r.repository.MarkPublished(storeCtx, event.ID); err != nil {
+			r.logger.Error("failed to mark outbox event published", err)
+		}
+	}
+}
+
+// release возвращает неопубликованные события в очередь, не засчитывая попытку.
+func (r *Relay) release(ctx context.Context, events []entity.OutboxEvent, at time.Time) {
+	for _, event := range events {
+		if err := r.repository.Release(ctx, event.ID, at); err != nil {
+			r.logger.Error("failed to release outbox event", err)
+		}
+	}
+}
+
+// delay failures считает и текущую неудачу.
+func (r *Relay) delay(failures int) time.Duration {
+	return poller.Backoff(failures, r.config.BaseDelay, r.config.MaxDelay)
+}
@@ -0,0 +1,220 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/outbox/mocks"
+)
+
+var testConfig = Config{
+	PollInterval: time.Second,
+	BatchSize:    10,
+	Timeout:      time.Second,
+	BaseDelay:    time.Second,
+	MaxDelay:     time.Minute,
+}
+
+// testSink возвращает ошибки из errs по порядку публикаций.
+type testSink struct {
+	published []Message
+	errs      []error
+}
+
+func (s *testSink) Publish(_ context.Context, m Message) error {
+	s.published = append(s.published, m)
+	if len(s.errs) == 0 {
+		return nil
+	}
+	err := s.errs[0]
+	s.errs = s.errs[1:]
+	return err
+}
+
+func TestRelay_delay(t *testing.T) {
+	r := NewRelay(nil, nil, nil, testConfig)
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: time.Second},
+		{attempts: 3, want: 4 * time.Second},
+		{attempts: 7, want: time.Minute},
+		{attempts: 1000, want: time.Minute},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("attempts_%d", test.attempts), func(t *testing.T) {
+			assert.Equal(t, test.want, r.delay(test.attempts), "Retry delay")
+		})
+	}
+}
+
+func TestRelay_relay(t *testing.T) {
+	now := time.Date(2025, 10, 10, 10, 0, 0, 0, time.UTC)
+	events := []entity.OutboxEvent{
+		{
+			ID:       1,
+			EventID:  "6f1c2e5a-0b7d-4f4e-9a51-1d2f0c3b4a59",
+			Type:     entity.OutboxOrderStatusChanged,
+			Payload:  json.RawMessage(`{"number":"5062821234567892"}`),
+			Attempts: 0,
+			Created:  now.Add(-time.Minute),
+		},
+		{
+			ID:       2,
+			EventID:  "0d3f8b1e-4c2a-4b6f-8e7d-5a9c1b2e3f40",
+			Type:     entity.OutboxBalanceAccrued,
+			Payload:  json.RawMessage(`{"amount":729.98}`),
+			Attempts: 2,
+			Created:  now.Add(-time.Minute),
+		},
+		{
+			ID:       3,
+			EventID:  "9b8a7c6d-5e4f-4a3b-9c2d-1e0f9a8b7c6d",
+			Type:     entity.OutboxOrderStatusChanged,
+			Payload:  json.RawMessage(`{"number":"5062821234567892"}`),
+			Attempts: 0,
+			Created:  now,
+		},
+	}
+
+	tests := []struct {
+		name    string
+		rSetup  func(t *testing.T) Repository
+		sink    *testSink
+		lSetup  func(t *testing.T) Logger
+		stopped bool
+		want    []Message
+	}{
+		{
+			name: "success",
+			rSetup: func(t *testing.T) Repository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockRepository(ctrl)
+				repository.EXPECT().
+					ClaimEvents(gomock.All(), testConfig.BatchSize, gomock.All()).
+					Return(events, nil)
+				gomock.InOrder(
+					repository.EXPECT().MarkPublished(gomock.All(), uint64(1)).Return(nil),
+					repository.EXPECT().MarkPublished(gomock.All(), uint64(2)).Return(nil),
+					repository.EXPECT().MarkPublished(gomock.All(), uint64(3)).Return(nil),
+				)
+				return repository
+			},
+			sink: &testSink{},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: []Message{message(events[0]), message(events[1]), message(events[2])},
+		},
+		{
+			name: "stop_on_publish_error",
+			rSetup: func(t *testing.T) Repository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockRepository(ctrl)
+				repository.EXPECT().
+					ClaimEvents(gomock.All(), testConfig.BatchSize, gomock.All()).
+					Return(events, nil)
+				repository.EXPECT().
+					MarkPublished(gomock.All(), uint64(1)).
+					Return(nil)
+				repository.EXPECT().
+					Retry(gomock.All(), uint64(2), "any error", now.Add(4*time.Second)).
+					Return(nil)
+				repository.EXPECT().
+					Release(gomock.All(), uint64(3), now.Add(4*time.Second)).
+					Return(nil)
+				return repository
+			},
+			sink: &testSink{errs: []error{nil, fmt.Errorf("any error")}},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Warn("failed to publish outbox event", gomock.All())
+				return logger
+			},
+			want: []Message{message(events[0]), message(events[1])},
+		},
+		{
+			name: "release_when_stopped",
+			rSetup: func(t *testing.T) Repository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockRepository(ctrl)
+				repository.EXPECT().
+					ClaimEvents(gomock.All(), testConfig.BatchSize, gomock.All()).
+					Return(events, nil)
+				for _, event := range events {
+					repository.EXPECT().
+						Release(gomock.All(), event.ID, now).
+						Return(nil)
+				}
+				repository.EXPECT().
+					Retry(gomock.All(), gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			sink: &testSink{},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			stopped: true,
+			want:    nil,
+		},
+		{
+			name: "negative_claim_error",
+			rSetup: func(t *testing.T) Repository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockRepository(ctrl)
+				repository.EXPECT().
+					ClaimEvents(gomock.All(), testConfig.BatchSize, gomock.All()).
+					Return(nil, fmt.Errorf("any error"))
+				return repository
+			},
+			sink: &testSink{},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to claim outbox events", gomock.All())
+				return logger
+			},
+			want: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := NewRelay(test.rSetup(t), test.sink, test.lSetup(t), testConfig)
+			r.now = func() time.Time { return now }
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if test.stopped {
+				cancel()
+			}
+			defer cancel()
+			r.relay(ctx)
+
+			assert.Equal(t, test.want, test.sink.published, "Published messages")
+		})
+	}
+}
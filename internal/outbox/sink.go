@@ -0,0 +1,152 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+)
+
+const (
+	SinkLog        = "log"
+	SinkFilePrefix = "file:"
+
+	maxErrorBody = 512
+)
+
+var ErrUnknownSink = errors.New("outbox sink must be log, file:<path> or http(s) url")
+
+// Message ID одинаков при повторных публикациях одного события.
+type Message struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Created time.Time       `json:"created_at"`
+	Data    json.RawMessage `json:"data"`
+}
+
+type Sink interface {
+	Publish(ctx context.Context, m Message) error
+}
+
+// NewSink создает приемник по описанию из конфигурации: log, file:<path> или http(s) url.
+// Возвращаемая функция освобождает ресурсы приемника.
+func NewSink(spec string, l EventLogger) (Sink, func() error, error) {
+	switch {
+	case spec == SinkLog:
+		return NewLogSink(l), func() error { return nil }, nil
+	case strings.HasPrefix(spec, SinkFilePrefix):
+		sink, err := NewFileSink(strings.TrimPrefix(spec, SinkFilePrefix))
+		if err != nil {
+			return nil, nil, err
+		}
+		return sink, sink.Close, nil
+	case strings.HasPrefix(spec, "http://"), strings.HasPrefix(spec, "https://"):
+		return NewHTTPSink(spec), func() error { return nil }, nil
+	default:
+		return nil, nil, ErrUnknownSink
+	}
+}
+
+func message(e entity.OutboxEvent) Message {
+	return Message{ID: e.EventID, Type: e.Type, Created: e.Created, Data: e.Payload}
+}
+
+// LogSink пишет события в лог сервиса, подходит для отладки.
+type LogSink struct {
+	logger EventLogger
+}
+
+func NewLogSink(l EventLogger) *LogSink {
+	return &LogSink{logger: l}
+}
+
+func (s *LogSink) Publish(_ context.Context, m Message) error {
+	s.logger.EventInfo("outbox event", &logger.EventLogData{ID: m.ID, Type: m.Type, Payload: string(m.Data)})
+	return nil
+}
+
+// FileSink дописывает события в файл по одному JSON на строку.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open outbox file: %w", err)
+	}
+
+	return &FileSink{file: file}, nil
+}
+
+func (s *FileSink) Publish(_ context.Context, m Message) error {
+	line, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox message: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write outbox file: %w", err)
+	}
+	// Событие считается опубликованным только после записи на диск.
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync outbox file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// HTTPSink отправляет событие POST запросом, ID события передается в заголовке Idempotency-Key.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPSink(url string) *HTTPSink {
+	return &HTTPSink{url: url, client: &http.Client{}}
+}
+
+func (s *HTTPSink) Publish(ctx context.Context, m Message) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", m.ID)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send outbox message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, bytes.TrimSpace(text))
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/outbox/mocks"
+)
+
+var testMessage = Message{
+	ID:      "6f1c2e5a-0b7d-4f4e-9a51-1d2f0c3b4a59",
+	Type:    "order.status_changed",
+	Created: time.Date(2025, 10, 10, 10, 0, 0, 0, time.UTC),
+	Data:    json.RawMessage(`{"number":"5062821234567892"}`),
+}
+
+const testMessageJSON = `{"id":"6f1c2e5a-0b7d-4f4e-9a51-1d2f0c3b4a59","type":"order.status_changed",` +
+	`"created_at":"2025-10-10T10:00:00Z","data":{"number":"5062821234567892"}}`
+
+func TestNewSink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	eventLogger := mocks.NewMockEventLogger(ctrl)
+
+	tests := []struct {
+		spec    string
+		want    Sink
+		wantErr error
+	}{
+		{spec: "log", want: &LogSink{}},
+		{spec: "http://localhost:8081/events", want: &HTTPSink{}},
+		{spec: "file:" + filepath.Join(t.TempDir(), "outbox.jsonl"), want: &FileSink{}},
+		{spec: "kafka://localhost", wantErr: ErrUnknownSink},
+	}
+
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			sink, closeSink, err := NewSink(test.spec, eventLogger)
+			assert.ErrorIs(t, err, test.wantErr, "Sink error")
+			if test.wantErr != nil {
+				return
+			}
+			defer closeSink()
+			assert.IsType(t, test.want, sink, "Sink type")
+		})
+	}
+}
+
+func TestLogSink_Publish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	eventLogger := mocks.NewMockEventLogger(ctrl)
+	eventLogger.EXPECT().EventInfo("outbox event", &logger.EventLogData{
+		ID:      testMessage.ID,
+		Type:    testMessage.Type,
+		Payload: string(testMessage.Data),
+	})
+
+	err := NewLogSink(eventLogger).Publish(context.Background(), testMessage)
+	assert.Nil(t, err, "Publish error")
+}
+
+func TestFileSink_Publish(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	sink, err := NewFileSink(path)
+	require.Nil(t, err, "Open file")
+
+	require.Nil(t, sink.Publish(context.Background(), testMessage), "First publish")
+	require.Nil(t, sink.Publish(context.Background(), testMessage), "Repeated publish")
+	require.Nil(t, sink.Close(), "Close file")
+
+	content, err := os.ReadFile(path)
+	require.Nil(t, err, "Read file")
+	assert.Equal(t, testMessageJSON+"\n"+testMessageJSON+"\n", string(content), "File content")
+}
+
+func TestHTTPSink_Publish(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "success", status: http.StatusAccepted, wantErr: false},
+		{name: "negative_server_error", status: http.StatusServiceUnavailable, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, http.MethodPost, r.Method, "Request method")
+				assert.Equal(t, testMessage.ID, r.Header.Get("Idempotency-Key"), "Idempotency-Key header")
+				assert.JSONEq(t, testMessageJSON, string(body), "Request body")
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			err := NewHTTPSink(server.URL).Publish(context.Background(), testMessage)
+			assert.Equal(t, test.wantErr, err != nil, "Publish error")
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testParams = Params{Memory: 1024, Time: 1, Threads: 1, SaltLen: 16, KeyLen: 32}
+
+func TestHasher_Hash(t *testing.T) {
+	hasher := New(testParams)
+
+	hash, err := hasher.Hash("t1estP5assword")
+	require.Nil(t, err, "Hash password")
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"), "PHC prefix")
+
+	other, err := hasher.Hash("t1estP5assword")
+	require.Nil(t, err, "Hash password again")
+	assert.NotEqual(t, hash, other, "Hashes are salted")
+}
+
+func TestHasher_Verify(t *testing.T) {
+	hasher := New(testParams)
+	argonHash, err := hasher.Hash("t1estP5assword")
+	require.Nil(t, err, "Hash password")
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("t1estP5assword"), bcrypt.MinCost)
+	require.Nil(t, err, "Bcrypt password")
+
+	tests := []struct {
+		name     string
+		password string
+		hash     string
+		want     bool
+		wantErr  error
+	}{
+		{name: "argon2id", password: "t1estP5assword", hash: argonHash, want: true},
+		{name: "argon2id_mismatch", password: "badPassword", hash: argonHash, want: false},
+		{name: "bcrypt", password: "t1estP5assword", hash: string(bcryptHash), want: true},
+		{name: "bcrypt_mismatch", password: "badPassword", hash: string(bcryptHash), want: false},
+		{name: "negative_unknown_format", password: "t1estP5assword", hash: "plain", wantErr: ErrUnknownHash},
+		{
+			name:     "negative_corrupted_salt",
+			password: "t1estP5assword",
+			hash:     "$argon2id$v=19$m=1024,t=1,p=1$!!!$AAAA",
+			wantErr:  ErrInvalidHash,
+		},
+		{
+			name:     "negative_unknown_version",
+			password: "t1estP5assword",
+			hash:     strings.Replace(argonHash, "v=19", "v=16", 1),
+			wantErr:  ErrInvalidHash,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ok, err := hasher.Verify(test.password, test.hash)
+			assert.ErrorIs(t, err, test.wantErr, "Verify error")
+			assert.Equal(t, test.want, ok, "Password matches")
+		})
+	}
+}
+
+func TestHasher_NeedsRehash(t *testing.T) {
+	hasher := New(testParams)
+	current, err := hasher.Hash("t1estP5assword")
+	require.Nil(t, err, "Hash password")
+
+	weaker := testParams
+	weaker.Memory = 512
+	outdated, err := New(weaker).Hash("t1estP5assword")
+	require.Nil(t, err, "Hash with outdated params")
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("t1estP5assword"), bcrypt.MinCost)
+	require.Nil(t, err, "Bcrypt password")
+
+	assert.False(t, hasher.NeedsRehash(current), "Current params")
+	assert.True(t, hasher.NeedsRehash(outdated), "Outdated params")
+	assert.True(t, hasher.NeedsRehash(string(bcryptHash)), "Legacy bcrypt")
+}
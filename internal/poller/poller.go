@@ -0,0 +1,67 @@
+// Package poller общий цикл фоновых обработчиков, которые периодически арендуют
+// пачку записей из БД и обрабатывают ее: outbox-релея и диспетчера вебхуков.
+package poller
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+type Poller struct {
+	name     string
+	interval time.Duration
+	poll     func(ctx context.Context)
+	cancel   context.CancelFunc
+	stopped  chan struct{}
+}
+
+// New poll вызывается раз в interval, следующий вызов не начнется, пока не завершится текущий.
+func New(name string, interval time.Duration, poll func(ctx context.Context)) *Poller {
+	return &Poller{name: name, interval: interval, poll: poll}
+}
+
+func (p *Poller) Run(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.stopped = make(chan struct{})
+
+	go func() {
+		defer close(p.stopped)
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.poll(ctx)
+			}
+		}
+	}()
+
+	log.Println(p.name + " started")
+}
+
+// Stop дожидается окончания текущего вызова poll.
+func (p *Poller) Stop() {
+	p.cancel()
+	<-p.stopped
+	log.Println(p.name + " stopped")
+}
+
+// Backoff задержка после failures неудач подряд: удваивается, начиная с base, но не больше max.
+func Backoff(failures int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < failures && delay < max; i++ {
+		delay *= 2
+	}
+
+	return min(delay, max)
+}
+
+// Lease покрывает обработку всей пачки, после него записи заберет другой экземпляр.
+func Lease(batchSize int, timeout time.Duration) time.Duration {
+	return time.Duration(batchSize)*timeout + time.Minute
+}
@@ -0,0 +1,60 @@
+package poller
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 1, want: 10 * time.Second},
+		{failures: 2, want: 20 * time.Second},
+		{failures: 3, want: 40 * time.Second},
+		{failures: 4, want: time.Minute},
+		{failures: 1000, want: time.Minute},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("failures_%d", test.failures), func(t *testing.T) {
+			assert.Equal(t, test.want, Backoff(test.failures, 10*time.Second, time.Minute), "Retry delay")
+		})
+	}
+}
+
+func TestPoller_Stop(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	p := New("test poller", time.Millisecond, func(ctx context.Context) {
+		if calls.Add(1) == 1 {
+			<-release
+		}
+	})
+	p.Run(context.Background())
+
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond, "Poll is called")
+
+	stopped := make(chan struct{})
+	go func() {
+		p.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned before poll finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	<-stopped
+	assert.Equal(t, int32(1), calls.Load(), "No polls after stop")
+}
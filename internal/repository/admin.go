@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository/errors"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository/pg"
+)
+
+const orderDetailsColumns = `number, user_id, status, accrual, uploaded_at, updated_at,
+	COALESCE(attempts, 0) AS attempts, last_error, next_attempt_at, lease_owner, lease_expires_at`
+
+type AdminOrder struct {
+	pool *pgxpool.Pool
+}
+
+func NewAdminOrder(db *pg.DB) *AdminOrder {
+	return &AdminOrder{pool: db.Pool()}
+}
+
+// Search возвращает страницу заказов всех пользователей, упорядоченных
+// по времени загрузки и номеру, начиная с позиции после f.After.
+func (r *AdminOrder) Search(ctx context.Context, f entity.AdminOrderFilter) ([]entity.OrderDetails, error) {
+	var args queryArgs
+	cmp, dir := listOrder(f.Ascending)
+
+	conds := []string{"TRUE"}
+	if f.Number != "" {
+		conds = append(conds, "number = "+args.add(f.Number))
+	}
+	if f.UserID != 0 {
+		conds = append(conds, "user_id = "+args.add(f.UserID))
+	}
+	if len(f.Statuses) > 0 {
+		conds = append(conds, "status = ANY("+args.add(f.Statuses)+")")
+	}
+	if !f.From.IsZero() {
+		conds = append(conds, "uploaded_at >= "+args.add(f.From))
+	}
+	if !f.To.IsZero() {
+		conds = append(conds, "uploaded_at < "+args.add(f.To))
+	}
+	if f.After != nil {
+		conds = append(conds, fmt.Sprintf(
+			"(uploaded_at, number) %s (%s, %s)", cmp, args.add(f.After.Uploaded), args.add(f.After.Number),
+		))
+	}
+
+	query := `SELECT ` + orderDetailsColumns + ` FROM orders` +
+		where(conds) +
+		fmt.Sprintf(" ORDER BY uploaded_at %[1]s, number %[1]s LIMIT %s", dir, args.add(f.Limit))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select from orders: %w", err)
+	}
+
+	orders, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.OrderDetails])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse selected orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+// CountByStatus считает заказы всех пользователей по статусам для метрик.
+func (r *AdminOrder) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	rows, err := r.pool.Query(ctx, `SELECT status, COUNT(*) FROM orders GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count orders: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var (
+			status string
+			count  int64
+		)
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan orders count: %w", err)
+		}
+		counts[status] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count orders: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (r *AdminOrder) GetDetails(ctx context.Context, number string) (entity.OrderDetails, error) {
+	return selectOrderDetails(ctx, r.pool, number)
+}
+
+// Requeue ставит заказ в очередь на немедленный опрос, сбрасывая счетчик попыток.
+// FAILED заказ возвращается в NEW. Если статус заказа не входит в from или аккаунт
+// владельца закрыт, возвращает ErrNoRowsUpdated.
+func (r *AdminOrder) Requeue(ctx context.Context, number string, from []string) (entity.OrderDetails, error) {
+	return r.reset(
+		ctx,
+		number,
+		from,
+		`CASE WHEN o.status = $3 THEN $4 ELSE o.status END`,
+		entity.OrderStatusFailed,
+		entity.OrderStatusNew,
+	)
+}
+
+// SetStatus переводит заказ в status со сбросом счетчика попыток.
+// Если статус заказа не входит в from или аккаунт владельца закрыт, возвращает ErrNoRowsUpdated.
+func (r *AdminOrder) SetStatus(
+	ctx context.Context,
+	number string,
+	status string,
+	from []string,
+) (entity.OrderDetails, error) {
+	return r.reset(ctx, number, from, `$3`, status)
+}
+
+func (r *AdminOrder) reset(
+	ctx context.Context,
+	number string,
+	from []string,
+	statusExpr string,
+	statusArgs ...any,
+) (order entity.OrderDetails, err error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return order, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID uint64
+	var prevStatus string
+	query := `UPDATE orders o
+				SET
+					status = ` + statusExpr + `,
+					attempts = 0,
+					next_attempt_at = NOW(),
+					last_error = NULL,
+					updated_at = NOW(),
+					lease_owner = NULL,
+					lease_expires_at = NULL
+				FROM (SELECT number, status FROM orders WHERE number = $1 FOR UPDATE) prev
+				WHERE o.number = prev.number AND o.status = ANY($2)
+					AND EXISTS (SELECT 1 FROM users u WHERE u.id = o.user_id AND u.deleted_at IS NULL)
+				RETURNING o.user_id, prev.status`
+
+	args := append([]any{number, from}, statusArgs...)
+	err = tx.QueryRow(ctx, query, args...).Scan(&userID, &prevStatus)
+	if err != nil {
+		err = errors.Trasform(err)
+		if err != errors.ErrNotFound {
+			return order, fmt.Errorf("failed to update order#%s : %w", number, err)
+		}
+
+		// Заказ есть, но его статус не допускает изменения или аккаунт закрыт
+		if _, err := selectOrderDetails(ctx, tx, number); err != nil {
+			return order, err
+		}
+		return order, fmt.Errorf("failed to update order#%s : %w", number, errors.ErrNoRowsUpdated)
+	}
+
+	order, err = selectOrderDetails(ctx, tx, number)
+	if err != nil {
+		return order, err
+	}
+
+	if order.Status != prevStatus {
+		if err := orderStatusChanged(ctx, tx, userID, prevStatus, order.Order); err != nil {
+			return order, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return order, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return order, nil
+}
+
+// querier общая часть pgxpool.Pool и pgx.Tx для выборок.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func selectOrderDetails(ctx context.Context, db querier, number string) (order entity.OrderDetails, err error) {
+	query := `SELECT ` + orderDetailsColumns + ` FROM orders WHERE number = $1`
+
+	rows, err := db.Query(ctx, query, number)
+	if err != nil {
+		return order, fmt.Errorf("failed to select from orders: %w", err)
+	}
+
+	order, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.OrderDetails])
+	if err != nil {
+		return order, fmt.Errorf("failed to parse order#%s : %w", number, errors.Trasform(err))
+	}
+
+	return order, nil
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
@@ -30,3 +32,167 @@ func (b *Balance) GetByUser(ctx context.Context, userID uint64) (entity.Balance,
 
 	return balance, nil
 }
+
+func (b *Balance) Rebuild(ctx context.Context, userID uint64) (rec entity.BalanceReconciliation, err error) {
+	tx, err := b.pool.Begin(ctx)
+	if err != nil {
+		return rec, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `SELECT user_id, balance, debited FROM balance WHERE user_id = $1 FOR UPDATE`
+	row := tx.QueryRow(ctx, query, userID)
+	err = row.Scan(&rec.Cached.UserID, &rec.Cached.Balance, &rec.Cached.Debited)
+	if err != nil {
+		return rec, fmt.Errorf("failed to select balance: %w", errors.Trasform(err))
+	}
+
+	query = `SELECT
+				COALESCE(SUM(amount), 0),
+				COALESCE(-SUM(amount) FILTER (WHERE kind = $2), 0)
+			FROM ledger WHERE user_id = $1`
+	row = tx.QueryRow(ctx, query, userID, entity.LedgerKindWithdrawal)
+	rec.Ledger.UserID = userID
+	err = row.Scan(&rec.Ledger.Balance, &rec.Ledger.Debited)
+	if err != nil {
+		return rec, fmt.Errorf("failed to sum ledger: %w", err)
+	}
+
+	if rec.Consistent() {
+		return rec, nil
+	}
+
+	query = `UPDATE balance SET balance = $2, debited = $3 WHERE user_id = $1`
+	_, err = tx.Exec(ctx, query, userID, rec.Ledger.Balance, rec.Ledger.Debited)
+	if err != nil {
+		return rec, fmt.Errorf("failed to update balance: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return rec, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return rec, nil
+}
+
+// Adjust корректирует баланс пользователя на a.Amount. Списание не может сделать баланс
+// отрицательным, тогда возвращается ErrNoRowsUpdated. Корректировка не меняет сумму списаний.
+func (b *Balance) Adjust(
+	ctx context.Context,
+	a entity.BalanceAdjustment,
+) (adj entity.BalanceAdjustment, balance entity.Balance, err error) {
+	tx, err := b.pool.Begin(ctx)
+	if err != nil {
+		return adj, balance, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `LOCK TABLE balance IN ROW EXCLUSIVE MODE`)
+	if err != nil {
+		return adj, balance, fmt.Errorf("failed to lock balance table: %w", err)
+	}
+
+	query := `SELECT b.user_id FROM balance b JOIN users u ON u.id = b.user_id
+				WHERE b.user_id = $1 AND u.deleted_at IS NULL FOR UPDATE OF b`
+	err = tx.QueryRow(ctx, query, a.UserID).Scan(&balance.UserID)
+	if err != nil {
+		return adj, balance, fmt.Errorf("failed to select balance: %w", errors.Trasform(err))
+	}
+
+	query = `UPDATE balance SET balance = balance + $2
+				WHERE user_id = $1 AND balance + $2 >= 0
+				RETURNING balance, debited`
+	err = tx.QueryRow(ctx, query, a.UserID, a.Amount).Scan(&balance.Balance, &balance.Debited)
+	if err != nil {
+		err = errors.Trasform(err)
+		if err == errors.ErrNotFound {
+			err = errors.ErrNoRowsUpdated
+		}
+		return adj, balance, fmt.Errorf("failed to update balance: %w", err)
+	}
+
+	ledgerID, err := insertLedgerEntry(ctx, tx, entity.LedgerEntry{
+		UserID: a.UserID,
+		Kind:   entity.LedgerKindAdjustment,
+		Amount: a.Amount,
+	})
+	if err != nil {
+		return adj, balance, err
+	}
+
+	adj = a
+	query = `INSERT INTO balance_adjustments (ledger_id, user_id, amount, reason, operator_id)
+				VALUES($1, $2, $3, $4, $5) RETURNING id, created_at`
+	err = tx.QueryRow(ctx, query, ledgerID, a.UserID, a.Amount, a.Reason, a.OperatorID).Scan(&adj.ID, &adj.Created)
+	if err != nil {
+		return adj, balance, fmt.Errorf("failed to insert into balance_adjustments: %w", errors.Trasform(err))
+	}
+
+	err = addOutboxEvent(ctx, tx, entity.OutboxBalanceAdjusted, entity.BalanceAdjusted{
+		UserID:       adj.UserID,
+		AdjustmentID: adj.ID,
+		Amount:       adj.Amount,
+		Reason:       adj.Reason,
+		OperatorID:   adj.OperatorID,
+		Balance:      balance.Balance,
+		Debited:      balance.Debited,
+	})
+	if err != nil {
+		return adj, balance, err
+	}
+
+	err = notifyUser(ctx, tx, entity.UserEvent{
+		UserID:  adj.UserID,
+		Type:    entity.UserEventBalance,
+		Balance: &entity.BalanceEvent{Balance: balance.Balance, Debited: balance.Debited},
+	})
+	if err != nil {
+		return adj, balance, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return adj, balance, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return adj, balance, nil
+}
+
+// History возвращает страницу журнала операций пользователя, упорядоченного
+// по времени записи и идентификатору, начиная с позиции после f.After.
+func (b *Balance) History(ctx context.Context, userID uint64, f entity.LedgerFilter) ([]entity.LedgerEntry, error) {
+	var (
+		list []entity.LedgerEntry
+		args queryArgs
+	)
+	cmp, dir := listOrder(f.Ascending)
+
+	conds := []string{"l.user_id = " + args.add(userID)}
+	if !f.From.IsZero() {
+		conds = append(conds, "l.created_at >= "+args.add(f.From))
+	}
+	if !f.To.IsZero() {
+		conds = append(conds, "l.created_at < "+args.add(f.To))
+	}
+	if f.After != nil {
+		conds = append(conds, fmt.Sprintf(
+			"(l.created_at, l.id) %s (%s, %s)", cmp, args.add(f.After.Created), args.add(f.After.ID),
+		))
+	}
+
+	query := `SELECT l.id, l.user_id, l.kind, l.amount, l.order_num, COALESCE(a.reason, '') AS reason, l.created_at
+				FROM ledger l LEFT JOIN balance_adjustments a ON a.ledger_id = l.id` +
+		where(conds) +
+		fmt.Sprintf(" ORDER BY l.created_at %[1]s, l.id %[1]s LIMIT %s", dir, args.add(f.Limit))
+
+	rows, err := b.pool.Query(ctx, query, args...)
+	if err != nil {
+		return list, errors.Trasform(err)
+	}
+
+	list, err = pgx.CollectRows(rows, pgx.RowToStructByName[entity.LedgerEntry])
+	if err != nil {
+		return list, errors.Trasform(err)
+	}
+
+	return list, nil
+}
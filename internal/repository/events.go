@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository/pg"
+)
+
+type Events struct {
+	pool *pgxpool.Pool
+}
+
+func NewEvents(db *pg.DB) *Events {
+	return &Events{pool: db.Pool()}
+}
+
+// Listen занимает отдельное соединение и передает уведомления в handle,
+// пока не произойдет ошибка или не будет отменен ctx.
+func (r *Events) Listen(ctx context.Context, handle func(entity.UserEvent)) error {
+	pooled, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	// Соединение с LISTEN нельзя возвращать в пул.
+	conn := pooled.Hijack()
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	_, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{entity.UserEventsChannel}.Sanitize())
+	if err != nil {
+		return fmt.Errorf("failed to listen %s: %w", entity.UserEventsChannel, err)
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to wait for notification: %w", err)
+		}
+
+		var event entity.UserEvent
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			return fmt.Errorf("failed to decode user event: %w", err)
+		}
+
+		handle(event)
+	}
+}
+
+// notifyUser отправляет уведомление при фиксации транзакции.
+func notifyUser(ctx context.Context, db execer, event entity.UserEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode user event: %w", err)
+	}
+
+	_, err = db.Exec(ctx, `SELECT pg_notify($1, $2)`, entity.UserEventsChannel, string(payload))
+	if err != nil {
+		return fmt.Errorf("failed to notify user event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository/errors"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository/pg"
+)
+
+type Health struct {
+	pool *pgxpool.Pool
+}
+
+func NewHealth(db *pg.DB) *Health {
+	return &Health{pool: db.Pool()}
+}
+
+func (h *Health) Ping(ctx context.Context) error {
+	return h.pool.Ping(ctx)
+}
+
+// MigrationVersion версия схемы из таблицы golang-migrate, dirty после прерванной миграции.
+func (h *Health) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+	query := `SELECT version, dirty FROM schema_migrations LIMIT 1`
+	err := h.pool.QueryRow(ctx, query).Scan(&version, &dirty)
+	if err != nil {
+		return 0, false, errors.Trasform(err)
+	}
+
+	return uint(version), dirty, nil
+}
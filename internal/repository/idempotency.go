@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository/errors"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository/pg"
+)
+
+type Idempotency struct {
+	pool *pgxpool.Pool
+}
+
+func NewIdempotency(db *pg.DB) *Idempotency {
+	return &Idempotency{pool: db.Pool()}
+}
+
+// Reserve сохраняет ключ на время lease, если его еще нет или он устарел.
+// Если действующий ключ уже существует, возвращает его с created = false.
+// Устаревшие ключи удаляет DeleteExpired, здесь они только перезаписываются.
+func (r *Idempotency) Reserve(
+	ctx context.Context,
+	k entity.IdempotencyKey,
+	lease time.Duration,
+) (stored entity.IdempotencyKey, created bool, err error) {
+	query := `INSERT INTO idempotency_keys (user_id, key, request_hash, expires_at)
+				VALUES($1, $2, $3, NOW() + make_interval(secs => $4))
+				ON CONFLICT (user_id, key) DO UPDATE
+				SET request_hash = EXCLUDED.request_hash, status_code = 0, content_type = '', body = '',
+					created_at = NOW(), expires_at = EXCLUDED.expires_at
+				WHERE idempotency_keys.expires_at <= NOW()`
+	tag, err := r.pool.Exec(ctx, query, k.UserID, k.Key, k.RequestHash, lease.Seconds())
+	if err != nil {
+		return stored, false, fmt.Errorf("failed to insert into idempotency_keys: %w", err)
+	}
+	if tag.RowsAffected() == 1 {
+		return k, true, nil
+	}
+
+	query = `SELECT user_id, key, request_hash, status_code, content_type, body, created_at
+				FROM idempotency_keys WHERE user_id = $1 AND key = $2`
+	rows, err := r.pool.Query(ctx, query, k.UserID, k.Key)
+	if err != nil {
+		return stored, false, fmt.Errorf("failed to select from idempotency_keys: %w", err)
+	}
+
+	stored, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.IdempotencyKey])
+	if err != nil {
+		return stored, false, fmt.Errorf("failed to parse idempotency key: %w", errors.Trasform(err))
+	}
+
+	return stored, false, nil
+}
+
+// Complete сохраняет ответ и продлевает ключ на ttl для повторной выдачи.
+// Ключ, устаревший и перехваченный другим запросом, не перезаписывается: у него другой
+// хэш запроса либо ответ уже сохранен.
+func (r *Idempotency) Complete(ctx context.Context, k entity.IdempotencyKey, ttl time.Duration) error {
+	query := `UPDATE idempotency_keys
+				SET status_code = $3, content_type = $4, body = $5, expires_at = NOW() + make_interval(secs => $6)
+				WHERE user_id = $1 AND key = $2 AND request_hash = $7 AND status_code = 0`
+
+	tag, err := r.pool.Exec(
+		ctx,
+		query,
+		k.UserID,
+		k.Key,
+		k.StatusCode,
+		k.ContentType,
+		k.Body,
+		ttl.Seconds(),
+		k.RequestHash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update idempotency key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to update idempotency key: %w", errors.ErrNoRowsUpdated)
+	}
+
+	return nil
+}
+
+// Delete удаляет незавершенный ключ запроса с хэшем k.RequestHash.
+func (r *Idempotency) Delete(ctx context.Context, k entity.IdempotencyKey) error {
+	query := `DELETE FROM idempotency_keys
+				WHERE user_id = $1 AND key = $2 AND request_hash = $3 AND status_code = 0`
+
+	_, err := r.pool.Exec(ctx, query, k.UserID, k.Key, k.RequestHash)
+	if err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Idempotency) DeleteExpired(ctx context.Context) error {
+	query := `DELETE FROM idempotency_keys WHERE expires_at < NOW()`
+
+	if _, err := r.pool.Exec(ctx, query); err != nil {
+		return fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	return nil
+}
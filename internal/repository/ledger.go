@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+)
+
+// Любое изменение таблицы balance должно сопровождаться записью в журнал
+// в той же транзакции, balance лишь кэширует сумму по журналу.
+func addLedgerEntry(ctx context.Context, tx pgx.Tx, e entity.LedgerEntry) error {
+	_, err := insertLedgerEntry(ctx, tx, e)
+	return err
+}
+
+func insertLedgerEntry(ctx context.Context, tx pgx.Tx, e entity.LedgerEntry) (id uint64, err error) {
+	query := `INSERT INTO ledger (user_id, kind, amount, order_num) VALUES($1, $2, $3, $4) RETURNING id`
+
+	err = tx.QueryRow(ctx, query, e.UserID, e.Kind, e.Amount, e.OrderNumber).Scan(&id)
+	if err != nil {
+		return id, fmt.Errorf("failed to insert into ledger: %w", err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository/pg"
+)
+
+type LoginAttempts struct {
+	pool *pgxpool.Pool
+}
+
+func NewLoginAttempts(db *pg.DB) *LoginAttempts {
+	return &LoginAttempts{pool: db.Pool()}
+}
+
+// LockedUntil возвращает самую позднюю блокировку среди ключей
+// или нулевое время, если блокировок нет.
+func (r *LoginAttempts) LockedUntil(ctx context.Context, keys []string) (time.Time, error) {
+	var until *time.Time
+	query := `SELECT MAX(locked_until) FROM login_attempts WHERE key = ANY($1)`
+
+	err := r.pool.QueryRow(ctx, query, keys).Scan(&until)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to select from login_attempts: %w", err)
+	}
+	if until == nil {
+		return time.Time{}, nil
+	}
+
+	return *until, nil
+}
+
+// Fail увеличивает счетчик неудачных попыток и возвращает его значение.
+// Счетчик начинается заново, если с прошлой неудачи прошло больше window.
+func (r *LoginAttempts) Fail(ctx context.Context, key string, window time.Duration) (int, error) {
+	var failures int
+	query := `INSERT INTO login_attempts (key, failures) VALUES($1, 1)
+				ON CONFLICT (key) DO UPDATE SET
+					failures = CASE
+						WHEN login_attempts.updated_at < NOW() - make_interval(secs => $2) THEN 1
+						ELSE login_attempts.failures + 1
+					END,
+					updated_at = NOW()
+				RETURNING failures`
+	err := r.pool.QueryRow(ctx, query, key, window.Seconds()).Scan(&failures)
+	if err != nil {
+		return 0, fmt.Errorf("failed to upsert login attempt: %w", err)
+	}
+
+	return failures, nil
+}
+
+// Lock блокирует ключ до until, более поздняя блокировка не сокращается.
+func (r *LoginAttempts) Lock(ctx context.Context, key string, until time.Time) error {
+	query := `UPDATE login_attempts SET locked_until = GREATEST(locked_until, $2) WHERE key = $1`
+
+	_, err := r.pool.Exec(ctx, query, key, until)
+	if err != nil {
+		return fmt.Errorf("failed to lock login attempts: %w", err)
+	}
+
+	return nil
+}
+
+func (r *LoginAttempts) Reset(ctx context.Context, key string) error {
+	query := `DELETE FROM login_attempts WHERE key = $1`
+
+	_, err := r.pool.Exec(ctx, query, key)
+	if err != nil {
+		return fmt.Errorf("failed to reset login attempts: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired удаляет незаблокированные записи без неудач дольше window,
+// вызывается периодически в фоне.
+func (r *LoginAttempts) DeleteExpired(ctx context.Context, window time.Duration) error {
+	query := `DELETE FROM login_attempts
+				WHERE updated_at < NOW() - make_interval(secs => $1)
+					AND (locked_until IS NULL OR locked_until < NOW())`
+
+	if _, err := r.pool.Exec(ctx, query, window.Seconds()); err != nil {
+		return fmt.Errorf("failed to delete stale login attempts: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+)
+
+// MemoryLoginAttempts хранит попытки входа в памяти процесса,
+// подходит только для запуска в одном экземпляре.
+type MemoryLoginAttempts struct {
+	mu       sync.Mutex
+	attempts map[string]*entity.LoginAttempts
+	now      func() time.Time
+}
+
+func NewMemoryLoginAttempts() *MemoryLoginAttempts {
+	return &MemoryLoginAttempts{attempts: make(map[string]*entity.LoginAttempts), now: time.Now}
+}
+
+func (r *MemoryLoginAttempts) LockedUntil(ctx context.Context, keys []string) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var until time.Time
+	for _, key := range keys {
+		a, ok := r.attempts[key]
+		if ok && a.LockedUntil != nil && a.LockedUntil.After(until) {
+			until = *a.LockedUntil
+		}
+	}
+
+	return until, nil
+}
+
+func (r *MemoryLoginAttempts) Fail(ctx context.Context, key string, window time.Duration) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	a, ok := r.attempts[key]
+	if !ok {
+		a = &entity.LoginAttempts{Key: key}
+		r.attempts[key] = a
+	}
+	if a.Updated.Before(now.Add(-window)) {
+		a.Failures = 0
+	}
+	a.Failures++
+	a.Updated = now
+
+	return a.Failures, nil
+}
+
+func (r *MemoryLoginAttempts) Lock(ctx context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.attempts[key]
+	if ok && (a.LockedUntil == nil || until.After(*a.LockedUntil)) {
+		a.LockedUntil = &until
+	}
+
+	return nil
+}
+
+func (r *MemoryLoginAttempts) Reset(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+	return nil
+}
+
+// DeleteExpired удаляет незаблокированные записи без неудач дольше window.
+func (r *MemoryLoginAttempts) DeleteExpired(ctx context.Context, window time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	for key, a := range r.attempts {
+		locked := a.LockedUntil != nil && a.LockedUntil.After(now)
+		if !locked && a.Updated.Before(now.Add(-window)) {
+			delete(r.attempts, key)
+		}
+	}
+
+	return nil
+}
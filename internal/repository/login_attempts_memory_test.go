@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLoginAttempts(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 10, 6, 10, 0, 0, 0, time.UTC)
+	window := time.Hour
+
+	r := NewMemoryLoginAttempts()
+	r.now = func() time.Time { return now }
+
+	for want := 1; want <= 3; want++ {
+		failures, err := r.Fail(ctx, "login:testLogin", window)
+		require.Nil(t, err, "Register failure")
+		assert.Equal(t, want, failures, "Failures counter")
+	}
+
+	until, err := r.LockedUntil(ctx, []string{"login:testLogin", "ip:192.0.2.1"})
+	require.Nil(t, err, "Check lock")
+	assert.True(t, until.IsZero(), "Not locked yet")
+
+	require.Nil(t, r.Lock(ctx, "login:testLogin", now.Add(time.Minute)), "Lock")
+	require.Nil(t, r.Lock(ctx, "login:testLogin", now.Add(time.Second)), "Shorter lock")
+	until, err = r.LockedUntil(ctx, []string{"login:testLogin", "ip:192.0.2.1"})
+	require.Nil(t, err, "Check lock")
+	assert.Equal(t, now.Add(time.Minute), until, "Longest lock wins")
+
+	now = now.Add(2 * window)
+	failures, err := r.Fail(ctx, "login:testLogin", window)
+	require.Nil(t, err, "Register failure after window")
+	assert.Equal(t, 1, failures, "Counter restarted after window")
+
+	require.Nil(t, r.Reset(ctx, "login:testLogin"), "Reset")
+	until, err = r.LockedUntil(ctx, []string{"login:testLogin"})
+	require.Nil(t, err, "Check lock")
+	assert.True(t, until.IsZero(), "Lock removed by reset")
+
+	for _, key := range []string{"login:testLogin", "ip:192.0.2.1"} {
+		_, err = r.Fail(ctx, key, window)
+		require.Nil(t, err, "Register failure")
+	}
+	require.Nil(t, r.Lock(ctx, "ip:192.0.2.1", now.Add(3*window)), "Lock address")
+	now = now.Add(2 * window)
+	require.Nil(t, r.DeleteExpired(ctx, window), "Delete expired")
+	assert.NotContains(t, r.attempts, "login:testLogin", "Stale counter deleted")
+	assert.Contains(t, r.attempts, "ip:192.0.2.1", "Locked counter kept")
+}
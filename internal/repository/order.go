@@ -36,10 +36,33 @@ func (w *Order) GetByNumber(ctx context.Context, number string) (order entity.Or
 	return order, nil
 }
 
-func (w *Order) GetAllByUser(ctx context.Context, userID uint64) (orders []entity.Order, err error) {
-	query := `SELECT number, user_id, status, accrual, uploaded_at, updated_at FROM orders WHERE user_id = $1`
+// ListByUser возвращает страницу заказов пользователя, упорядоченных
+// по времени загрузки и номеру, начиная с позиции после f.After.
+func (w *Order) ListByUser(ctx context.Context, userID uint64, f entity.OrderFilter) (orders []entity.Order, err error) {
+	var args queryArgs
+	cmp, dir := listOrder(f.Ascending)
+
+	conds := []string{"user_id = " + args.add(userID)}
+	if len(f.Statuses) > 0 {
+		conds = append(conds, "status = ANY("+args.add(f.Statuses)+")")
+	}
+	if !f.From.IsZero() {
+		conds = append(conds, "uploaded_at >= "+args.add(f.From))
+	}
+	if !f.To.IsZero() {
+		conds = append(conds, "uploaded_at < "+args.add(f.To))
+	}
+	if f.After != nil {
+		conds = append(conds, fmt.Sprintf(
+			"(uploaded_at, number) %s (%s, %s)", cmp, args.add(f.After.Uploaded), args.add(f.After.Number),
+		))
+	}
+
+	query := `SELECT number, user_id, status, accrual, uploaded_at, updated_at FROM orders` +
+		where(conds) +
+		fmt.Sprintf(" ORDER BY uploaded_at %[1]s, number %[1]s LIMIT %s", dir, args.add(f.Limit))
 
-	rows, err := w.pool.Query(ctx, query, userID)
+	rows, err := w.pool.Query(ctx, query, args...)
 	if err != nil {
 		return orders, fmt.Errorf("failed to select from orders: %w", err)
 	}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository/pg"
+)
+
+type Outbox struct {
+	pool *pgxpool.Pool
+}
+
+func NewOutbox(db *pg.DB) *Outbox {
+	return &Outbox{pool: db.Pool()}
+}
+
+// ClaimEvents выбирает первые неопубликованные события в порядке записи и откладывает их на lease,
+// после окончания аренды неподтвержденное событие будет опубликовано повторно.
+// Пока самое старое неопубликованное событие арендовано или отложено после ошибки,
+// ничего не выдается, поэтому более поздние события его не обгоняют. Выборку
+// в каждый момент делает один экземпляр, остальные ждут advisory lock до конца транзакции.
+// attempts считает только неудачные публикации, аренда его не меняет.
+func (r *Outbox) ClaimEvents(ctx context.Context, limit int, lease time.Duration) ([]entity.OutboxEvent, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('outbox_claim'))`); err != nil {
+		return nil, fmt.Errorf("failed to lock outbox claim: %w", err)
+	}
+
+	query := `UPDATE outbox
+				SET next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+				WHERE id IN (
+					SELECT id FROM outbox
+					WHERE published_at IS NULL
+					ORDER BY id
+					LIMIT $1
+				) AND (
+					SELECT next_attempt_at <= NOW() FROM outbox
+					WHERE published_at IS NULL
+					ORDER BY id
+					LIMIT 1
+				)
+				RETURNING id, event_id::text AS event_id, type, payload, attempts, created_at`
+
+	rows, err := tx.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+
+	list, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.OutboxEvent])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse claimed outbox events: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// RETURNING не сохраняет порядок подзапроса.
+	slices.SortFunc(list, func(a, b entity.OutboxEvent) int { return cmp.Compare(a.ID, b.ID) })
+
+	return list, nil
+}
+
+func (r *Outbox) MarkPublished(ctx context.Context, id uint64) error {
+	query := `UPDATE outbox SET published_at = NOW(), last_error = NULL WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to mark outbox event#%d published: %w", id, err)
+	}
+
+	return nil
+}
+
+// Retry засчитывает неудачную публикацию.
+func (r *Outbox) Retry(ctx context.Context, id uint64, reason string, at time.Time) error {
+	query := `UPDATE outbox SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3 WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id, at, reason); err != nil {
+		return fmt.Errorf("failed to reschedule outbox event#%d: %w", id, err)
+	}
+
+	return nil
+}
+
+// Release снимает аренду с события, которое не публиковалось.
+func (r *Outbox) Release(ctx context.Context, id uint64, at time.Time) error {
+	query := `UPDATE outbox SET next_attempt_at = $2 WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id, at); err != nil {
+		return fmt.Errorf("failed to release outbox event#%d: %w", id, err)
+	}
+
+	return nil
+}
+
+// addOutboxEvent вызывается в транзакции, изменения которой описывает событие.
+func addOutboxEvent(ctx context.Context, db execer, eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	_, err = db.Exec(ctx, `INSERT INTO outbox (type, payload) VALUES($1, $2)`, eventType, data)
+	if err != nil {
+		return fmt.Errorf("failed to insert into outbox: %w", err)
+	}
+
+	return nil
+}
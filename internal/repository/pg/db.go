@@ -7,12 +7,14 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 
+	"github.com/exaring/otelpgx"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type DB struct {
-	pool *pgxpool.Pool
+	pool      *pgxpool.Pool
+	migration uint
 }
 
 func NewDB(ctx context.Context, dsn string) (*DB, error) {
@@ -25,8 +27,18 @@ func NewDB(ctx context.Context, dsn string) (*DB, error) {
 	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
 		return db, fmt.Errorf("failed to apply migrations to the DB: %w", err)
 	}
+	db.migration, _, err = m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return db, fmt.Errorf("failed to get migrations version: %w", err)
+	}
+
+	cfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return db, fmt.Errorf("failed to parse DB config: %w", err)
+	}
+	cfg.ConnConfig.Tracer = otelpgx.NewTracer()
 
-	db.pool, err = pgxpool.New(ctx, dsn)
+	db.pool, err = pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
 		return db, fmt.Errorf("failed to create DB a connection pool: %w", err)
 	}
@@ -38,6 +50,11 @@ func (db *DB) Pool() *pgxpool.Pool {
 	return db.pool
 }
 
+// Migration версия схемы, до которой сервис применил миграции при старте.
+func (db *DB) Migration() uint {
+	return db.migration
+}
+
 func (db *DB) Close() {
 	db.pool.Close()
 }
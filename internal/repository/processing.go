@@ -3,7 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
-	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -15,39 +15,70 @@ import (
 
 type Processing struct {
 	pool    *pgxpool.Pool
-	delay   uint64
-	retries uint64
+	backoff entity.Backoff
 }
 
-func NewProcessing(db *pg.DB, delaySecond uint64, retryCount uint64) *Processing {
+func NewProcessing(db *pg.DB, backoff entity.Backoff) *Processing {
 	return &Processing{
 		pool:    db.Pool(),
-		delay:   delaySecond,
-		retries: retryCount,
+		backoff: backoff,
 	}
 }
 
-func (p *Processing) OrderNubmersForProcess(ctx context.Context, statuses []string) ([]string, error) {
-	var numbers = []string{}
-	pgStatuses := strings.Join(statuses, "', '")
-	query := `UPDATE orders SET updated_at = NOW()
-				WHERE status IN('%s') AND updated_at < NOW() - (attempts+1)*INTERVAL '%d seconds'
+// ClaimOrdersForProcess выдает заказы в аренду lease.Owner. Заказы, арендованные другими
+// экземплярами, пропускаются, поэтому несколько экземпляров не опрашивают один заказ.
+// Новый заказ впервые опрашивается через backoff.Base после загрузки.
+func (p *Processing) ClaimOrdersForProcess(
+	ctx context.Context,
+	statuses []string,
+	lease entity.OrderLease,
+) ([]string, error) {
+	query := `UPDATE orders
+				SET updated_at = NOW(), lease_owner = $3, lease_expires_at = NOW() + $5 * INTERVAL '1 second'
+				WHERE number IN (
+					SELECT number FROM orders
+					WHERE status = ANY($1)
+						AND COALESCE(next_attempt_at, uploaded_at + $2 * INTERVAL '1 second') <= NOW()
+						AND (lease_expires_at IS NULL OR lease_expires_at < NOW())
+					ORDER BY COALESCE(next_attempt_at, uploaded_at)
+					LIMIT $4
+					FOR UPDATE SKIP LOCKED
+				)
 				RETURNING number`
-	query = fmt.Sprintf(query, pgStatuses, p.delay)
 
-	rows, err := p.pool.Query(ctx, query)
+	rows, err := p.pool.Query(
+		ctx,
+		query,
+		statuses,
+		p.backoff.Base.Seconds(),
+		lease.Owner,
+		lease.Limit,
+		lease.Duration.Seconds(),
+	)
 	if err != nil {
-		return numbers, fmt.Errorf("failed to select orders numbers for process: %w", err)
+		return nil, fmt.Errorf("failed to claim orders for process: %w", err)
 	}
 
-	numbers, err = pgx.CollectRows(rows, pgx.RowTo[string])
+	numbers, err := pgx.CollectRows(rows, pgx.RowTo[string])
 	if err != nil {
-		return numbers, fmt.Errorf("failed to parse selected orders: %w", err)
+		return nil, fmt.Errorf("failed to parse claimed orders: %w", err)
 	}
 
 	return numbers, nil
 }
 
+// ReleaseOrders возвращает в очередь заказы, которые экземпляр не успел обработать.
+func (p *Processing) ReleaseOrders(ctx context.Context, owner string, numbers []string) error {
+	query := `UPDATE orders SET lease_owner = NULL, lease_expires_at = NULL
+				WHERE number = ANY($1) AND lease_owner = $2`
+
+	if _, err := p.pool.Exec(ctx, query, numbers, owner); err != nil {
+		return fmt.Errorf("failed to release orders: %w", err)
+	}
+
+	return nil
+}
+
 func (p *Processing) ProcessOrder(ctx context.Context, order entity.Order) error {
 	tx, err := p.pool.Begin(ctx)
 	if err != nil {
@@ -55,15 +86,41 @@ func (p *Processing) ProcessOrder(ctx context.Context, order entity.Order) error
 	}
 	defer tx.Rollback(ctx)
 
-	userID, err := updateOrderForProcess(ctx, tx, order)
+	userID, prevStatus, err := updateOrderForProcess(ctx, tx, order, p.backoff.Base)
 	if err != nil {
 		return err
 	}
 
-	if order.Status == entity.OrderStatusProcessed {
-		err = increaseBalance(ctx, tx, order.Accrual, userID)
+	if order.Status == entity.OrderStatusProcessed && order.Accrual > 0 {
+		balance, err := increaseBalance(ctx, tx, order, userID)
 		if err != nil {
-			return nil
+			return err
+		}
+
+		err = addOutboxEvent(ctx, tx, entity.OutboxBalanceAccrued, entity.BalanceAccrued{
+			UserID:  userID,
+			Number:  order.Number,
+			Amount:  order.Accrual,
+			Balance: balance.Balance,
+			Debited: balance.Debited,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = notifyUser(ctx, tx, entity.UserEvent{
+			UserID:  userID,
+			Type:    entity.UserEventBalance,
+			Balance: &entity.BalanceEvent{Balance: balance.Balance, Debited: balance.Debited},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if order.Status != prevStatus {
+		if err := orderStatusChanged(ctx, tx, userID, prevStatus, order); err != nil {
+			return err
 		}
 	}
 
@@ -74,63 +131,155 @@ func (p *Processing) ProcessOrder(ctx context.Context, order entity.Order) error
 	return nil
 }
 
-func updateOrderForProcess(ctx context.Context, tx pgx.Tx, o entity.Order) (userID uint64, err error) {
-	query := `UPDATE orders SET status = $1, accrual = $2, updated_at = NOW(), attempts = 0
-				WHERE number = $3 RETURNING user_id`
+func updateOrderForProcess(
+	ctx context.Context,
+	tx pgx.Tx,
+	o entity.Order,
+	delay time.Duration,
+) (userID uint64, prevStatus string, err error) {
+	// Финальные статусы не меняются, например если заказ стал INVALID при удалении аккаунта
+	query := `UPDATE orders o SET status = $1, accrual = $2, updated_at = NOW(), attempts = 0,
+					next_attempt_at = NOW() + $6 * INTERVAL '1 second', last_error = NULL,
+					lease_owner = NULL, lease_expires_at = NULL
+				FROM (SELECT number, status FROM orders WHERE number = $3 FOR UPDATE) prev
+				WHERE o.number = prev.number AND o.status IN ($4, $5)
+				RETURNING o.user_id, prev.status`
+
+	err = tx.QueryRow(
+		ctx,
+		query,
+		o.Status,
+		o.Accrual,
+		o.Number,
+		entity.OrderStatusNew,
+		entity.OrderStatusProcessing,
+		delay.Seconds(),
+	).Scan(&userID, &prevStatus)
+	if err != nil {
+		return userID, prevStatus, fmt.Errorf("failed to update order#%s : %w", o.Number, errors.Trasform(err))
+	}
+
+	return userID, prevStatus, nil
+}
 
-	rows, err := tx.Query(ctx, query, o.Status, o.Accrual, o.Number)
+func increaseBalance(
+	ctx context.Context,
+	tx pgx.Tx,
+	o entity.Order,
+	userID uint64,
+) (balance entity.Balance, err error) {
+	_, err = tx.Exec(ctx, `LOCK TABLE balance IN ROW EXCLUSIVE MODE`)
 	if err != nil {
-		return userID, fmt.Errorf("failed to update order#%s : %w", o.Number, err)
+		return balance, fmt.Errorf("failed to lock balance table: %w", err)
 	}
 
-	userID, err = pgx.CollectOneRow(rows, pgx.RowTo[uint64])
+	query := `UPDATE balance SET balance = balance + $1 WHERE user_id = $2
+				RETURNING user_id, balance, debited`
+	err = tx.QueryRow(ctx, query, o.Accrual, userID).Scan(&balance.UserID, &balance.Balance, &balance.Debited)
 	if err != nil {
-		return userID, fmt.Errorf("failed to parse user_id from order#%s : %w", o.Number, err)
+		err = errors.Trasform(err)
+		if err == errors.ErrNotFound {
+			err = errors.ErrNoRowsUpdated
+		}
+		return balance, fmt.Errorf("failed to update balance: %w", err)
 	}
 
-	return userID, err
+	return balance, addLedgerEntry(ctx, tx, entity.LedgerEntry{
+		UserID:      userID,
+		Kind:        entity.LedgerKindAccrual,
+		Amount:      o.Accrual,
+		OrderNumber: o.Number,
+	})
 }
 
-func increaseBalance(ctx context.Context, tx pgx.Tx, accrual float64, userID uint64) error {
-	_, err := tx.Exec(ctx, `LOCK TABLE balance IN ROW EXCLUSIVE MODE`)
+// MarkOrderForRetry откладывает следующий опрос заказа по backoff, а после f.Retries
+// неудачных попыток переводит заказ в f.FinalStatus. Попытки считаются вместе для всех видов неудач.
+func (p *Processing) MarkOrderForRetry(ctx context.Context, number string, f entity.OrderFailure) error {
+	tx, err := p.pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to lock balance table: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback(ctx)
 
-	query := `UPDATE balance SET balance = balance + $1 WHERE user_id = $2`
-	tag, err := tx.Exec(ctx, query, accrual, userID)
+	var (
+		order      = entity.Order{Number: number}
+		userID     uint64
+		prevStatus string
+	)
+	// Степень ограничена, чтобы POWER не переполнился при большом числе попыток
+	query := `UPDATE orders o
+				SET
+					status = CASE WHEN o.attempts < $1 THEN o.status ELSE $2 END,
+					attempts = o.attempts + 1,
+					next_attempt_at = NOW() + LEAST($7 * POWER(2, LEAST(o.attempts, 30)), $8)
+						* (1 - $9 * random()) * INTERVAL '1 second',
+					last_error = $3,
+					updated_at = NOW(),
+					lease_owner = NULL,
+					lease_expires_at = NULL
+				FROM (SELECT number, status FROM orders WHERE number = $4 FOR UPDATE) prev
+				WHERE o.number = prev.number AND o.status IN ($5, $6)
+				RETURNING o.user_id, o.status, prev.status`
+
+	err = tx.QueryRow(
+		ctx,
+		query,
+		f.Retries,
+		f.FinalStatus,
+		f.Reason,
+		number,
+		entity.OrderStatusNew,
+		entity.OrderStatusProcessing,
+		p.backoff.Base.Seconds(),
+		p.backoff.Max.Seconds(),
+		p.backoff.Jitter,
+	).Scan(&userID, &order.Status, &prevStatus)
 	if err != nil {
-		return fmt.Errorf("failed to update balance: %w", err)
+		// Заказ уже в конечном статусе, например после удаления аккаунта.
+		if err = errors.Trasform(err); err == errors.ErrNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to update order#%s : %w", number, err)
+	}
+
+	if order.Status != prevStatus {
+		if err := orderStatusChanged(ctx, tx, userID, prevStatus, order); err != nil {
+			return err
+		}
 	}
 
-	if tag.RowsAffected() == 0 {
-		return fmt.Errorf("failed to update balance: %w", errors.ErrNoRowsUpdated)
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
 }
 
-func (p *Processing) MarkOrderForRetryOrInvalid(
-	ctx context.Context,
-	number string,
-	toStatus string,
-	invalidStatus string,
-) error {
-	query := `UPDATE orders 
-				SET 
-					status = CASE WHEN attempts < $1 THEN $2 ELSE $3 END,
-					attempts = attempts +1,
-					updated_at = NOW()
-				WHERE number = $4`
-
-	tag, err := p.pool.Exec(ctx, query, p.retries, toStatus, invalidStatus, number)
+// orderStatusChanged сообщает о новом статусе заказа в outbox, вебхукам и подписчикам /api/user/events.
+// Переход в FAILED пользователю не виден, о нем сообщается только в outbox.
+func orderStatusChanged(ctx context.Context, tx pgx.Tx, userID uint64, prevStatus string, o entity.Order) error {
+	err := addOutboxEvent(ctx, tx, entity.OutboxOrderStatusChanged, entity.OrderStatusChanged{
+		UserID:     userID,
+		Number:     o.Number,
+		Status:     o.Status,
+		PrevStatus: prevStatus,
+		Accrual:    o.Accrual,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to update order#%s : %w", number, err)
+		return err
 	}
 
-	if tag.RowsAffected() != 1 {
-		return fmt.Errorf("failed to update order#%s : %w", number, errors.ErrNoRowsUpdated)
+	if o.Status == entity.OrderStatusFailed {
+		return nil
 	}
 
-	return nil
+	if err := addOrderEvent(ctx, tx, userID, o); err != nil {
+		return err
+	}
+
+	return notifyUser(ctx, tx, entity.UserEvent{
+		UserID: userID,
+		Type:   entity.UserEventOrder,
+		Order:  &entity.OrderEvent{Number: o.Number, Status: o.Status, Accrual: o.Accrual},
+	})
 }
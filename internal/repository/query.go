@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+)
+
+// queryArgs собирает аргументы запроса с динамическим набором условий.
+type queryArgs []any
+
+// add добавляет аргумент и возвращает его плейсхолдер.
+func (a *queryArgs) add(v any) string {
+	*a = append(*a, v)
+	return "$" + strconv.Itoa(len(*a))
+}
+
+// listOrder возвращает оператор сравнения курсора и направление сортировки.
+func listOrder(ascending bool) (cmp, dir string) {
+	if ascending {
+		return ">", "ASC"
+	}
+	return "<", "DESC"
+}
+
+func where(conds []string) string {
+	return " WHERE " + strings.Join(conds, " AND ")
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository/errors"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository/pg"
+)
+
+type Token struct {
+	pool *pgxpool.Pool
+}
+
+func NewToken(db *pg.DB) *Token {
+	return &Token{pool: db.Pool()}
+}
+
+func (r *Token) CreateRefresh(ctx context.Context, t entity.RefreshToken) error {
+	query := `DELETE FROM refresh_tokens WHERE user_id = $1 AND expires_at < NOW()`
+	_, err := r.pool.Exec(ctx, query, t.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired refresh tokens: %w", err)
+	}
+
+	query = `INSERT INTO refresh_tokens (user_id, hash, expires_at) VALUES($1, $2, $3)`
+	_, err = r.pool.Exec(ctx, query, t.UserID, t.Hash, t.Expires)
+	if err != nil {
+		return fmt.Errorf("failed to insert into refresh_tokens: %w", errors.Trasform(err))
+	}
+
+	return nil
+}
+
+func (r *Token) FindRefresh(ctx context.Context, hash string) (entity.RefreshToken, error) {
+	query := `SELECT id, user_id, hash, expires_at, revoked_at, created_at
+				FROM refresh_tokens WHERE hash = $1`
+	rows, err := r.pool.Query(ctx, query, hash)
+	if err != nil {
+		return entity.RefreshToken{}, fmt.Errorf("failed to select from refresh_tokens: %w", err)
+	}
+
+	token, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.RefreshToken])
+	if err != nil {
+		return token, fmt.Errorf("failed to parse refresh token: %w", errors.Trasform(err))
+	}
+
+	return token, nil
+}
+
+// RotateRefresh отзывает использованный токен и сохраняет новый.
+// Если использованный токен уже отозван, возвращает ErrNoRowsUpdated.
+func (r *Token) RotateRefresh(ctx context.Context, usedID uint64, next entity.RefreshToken) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	tag, err := tx.Exec(ctx, query, usedID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to revoke refresh token: %w", errors.ErrNoRowsUpdated)
+	}
+
+	query = `INSERT INTO refresh_tokens (user_id, hash, expires_at) VALUES($1, $2, $3)`
+	_, err = tx.Exec(ctx, query, next.UserID, next.Hash, next.Expires)
+	if err != nil {
+		return fmt.Errorf("failed to insert into refresh_tokens: %w", errors.Trasform(err))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Token) RevokeRefresh(ctx context.Context, userID uint64, hash string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW()
+				WHERE user_id = $1 AND hash = $2 AND revoked_at IS NULL`
+
+	_, err := r.pool.Exec(ctx, query, userID, hash)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Token) RevokeAllRefresh(ctx context.Context, userID uint64) error {
+	return revokeUserRefreshTokens(ctx, r.pool, userID)
+}
+
+// execer общая часть pgxpool.Pool и pgx.Tx.
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+func revokeUserRefreshTokens(ctx context.Context, db execer, userID uint64) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+
+	_, err := db.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke user refresh tokens: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Token) RevokeAccess(ctx context.Context, jti string, userID uint64, expires time.Time) error {
+	query := `DELETE FROM revoked_access_tokens WHERE expires_at < NOW()`
+	_, err := r.pool.Exec(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired revoked access tokens: %w", err)
+	}
+
+	query = `INSERT INTO revoked_access_tokens (jti, user_id, expires_at) VALUES($1, $2, $3)
+				ON CONFLICT (jti) DO NOTHING`
+	_, err = r.pool.Exec(ctx, query, jti, userID, expires)
+	if err != nil {
+		return fmt.Errorf("failed to insert into revoked_access_tokens: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Token) IsAccessRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+	query := `SELECT EXISTS(SELECT 1 FROM revoked_access_tokens WHERE jti = $1)`
+
+	err := r.pool.QueryRow(ctx, query, jti).Scan(&revoked)
+	if err != nil {
+		return false, fmt.Errorf("failed to check access token revocation: %w", err)
+	}
+
+	return revoked, nil
+}
@@ -7,10 +7,16 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository/errors"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository/pg"
 )
 
+// tokensRevokedAt граница отзыва токенов. iat в JWT хранится с точностью до секунды,
+// поэтому граница тоже округляется вниз, иначе токен, выданный сразу после отзыва,
+// например при входе с новым паролем, оказался бы отозван.
+const tokensRevokedAt = `date_trunc('second', NOW())`
+
 type User struct {
 	pool *pgxpool.Pool
 }
@@ -21,10 +27,11 @@ func NewUser(db *pg.DB) *User {
 
 func (u *User) GetByID(ctx context.Context, id uint64) (entity.User, error) {
 	var user entity.User
-	query := `SELECT id, login, hash, created_at FROM users WHERE id = $1`
+	query := `SELECT id, login, hash, role, created_at, tokens_valid_after
+				FROM users WHERE id = $1 AND deleted_at IS NULL`
 	row := u.pool.QueryRow(ctx, query, id)
 
-	err := row.Scan(&user.ID, &user.Login, &user.Hash, &user.Created)
+	err := row.Scan(&user.ID, &user.Login, &user.Hash, &user.Role, &user.Created, &user.TokensValidAfter)
 	if err != nil {
 		return entity.User{}, errors.Trasform(err)
 	}
@@ -34,10 +41,11 @@ func (u *User) GetByID(ctx context.Context, id uint64) (entity.User, error) {
 
 func (u *User) FindByLogin(ctx context.Context, login string) (entity.User, error) {
 	var user entity.User
-	query := `SELECT id, login, hash, created_at FROM users WHERE login = $1`
+	query := `SELECT id, login, hash, role, created_at, tokens_valid_after
+				FROM users WHERE login = $1 AND deleted_at IS NULL`
 	row := u.pool.QueryRow(ctx, query, login)
 
-	err := row.Scan(&user.ID, &user.Login, &user.Hash, &user.Created)
+	err := row.Scan(&user.ID, &user.Login, &user.Hash, &user.Role, &user.Created, &user.TokensValidAfter)
 	if err != nil {
 		return entity.User{}, errors.Trasform(err)
 	}
@@ -52,9 +60,9 @@ func (u *User) Create(ctx context.Context, user entity.User) (entity.User, error
 	}
 	defer tx.Rollback(ctx)
 
-	query := `INSERT INTO users (login, hash) VALUES($1, $2) RETURNING id, created_at`
+	query := `INSERT INTO users (login, hash) VALUES($1, $2) RETURNING id, role, created_at`
 	row := tx.QueryRow(ctx, query, user.Login, user.Hash)
-	err = row.Scan(&user.ID, &user.Created)
+	err = row.Scan(&user.ID, &user.Role, &user.Created)
 	if err != nil {
 		return user, fmt.Errorf("failed to insert to users: %w", errors.Trasform(err))
 	}
@@ -71,3 +79,138 @@ func (u *User) Create(ctx context.Context, user entity.User) (entity.User, error
 
 	return user, nil
 }
+
+func (u *User) UpdateHash(ctx context.Context, id uint64, hash string) error {
+	query := `UPDATE users SET hash = $2 WHERE id = $1`
+
+	tag, err := u.pool.Exec(ctx, query, id, hash)
+	if err != nil {
+		return fmt.Errorf("failed to update user hash: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to update user hash: %w", errors.ErrNoRowsUpdated)
+	}
+
+	return nil
+}
+
+// ChangePassword сохраняет новый хеш и отзывает все выданные пользователю токены.
+func (u *User) ChangePassword(ctx context.Context, id uint64, hash string) error {
+	tx, err := u.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE users SET hash = $2, tokens_valid_after = ` + tokensRevokedAt + `
+				WHERE id = $1 AND deleted_at IS NULL`
+	tag, err := tx.Exec(ctx, query, id, hash)
+	if err != nil {
+		return fmt.Errorf("failed to update user password: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to update user password: %w", errors.ErrNotFound)
+	}
+
+	if err := revokeUserRefreshTokens(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Delete закрывает аккаунт. Строка пользователя остается, потому что на нее
+// ссылаются заказы, списания и журнал, но логин и хеш пароля затираются.
+// Логин становится NULL, поэтому не пересекается с логинами других пользователей.
+// Остаток баланса списывается с уведомлением подписчиков, необработанные и FAILED заказы становятся INVALID.
+func (u *User) Delete(ctx context.Context, id uint64) (forfeited money.Amount, err error) {
+	tx, err := u.pool.Begin(ctx)
+	if err != nil {
+		return forfeited, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE users
+				SET login = NULL, hash = '', deleted_at = NOW(), tokens_valid_after = ` + tokensRevokedAt + `
+				WHERE id = $1 AND deleted_at IS NULL`
+	tag, err := tx.Exec(ctx, query, id)
+	if err != nil {
+		return forfeited, fmt.Errorf("failed to anonymize user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return forfeited, fmt.Errorf("failed to anonymize user: %w", errors.ErrNotFound)
+	}
+
+	var debited money.Amount
+	query = `SELECT balance, debited FROM balance WHERE user_id = $1 FOR UPDATE`
+	err = tx.QueryRow(ctx, query, id).Scan(&forfeited, &debited)
+	if err != nil {
+		return forfeited, fmt.Errorf("failed to select user balance: %w", errors.Trasform(err))
+	}
+
+	if forfeited != 0 {
+		query = `UPDATE balance SET balance = 0 WHERE user_id = $1`
+		if _, err = tx.Exec(ctx, query, id); err != nil {
+			return forfeited, fmt.Errorf("failed to reset user balance: %w", err)
+		}
+
+		err = addLedgerEntry(ctx, tx, entity.LedgerEntry{
+			UserID: id,
+			Kind:   entity.LedgerKindForfeit,
+			Amount: -forfeited,
+		})
+		if err != nil {
+			return forfeited, err
+		}
+
+		err = notifyUser(ctx, tx, entity.UserEvent{
+			UserID:  id,
+			Type:    entity.UserEventBalance,
+			Balance: &entity.BalanceEvent{Balance: 0, Debited: debited},
+		})
+		if err != nil {
+			return forfeited, err
+		}
+	}
+
+	// FAILED тоже закрывается, иначе администратор мог бы вернуть заказ в очередь
+	// и начислить баллы закрытому аккаунту.
+	query = `UPDATE orders SET status = $2, updated_at = NOW()
+				WHERE user_id = $1 AND status IN ($3, $4, $5)`
+	_, err = tx.Exec(
+		ctx,
+		query,
+		id,
+		entity.OrderStatusInvalid,
+		entity.OrderStatusNew,
+		entity.OrderStatusProcessing,
+		entity.OrderStatusFailed,
+	)
+	if err != nil {
+		return forfeited, fmt.Errorf("failed to invalidate user orders: %w", err)
+	}
+
+	query = `DELETE FROM webhooks WHERE user_id = $1`
+	if _, err = tx.Exec(ctx, query, id); err != nil {
+		return forfeited, fmt.Errorf("failed to delete user webhooks: %w", err)
+	}
+
+	query = `DELETE FROM idempotency_keys WHERE user_id = $1`
+	if _, err = tx.Exec(ctx, query, id); err != nil {
+		return forfeited, fmt.Errorf("failed to delete user idempotency keys: %w", err)
+	}
+
+	if err := revokeUserRefreshTokens(ctx, tx, id); err != nil {
+		return forfeited, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return forfeited, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return forfeited, nil
+}
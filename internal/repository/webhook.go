@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository/errors"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/repository/pg"
+)
+
+type Webhook struct {
+	pool *pgxpool.Pool
+}
+
+func NewWebhook(db *pg.DB) *Webhook {
+	return &Webhook{pool: db.Pool()}
+}
+
+// Create сохраняет вебхук, если у пользователя их меньше maxPerUser,
+// иначе возвращает ErrNoRowsUpdated.
+func (r *Webhook) Create(ctx context.Context, w entity.Webhook, maxPerUser int) (entity.Webhook, error) {
+	query := `INSERT INTO webhooks (user_id, url, secret)
+				SELECT $1, $2, $3 WHERE (SELECT COUNT(*) FROM webhooks WHERE user_id = $1) < $4
+				RETURNING id, user_id, url, secret, created_at`
+
+	rows, err := r.pool.Query(ctx, query, w.UserID, w.URL, w.Secret, maxPerUser)
+	if err != nil {
+		return w, fmt.Errorf("failed to insert into webhooks: %w", err)
+	}
+
+	created, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[entity.Webhook])
+	if err != nil {
+		err = errors.Trasform(err)
+		if err == errors.ErrNotFound {
+			err = errors.ErrNoRowsUpdated
+		}
+		return w, fmt.Errorf("failed to insert into webhooks: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *Webhook) ListByUser(ctx context.Context, userID uint64) ([]entity.Webhook, error) {
+	query := `SELECT id, user_id, url, secret, created_at FROM webhooks WHERE user_id = $1 ORDER BY id`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select from webhooks: %w", err)
+	}
+
+	list, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.Webhook])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse selected webhooks: %w", err)
+	}
+
+	return list, nil
+}
+
+// Delete удаляет вебхук вместе с недоставленными событиями.
+func (r *Webhook) Delete(ctx context.Context, userID, id uint64) error {
+	query := `DELETE FROM webhooks WHERE id = $1 AND user_id = $2`
+
+	tag, err := r.pool.Exec(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to delete webhook#%d: %w", id, errors.ErrNotFound)
+	}
+
+	return nil
+}
+
+// ClaimDeliveries выбирает готовые к отправке события и откладывает их на lease.
+// Если процесс упадет, не успев отчитаться о доставке, событие будет отправлено
+// повторно после окончания аренды, поэтому доставка выполняется хотя бы один раз.
+// attempts считает только неудачные отправки, аренда его не меняет.
+func (r *Webhook) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	query := `UPDATE webhook_deliveries d
+				SET next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+				FROM webhooks w
+				WHERE w.id = d.webhook_id AND d.id IN (
+					SELECT id FROM webhook_deliveries
+					WHERE delivered_at IS NULL AND failed_at IS NULL AND next_attempt_at <= NOW()
+					ORDER BY next_attempt_at
+					LIMIT $1
+					FOR UPDATE SKIP LOCKED
+				)
+				RETURNING d.id, d.event_id::text AS event_id, d.order_num, d.status, d.accrual,
+					d.attempts, d.created_at, w.url, w.secret`
+
+	rows, err := r.pool.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	list, err := pgx.CollectRows(rows, pgx.RowToStructByName[entity.WebhookDelivery])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse claimed webhook deliveries: %w", err)
+	}
+
+	return list, nil
+}
+
+func (r *Webhook) MarkDelivered(ctx context.Context, id uint64) error {
+	query := `UPDATE webhook_deliveries SET delivered_at = NOW(), last_error = NULL WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to mark webhook delivery#%d delivered: %w", id, err)
+	}
+
+	return nil
+}
+
+// Retry засчитывает неудачную отправку.
+func (r *Webhook) Retry(ctx context.Context, id uint64, reason string, at time.Time) error {
+	query := `UPDATE webhook_deliveries
+				SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3
+				WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id, at, reason); err != nil {
+		return fmt.Errorf("failed to reschedule webhook delivery#%d: %w", id, err)
+	}
+
+	return nil
+}
+
+// Release снимает аренду с события, которое не отправлялось.
+func (r *Webhook) Release(ctx context.Context, id uint64, at time.Time) error {
+	query := `UPDATE webhook_deliveries SET next_attempt_at = $2 WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id, at); err != nil {
+		return fmt.Errorf("failed to release webhook delivery#%d: %w", id, err)
+	}
+
+	return nil
+}
+
+func (r *Webhook) Fail(ctx context.Context, id uint64, reason string) error {
+	query := `UPDATE webhook_deliveries
+				SET attempts = attempts + 1, failed_at = NOW(), last_error = $2
+				WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id, reason); err != nil {
+		return fmt.Errorf("failed to mark webhook delivery#%d failed: %w", id, err)
+	}
+
+	return nil
+}
+
+// addOrderEvent ставит событие в очередь доставки на каждый вебхук пользователя.
+// Вызывается в транзакции изменения статуса, поэтому событие не теряется
+// и не появляется без самого изменения.
+func addOrderEvent(ctx context.Context, tx pgx.Tx, userID uint64, o entity.Order) error {
+	query := `INSERT INTO webhook_deliveries (webhook_id, event_id, order_num, status, accrual)
+				SELECT w.id, e.id, $2, $3, $4
+				FROM webhooks w CROSS JOIN (SELECT gen_random_uuid() AS id) e
+				WHERE w.user_id = $1`
+
+	_, err := tx.Exec(ctx, query, userID, o.Number, o.Status, o.Accrual)
+	if err != nil {
+		return fmt.Errorf("failed to insert into webhook_deliveries: %w", err)
+	}
+
+	return nil
+}
@@ -19,7 +19,9 @@ func NewWithdrawals(db *pg.DB) *Withdrawals {
 	return &Withdrawals{pool: db.Pool()}
 }
 
-func (r *Withdrawals) Create(ctx context.Context, w entity.Withdrawals) error {
+// Create списывает баллы в счет оплаты заказа. Один заказ может быть оплачен
+// не более чем maxPayments частичными списаниями одного пользователя.
+func (r *Withdrawals) Create(ctx context.Context, w entity.Withdrawals, maxPayments uint64) error {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -31,23 +33,50 @@ func (r *Withdrawals) Create(ctx context.Context, w entity.Withdrawals) error {
 		return fmt.Errorf("failed to lock balance table: %w", err)
 	}
 
+	paymentNum, err := nextPaymentNum(ctx, tx, w, maxPayments)
+	if err != nil {
+		return err
+	}
+
+	var balance entity.Balance
 	query :=
 		`UPDATE balance 
 			SET balance = balance - $2, debited = debited + $2
-			WHERE user_id = $1 AND balance >= $2`
+			WHERE user_id = $1 AND balance >= $2
+			RETURNING balance, debited`
 
-	tag, err := tx.Exec(ctx, query, w.UserID, w.Sum)
+	err = tx.QueryRow(ctx, query, w.UserID, w.Sum).Scan(&balance.Balance, &balance.Debited)
 	if err != nil {
+		err = errors.Trasform(err)
+		if err == errors.ErrNotFound {
+			err = errors.ErrNoRowsUpdated
+		}
 		return fmt.Errorf("failed to update balance: %w", err)
 	}
-	if tag.RowsAffected() == 0 {
-		return fmt.Errorf("failed to update balance: %w", errors.ErrNoRowsUpdated)
+
+	query = `INSERT INTO withdrawals (user_id, order_num, sum, payment_num) VALUES($1, $2, $3, $4)`
+	_, err = tx.Exec(ctx, query, w.UserID, w.OrderNumber, w.Sum, paymentNum)
+	if err != nil {
+		return fmt.Errorf("failed to insert into withdrawals: %w", errors.Trasform(err))
+	}
+
+	err = addLedgerEntry(ctx, tx, entity.LedgerEntry{
+		UserID:      w.UserID,
+		Kind:        entity.LedgerKindWithdrawal,
+		Amount:      -w.Sum,
+		OrderNumber: w.OrderNumber,
+	})
+	if err != nil {
+		return err
 	}
 
-	query = `INSERT INTO withdrawals (user_id, order_num, sum) VALUES($1, $2, $3)`
-	_, err = tx.Exec(ctx, query, w.UserID, w.OrderNumber, w.Sum)
+	err = notifyUser(ctx, tx, entity.UserEvent{
+		UserID:  w.UserID,
+		Type:    entity.UserEventBalance,
+		Balance: &entity.BalanceEvent{Balance: balance.Balance, Debited: balance.Debited},
+	})
 	if err != nil {
-		return fmt.Errorf("failed to insert into withdrawals: %w", err)
+		return err
 	}
 
 	if err := tx.Commit(ctx); err != nil {
@@ -57,11 +86,69 @@ func (r *Withdrawals) Create(ctx context.Context, w entity.Withdrawals) error {
 	return nil
 }
 
-func (r *Withdrawals) GetAllByUser(ctx context.Context, userID uint64) ([]entity.Withdrawals, error) {
-	var list []entity.Withdrawals
-	query := `SELECT id, user_id, order_num, sum, processed_at FROM withdrawals WHERE user_id = $1`
+// Конкурентные списания по одному заказу получат одинаковый номер платежа,
+// и одно из них отсечет уникальный индекс (order_num, payment_num).
+func nextPaymentNum(ctx context.Context, tx pgx.Tx, w entity.Withdrawals, maxPayments uint64) (uint64, error) {
+	var (
+		userID     uint64
+		paymentNum uint64
+	)
+
+	query := `SELECT user_id, payment_num FROM withdrawals
+				WHERE order_num = $1 ORDER BY payment_num DESC LIMIT 1 FOR UPDATE`
+	err := tx.QueryRow(ctx, query, w.OrderNumber).Scan(&userID, &paymentNum)
+	if err != nil {
+		err = errors.Trasform(err)
+		if err == errors.ErrNotFound {
+			return 1, nil
+		}
+		return 0, fmt.Errorf("failed to select order withdrawals: %w", err)
+	}
+
+	if userID != w.UserID || paymentNum >= maxPayments {
+		return 0, fmt.Errorf("order#%s already paid: %w", w.OrderNumber, errors.ErrDuplicateKey)
+	}
+
+	return paymentNum + 1, nil
+}
+
+// ListByUser возвращает страницу списаний пользователя, упорядоченных
+// по времени списания и идентификатору, начиная с позиции после f.After.
+func (r *Withdrawals) ListByUser(
+	ctx context.Context,
+	userID uint64,
+	f entity.WithdrawalsFilter,
+) ([]entity.Withdrawals, error) {
+	var (
+		list []entity.Withdrawals
+		args queryArgs
+	)
+	cmp, dir := listOrder(f.Ascending)
+
+	conds := []string{"user_id = " + args.add(userID)}
+	if !f.From.IsZero() {
+		conds = append(conds, "processed_at >= "+args.add(f.From))
+	}
+	if !f.To.IsZero() {
+		conds = append(conds, "processed_at < "+args.add(f.To))
+	}
+	if f.MinSum != nil {
+		conds = append(conds, "sum >= "+args.add(*f.MinSum))
+	}
+	if f.MaxSum != nil {
+		conds = append(conds, "sum <= "+args.add(*f.MaxSum))
+	}
+	if f.After != nil {
+		conds = append(conds, fmt.Sprintf(
+			"(processed_at, id) %s (%s, %s)", cmp, args.add(f.After.Processed), args.add(f.After.ID),
+		))
+	}
+
+	query := `SELECT id, user_id, order_num, sum, processed_at FROM withdrawals` +
+		where(conds) +
+		fmt.Sprintf(" ORDER BY processed_at %[1]s, id %[1]s LIMIT %s", dir, args.add(f.Limit))
 
-	rows, err := r.pool.Query(ctx, query, userID)
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return list, errors.Trasform(err)
 	}
@@ -0,0 +1,50 @@
+// Package requestid хранит идентификатор запроса в контексте, чтобы связать
+// логи HTTP запроса, сервисов и обращений к системе расчета.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+const (
+	Header = "X-Request-ID"
+	maxLen = 128
+)
+
+type contextKey struct{}
+
+// New генерирует идентификатор из 16 случайных байт в hex.
+func New() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Valid принимаются идентификаторы до 128 символов из букв, цифр и -_.:
+// чтобы чужое значение не ломало формат логов и заголовков.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext возвращает пустую строку, если идентификатора в контексте нет.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
@@ -0,0 +1,41 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	id := New()
+	assert.Len(t, id, 32, "Generated id length")
+	assert.True(t, Valid(id), "Generated id is valid")
+	assert.NotEqual(t, id, New(), "Generated ids differ")
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{name: "uuid", id: "3f2c9a1e-7b4d-4c2a-9e1f-0a6b5c4d3e2f", want: true},
+		{name: "dotted", id: "lb-1.req:42_a", want: true},
+		{name: "negative_empty", id: "", want: false},
+		{name: "negative_too_long", id: strings.Repeat("a", 129), want: false},
+		{name: "negative_spaces", id: "req 42", want: false},
+		{name: "negative_newline", id: "req\n42", want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, Valid(test.id))
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	assert.Equal(t, "", FromContext(context.Background()), "Missing id")
+	assert.Equal(t, "req-42", FromContext(NewContext(context.Background(), "req-42")), "Stored id")
+}
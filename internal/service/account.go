@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/middleware"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+	repErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/repository/errors"
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+)
+
+// ChangePassword меняет пароль текущего пользователя. Все выданные ранее токены
+// перестают действовать, новые нужно получить через вход.
+func (a *Auth) ChangePassword(ctx context.Context, c dto.PasswordChange, ip string) error {
+	ctx, span := tracer.Start(ctx, "Auth.ChangePassword")
+	defer span.End()
+
+	user, err := a.currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := a.checkPassword(ctx, user, strings.TrimSpace(c.OldPassword), ip); err != nil {
+		return err
+	}
+
+	newPassword := strings.TrimSpace(c.NewPassword)
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+
+	hash, err := a.hasher.Hash(newPassword)
+	if err != nil {
+		a.logger.Error("failed to hash password", err, logger.Request(ctx))
+		return srvErrors.ErrUnexpected
+	}
+
+	err = a.repository.ChangePassword(ctx, user.ID, hash)
+	if err != nil {
+		if errors.Is(err, repErrors.ErrNotFound) {
+			return srvErrors.ErrUserNotFound
+		}
+		a.logger.Error("failed to change password", err, logger.UserID(user.ID), logger.Request(ctx))
+		return srvErrors.ErrUnexpected
+	}
+
+	return nil
+}
+
+// DeleteAccount закрывает аккаунт текущего пользователя после проверки пароля.
+func (a *Auth) DeleteAccount(ctx context.Context, c dto.AccountDeletion, ip string) error {
+	ctx, span := tracer.Start(ctx, "Auth.DeleteAccount")
+	defer span.End()
+
+	user, err := a.currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := a.checkPassword(ctx, user, strings.TrimSpace(c.Password), ip); err != nil {
+		return err
+	}
+
+	_, err = a.repository.Delete(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, repErrors.ErrNotFound) {
+			return srvErrors.ErrUserNotFound
+		}
+		a.logger.Error("failed to delete user", err, logger.UserID(user.ID), logger.Request(ctx))
+		return srvErrors.ErrUnexpected
+	}
+
+	return nil
+}
+
+func (a *Auth) currentUser(ctx context.Context) (entity.User, error) {
+	userID, ok := ctx.Value(middleware.KeyUserID).(uint64)
+	if !ok {
+		a.logger.Error("failed to get user id", srvErrors.ErrUnexpected, logger.Request(ctx))
+		return entity.User{}, srvErrors.ErrUnexpected
+	}
+
+	user, err := a.repository.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repErrors.ErrNotFound) {
+			return user, srvErrors.ErrUserNotFound
+		}
+		a.logger.Error("failed to find user by id", err, logger.UserID(userID), logger.Request(ctx))
+		return user, srvErrors.ErrUnexpected
+	}
+
+	return user, nil
+}
+
+// checkPassword подтверждает действие паролем, неудачные попытки
+// ограничиваются так же, как при входе.
+func (a *Auth) checkPassword(ctx context.Context, user entity.User, password, ip string) error {
+	if err := a.throttler.Allow(ctx, user.Login, ip); err != nil {
+		return err
+	}
+
+	ok, err := a.hasher.Verify(password, user.Hash)
+	if err != nil {
+		a.logger.Error("failed to verify password", err, logger.Request(ctx))
+		return srvErrors.ErrUnexpected
+	}
+	if !ok {
+		a.throttler.Fail(ctx, user.Login, ip)
+		return srvErrors.ErrAuthWrongPassword
+	}
+
+	a.throttler.Succeed(ctx, user.Login)
+
+	return nil
+}
@@ -0,0 +1,465 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/middleware"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+	repErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/repository/errors"
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/mocks"
+)
+
+func TestAuth_ChangePassword(t *testing.T) {
+	var userID uint64 = 13
+	userIDctx := context.WithValue(context.Background(), middleware.KeyUserID, userID)
+
+	hash, err := testHasher.Hash("t1estP5assword")
+	require.Nil(t, err, "Generate hash for entity")
+	user := entity.User{ID: userID, Login: "testLogin", Hash: hash}
+
+	goodRequest := dto.PasswordChange{OldPassword: "t1estP5assword", NewPassword: "n2ewP6assword"}
+	retryErr := &srvErrors.RetryError{Err: srvErrors.ErrAuthTooManyAttempts, After: time.Minute}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		request dto.PasswordChange
+		rSetup  func(t *testing.T) UserRepository
+		thSetup func(t *testing.T) Throttler
+		lSetup  func(t *testing.T) Logger
+		wantErr error
+	}{
+		{
+			name:    "success",
+			ctx:     userIDctx,
+			request: goodRequest,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					GetByID(gomock.All(), userID).
+					Return(user, nil)
+				repository.EXPECT().
+					ChangePassword(gomock.All(), userID, gomock.All()).
+					DoAndReturn(func(_ context.Context, _ uint64, hash string) error {
+						ok, err := testHasher.Verify(goodRequest.NewPassword, hash)
+						assert.True(t, ok && err == nil, "New hash matches new password")
+						return nil
+					})
+				return repository
+			},
+			thSetup: func(t *testing.T) Throttler {
+				ctrl := gomock.NewController(t)
+				throttler := mocks.NewMockThrottler(ctrl)
+				throttler.EXPECT().
+					Allow(gomock.All(), user.Login, testIP).
+					Return(nil)
+				throttler.EXPECT().
+					Succeed(gomock.All(), user.Login)
+				return throttler
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			wantErr: nil,
+		},
+		{
+			name:    "negative_wrong_old_password",
+			ctx:     userIDctx,
+			request: dto.PasswordChange{OldPassword: "badPassword", NewPassword: "n2ewP6assword"},
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					GetByID(gomock.All(), userID).
+					Return(user, nil)
+				repository.EXPECT().
+					ChangePassword(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			thSetup: func(t *testing.T) Throttler {
+				ctrl := gomock.NewController(t)
+				throttler := mocks.NewMockThrottler(ctrl)
+				throttler.EXPECT().
+					Allow(gomock.All(), user.Login, testIP).
+					Return(nil)
+				throttler.EXPECT().
+					Fail(gomock.All(), user.Login, testIP)
+				return throttler
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			wantErr: srvErrors.ErrAuthWrongPassword,
+		},
+		{
+			name:    "negative_throttled",
+			ctx:     userIDctx,
+			request: goodRequest,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					GetByID(gomock.All(), userID).
+					Return(user, nil)
+				repository.EXPECT().
+					ChangePassword(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			thSetup: func(t *testing.T) Throttler {
+				ctrl := gomock.NewController(t)
+				throttler := mocks.NewMockThrottler(ctrl)
+				throttler.EXPECT().
+					Allow(gomock.All(), user.Login, testIP).
+					Return(retryErr)
+				return throttler
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			wantErr: srvErrors.ErrAuthTooManyAttempts,
+		},
+		{
+			name:    "negative_empty_new_password",
+			ctx:     userIDctx,
+			request: dto.PasswordChange{OldPassword: "t1estP5assword", NewPassword: "  "},
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					GetByID(gomock.All(), userID).
+					Return(user, nil)
+				repository.EXPECT().
+					ChangePassword(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			thSetup: func(t *testing.T) Throttler {
+				ctrl := gomock.NewController(t)
+				throttler := mocks.NewMockThrottler(ctrl)
+				throttler.EXPECT().
+					Allow(gomock.All(), user.Login, testIP).
+					Return(nil)
+				throttler.EXPECT().
+					Succeed(gomock.All(), user.Login)
+				return throttler
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			wantErr: srvErrors.ErrAuthInvalidCredentials,
+		},
+		{
+			name:    "negative_user_deleted",
+			ctx:     userIDctx,
+			request: goodRequest,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					GetByID(gomock.All(), userID).
+					Return(entity.User{}, repErrors.ErrNotFound)
+				return repository
+			},
+			thSetup: func(t *testing.T) Throttler {
+				ctrl := gomock.NewController(t)
+				throttler := mocks.NewMockThrottler(ctrl)
+				throttler.EXPECT().
+					Allow(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return throttler
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			wantErr: srvErrors.ErrUserNotFound,
+		},
+		{
+			name:    "negative_no_user_id_in_context",
+			ctx:     context.Background(),
+			request: goodRequest,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					GetByID(gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			thSetup: func(t *testing.T) Throttler {
+				return mocks.NewMockThrottler(gomock.NewController(t))
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to get user id", gomock.All(), gomock.Any())
+				return logger
+			},
+			wantErr: srvErrors.ErrUnexpected,
+		},
+		{
+			name:    "negative_repository_error",
+			ctx:     userIDctx,
+			request: goodRequest,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					GetByID(gomock.All(), userID).
+					Return(user, nil)
+				repository.EXPECT().
+					ChangePassword(gomock.All(), userID, gomock.All()).
+					Return(fmt.Errorf("any error"))
+				return repository
+			},
+			thSetup: func(t *testing.T) Throttler {
+				ctrl := gomock.NewController(t)
+				throttler := mocks.NewMockThrottler(ctrl)
+				throttler.EXPECT().
+					Allow(gomock.All(), user.Login, testIP).
+					Return(nil)
+				throttler.EXPECT().
+					Succeed(gomock.All(), user.Login)
+				return throttler
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to change password", gomock.All(), gomock.Any())
+				return logger
+			},
+			wantErr: srvErrors.ErrUnexpected,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokens := mocks.NewMockTokenRepository(gomock.NewController(t))
+			authService := NewAuth(
+				test.rSetup(t),
+				tokens,
+				testHasher,
+				test.thSetup(t),
+				test.lSetup(t),
+				testKeySet(t),
+				testTokenTTL,
+			)
+
+			err := authService.ChangePassword(test.ctx, test.request, testIP)
+			assert.ErrorIs(t, err, test.wantErr, "Change password error")
+		})
+	}
+}
+
+func TestAuth_DeleteAccount(t *testing.T) {
+	var userID uint64 = 13
+	userIDctx := context.WithValue(context.Background(), middleware.KeyUserID, userID)
+
+	hash, err := testHasher.Hash("t1estP5assword")
+	require.Nil(t, err, "Generate hash for entity")
+	user := entity.User{ID: userID, Login: "testLogin", Hash: hash}
+
+	goodRequest := dto.AccountDeletion{Password: "t1estP5assword"}
+
+	tests := []struct {
+		name    string
+		request dto.AccountDeletion
+		rSetup  func(t *testing.T) UserRepository
+		thSetup func(t *testing.T) Throttler
+		lSetup  func(t *testing.T) Logger
+		wantErr error
+	}{
+		{
+			name:    "success",
+			request: goodRequest,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					GetByID(gomock.All(), userID).
+					Return(user, nil)
+				repository.EXPECT().
+					Delete(gomock.All(), userID).
+					Return(money.Amount(72998), nil)
+				return repository
+			},
+			thSetup: func(t *testing.T) Throttler {
+				ctrl := gomock.NewController(t)
+				throttler := mocks.NewMockThrottler(ctrl)
+				throttler.EXPECT().
+					Allow(gomock.All(), user.Login, testIP).
+					Return(nil)
+				throttler.EXPECT().
+					Succeed(gomock.All(), user.Login)
+				return throttler
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			wantErr: nil,
+		},
+		{
+			name:    "negative_wrong_password",
+			request: dto.AccountDeletion{Password: "badPassword"},
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					GetByID(gomock.All(), userID).
+					Return(user, nil)
+				repository.EXPECT().
+					Delete(gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			thSetup: func(t *testing.T) Throttler {
+				ctrl := gomock.NewController(t)
+				throttler := mocks.NewMockThrottler(ctrl)
+				throttler.EXPECT().
+					Allow(gomock.All(), user.Login, testIP).
+					Return(nil)
+				throttler.EXPECT().
+					Fail(gomock.All(), user.Login, testIP)
+				return throttler
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			wantErr: srvErrors.ErrAuthWrongPassword,
+		},
+		{
+			name:    "negative_already_deleted",
+			request: goodRequest,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					GetByID(gomock.All(), userID).
+					Return(user, nil)
+				repository.EXPECT().
+					Delete(gomock.All(), userID).
+					Return(money.Amount(0), repErrors.ErrNotFound)
+				return repository
+			},
+			thSetup: func(t *testing.T) Throttler {
+				ctrl := gomock.NewController(t)
+				throttler := mocks.NewMockThrottler(ctrl)
+				throttler.EXPECT().
+					Allow(gomock.All(), user.Login, testIP).
+					Return(nil)
+				throttler.EXPECT().
+					Succeed(gomock.All(), user.Login)
+				return throttler
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			wantErr: srvErrors.ErrUserNotFound,
+		},
+		{
+			name:    "negative_repository_error",
+			request: goodRequest,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					GetByID(gomock.All(), userID).
+					Return(user, nil)
+				repository.EXPECT().
+					Delete(gomock.All(), userID).
+					Return(money.Amount(0), fmt.Errorf("any error"))
+				return repository
+			},
+			thSetup: func(t *testing.T) Throttler {
+				ctrl := gomock.NewController(t)
+				throttler := mocks.NewMockThrottler(ctrl)
+				throttler.EXPECT().
+					Allow(gomock.All(), user.Login, testIP).
+					Return(nil)
+				throttler.EXPECT().
+					Succeed(gomock.All(), user.Login)
+				return throttler
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to delete user", gomock.All(), gomock.Any())
+				return logger
+			},
+			wantErr: srvErrors.ErrUnexpected,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokens := mocks.NewMockTokenRepository(gomock.NewController(t))
+			authService := NewAuth(
+				test.rSetup(t),
+				tokens,
+				testHasher,
+				test.thSetup(t),
+				test.lSetup(t),
+				testKeySet(t),
+				testTokenTTL,
+			)
+
+			err := authService.DeleteAccount(userIDctx, test.request, testIP)
+			assert.ErrorIs(t, err, test.wantErr, "Delete account error")
+		})
+	}
+}
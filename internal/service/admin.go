@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+	repErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/repository/errors"
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+)
+
+type AdminOrderRepository interface {
+	Search(ctx context.Context, f entity.AdminOrderFilter) ([]entity.OrderDetails, error)
+	GetDetails(ctx context.Context, number string) (entity.OrderDetails, error)
+	Requeue(ctx context.Context, number string, from []string) (entity.OrderDetails, error)
+	SetStatus(ctx context.Context, number string, status string, from []string) (entity.OrderDetails, error)
+}
+
+// Начисленный заказ не возвращается в обработку, иначе баллы были бы начислены повторно.
+var (
+	requeueSources = []string{entity.OrderStatusNew, entity.OrderStatusProcessing, entity.OrderStatusFailed}
+	statusSources  = map[string][]string{
+		entity.OrderStatusNew: {
+			entity.OrderStatusNew,
+			entity.OrderStatusProcessing,
+			entity.OrderStatusInvalid,
+			entity.OrderStatusFailed,
+		},
+		entity.OrderStatusInvalid: {
+			entity.OrderStatusNew,
+			entity.OrderStatusProcessing,
+			entity.OrderStatusFailed,
+		},
+	}
+)
+
+// AdminOrder операции с заказами всех пользователей, в отличие от Order видит статус FAILED.
+type AdminOrder struct {
+	repository AdminOrderRepository
+	logger     Logger
+}
+
+func NewAdminOrder(r AdminOrderRepository, l Logger) *AdminOrder {
+	return &AdminOrder{repository: r, logger: l}
+}
+
+func (a *AdminOrder) Search(ctx context.Context, q dto.AdminOrderQuery) (page dto.AdminOrderPage, err error) {
+	ctx, span := tracer.Start(ctx, "AdminOrder.Search")
+	defer span.End()
+
+	filter, err := adminOrderFilter(q)
+	if err != nil {
+		return page, err
+	}
+	limit := filter.Limit
+	// Лишняя запись показывает, есть ли следующая страница.
+	filter.Limit++
+
+	orders, err := a.repository.Search(ctx, filter)
+	if err != nil {
+		a.logger.Error("failed to search orders", err, logger.Request(ctx))
+		return page, srvErrors.ErrUnexpected
+	}
+
+	if len(orders) > limit {
+		orders = orders[:limit]
+		last := orders[limit-1]
+		page.NextCursor = encodeCursor(entity.OrderCursor{Uploaded: last.Uploaded, Number: last.Number})
+	}
+
+	for _, order := range orders {
+		page.Orders = append(page.Orders, adminOrderDTO(order))
+	}
+
+	return page, nil
+}
+
+func (a *AdminOrder) Get(ctx context.Context, number string) (dto.AdminOrder, error) {
+	ctx, span := tracer.Start(ctx, "AdminOrder.Get")
+	defer span.End()
+
+	order, err := a.repository.GetDetails(ctx, number)
+	if err != nil {
+		return dto.AdminOrder{}, a.orderError(ctx, err, "failed to get order", number)
+	}
+
+	return adminOrderDTO(order), nil
+}
+
+// Recheck ставит заказ на немедленный опрос системы расчета без учета backoff.
+func (a *AdminOrder) Recheck(ctx context.Context, number string) (dto.AdminOrder, error) {
+	ctx, span := tracer.Start(ctx, "AdminOrder.Recheck")
+	defer span.End()
+
+	order, err := a.repository.Requeue(ctx, number, requeueSources)
+	if err != nil {
+		return dto.AdminOrder{}, a.orderError(ctx, err, "failed to requeue order", number)
+	}
+
+	return adminOrderDTO(order), nil
+}
+
+// SetStatus возвращает заказ в NEW или признает его INVALID.
+func (a *AdminOrder) SetStatus(
+	ctx context.Context,
+	number string,
+	req dto.AdminOrderStatusReq,
+) (dto.AdminOrder, error) {
+	ctx, span := tracer.Start(ctx, "AdminOrder.SetStatus")
+	defer span.End()
+
+	status := strings.ToUpper(strings.TrimSpace(req.Status))
+	sources, ok := statusSources[status]
+	if !ok {
+		return dto.AdminOrder{}, fmt.Errorf("%w: only NEW or INVALID allowed", srvErrors.ErrOrderInvalidStatus)
+	}
+
+	order, err := a.repository.SetStatus(ctx, number, status, sources)
+	if err != nil {
+		return dto.AdminOrder{}, a.orderError(ctx, err, "failed to set order status", number)
+	}
+
+	return adminOrderDTO(order), nil
+}
+
+func (a *AdminOrder) orderError(ctx context.Context, err error, message, number string) error {
+	switch {
+	case errors.Is(err, repErrors.ErrNotFound):
+		return srvErrors.ErrOrderNotFound
+	case errors.Is(err, repErrors.ErrNoRowsUpdated):
+		return srvErrors.ErrOrderStatusConflict
+	default:
+		a.logger.Error(message, err, logger.OrderNumber(number), logger.Request(ctx))
+		return srvErrors.ErrUnexpected
+	}
+}
+
+func adminOrderFilter(q dto.AdminOrderQuery) (f entity.AdminOrderFilter, err error) {
+	var after entity.OrderCursor
+
+	f.Limit, f.Ascending, err = listParams(q.ListQuery, &after)
+	if err != nil {
+		return f, err
+	}
+	if q.Cursor != "" {
+		if after.Uploaded.IsZero() || after.Number == "" {
+			return f, fmt.Errorf("%w: invalid cursor", srvErrors.ErrListInvalidQuery)
+		}
+		f.After = &after
+	}
+
+	for _, status := range q.Statuses {
+		status = strings.ToUpper(status)
+		if !slices.Contains(entity.OrderStatuses, status) && status != entity.OrderStatusFailed {
+			return f, fmt.Errorf("%w: unknown order status %q", srvErrors.ErrListInvalidQuery, status)
+		}
+		f.Statuses = append(f.Statuses, status)
+	}
+
+	f.Number, f.UserID = q.Number, q.UserID
+	f.From, f.To = q.From, q.To
+
+	return f, nil
+}
+
+func adminOrderDTO(o entity.OrderDetails) dto.AdminOrder {
+	order := dto.AdminOrder{
+		Number:       o.Number,
+		UserID:       o.UserID,
+		Status:       o.Status,
+		Attempts:     o.Attempts,
+		Uploaded:     o.Uploaded,
+		Updated:      o.Updated,
+		NextAttempt:  o.NextAttempt,
+		LeaseExpires: o.LeaseExpires,
+	}
+	if o.Accrual > 0 {
+		order.Accrual = &o.Accrual
+	}
+	if o.LastError != nil {
+		order.LastError = *o.LastError
+	}
+	if o.LeaseOwner != nil {
+		order.LeaseOwner = *o.LeaseOwner
+	}
+
+	return order
+}
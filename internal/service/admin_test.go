@@ -0,0 +1,440 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+	repErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/repository/errors"
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/mocks"
+)
+
+var (
+	testAdminUploaded = time.Date(2025, 10, 12, 10, 0, 0, 0, time.UTC)
+	testLastError     = "internal server error"
+	testLeaseOwner    = "test-worker"
+	testOrderDetails  = entity.OrderDetails{
+		Order: entity.Order{
+			Number:   "5062821234567892",
+			UserID:   13,
+			Status:   entity.OrderStatusFailed,
+			Uploaded: testAdminUploaded,
+			Updated:  testAdminUploaded.Add(time.Hour),
+		},
+		Attempts:     11,
+		LastError:    &testLastError,
+		NextAttempt:  &testAdminUploaded,
+		LeaseOwner:   &testLeaseOwner,
+		LeaseExpires: &testAdminUploaded,
+	}
+	testAdminOrder = dto.AdminOrder{
+		Number:       "5062821234567892",
+		UserID:       13,
+		Status:       entity.OrderStatusFailed,
+		Attempts:     11,
+		LastError:    testLastError,
+		Uploaded:     testAdminUploaded,
+		Updated:      testAdminUploaded.Add(time.Hour),
+		NextAttempt:  &testAdminUploaded,
+		LeaseOwner:   testLeaseOwner,
+		LeaseExpires: &testAdminUploaded,
+	}
+)
+
+func TestAdminOrder_Search(t *testing.T) {
+	processed := entity.OrderDetails{Order: entity.Order{
+		Number:   "5062821234567819",
+		UserID:   13,
+		Status:   entity.OrderStatusProcessed,
+		Accrual:  money.New(100, 0),
+		Uploaded: testAdminUploaded.Add(-time.Hour),
+		Updated:  testAdminUploaded,
+	}}
+	cursor := entity.OrderCursor{Uploaded: testAdminUploaded, Number: "5062821234567892"}
+
+	type want struct {
+		page dto.AdminOrderPage
+		err  error
+	}
+
+	tests := []struct {
+		name   string
+		query  dto.AdminOrderQuery
+		rSetup func(t *testing.T) AdminOrderRepository
+		lSetup func(t *testing.T) Logger
+		want   want
+	}{
+		{
+			name: "success_with_filters",
+			query: dto.AdminOrderQuery{
+				ListQuery: dto.ListQuery{Limit: 1},
+				UserID:    13,
+				Statuses:  []string{"failed", "PROCESSED"},
+			},
+			rSetup: func(t *testing.T) AdminOrderRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockAdminOrderRepository(ctrl)
+				repository.EXPECT().
+					Search(gomock.All(), entity.AdminOrderFilter{
+						OrderFilter: entity.OrderFilter{
+							Statuses: []string{entity.OrderStatusFailed, entity.OrderStatusProcessed},
+							Limit:    2,
+						},
+						UserID: 13,
+					}).
+					Return([]entity.OrderDetails{testOrderDetails, processed}, nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				page: dto.AdminOrderPage{
+					Orders:     []dto.AdminOrder{testAdminOrder},
+					NextCursor: encodeCursor(cursor),
+				},
+				err: nil,
+			},
+		},
+		{
+			name: "success_by_number",
+			query: dto.AdminOrderQuery{
+				Number: "5062821234567819",
+			},
+			rSetup: func(t *testing.T) AdminOrderRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockAdminOrderRepository(ctrl)
+				repository.EXPECT().
+					Search(gomock.All(), entity.AdminOrderFilter{
+						OrderFilter: entity.OrderFilter{Limit: defaultListLimit + 1},
+						Number:      "5062821234567819",
+					}).
+					Return([]entity.OrderDetails{processed}, nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				page: dto.AdminOrderPage{Orders: []dto.AdminOrder{{
+					Number:   "5062821234567819",
+					UserID:   13,
+					Status:   entity.OrderStatusProcessed,
+					Accrual:  &processed.Accrual,
+					Uploaded: testAdminUploaded.Add(-time.Hour),
+					Updated:  testAdminUploaded,
+				}}},
+				err: nil,
+			},
+		},
+		{
+			name:  "negative_unknown_status",
+			query: dto.AdminOrderQuery{Statuses: []string{"LOST"}},
+			rSetup: func(t *testing.T) AdminOrderRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockAdminOrderRepository(ctrl)
+				repository.EXPECT().
+					Search(gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				page: dto.AdminOrderPage{},
+				err:  srvErrors.ErrListInvalidQuery,
+			},
+		},
+		{
+			name:  "negative_repository_error",
+			query: dto.AdminOrderQuery{},
+			rSetup: func(t *testing.T) AdminOrderRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockAdminOrderRepository(ctrl)
+				repository.EXPECT().
+					Search(gomock.All(), gomock.All()).
+					Return(nil, fmt.Errorf("any error"))
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to search orders", gomock.All(), gomock.Any())
+				return logger
+			},
+			want: want{
+				page: dto.AdminOrderPage{},
+				err:  srvErrors.ErrUnexpected,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			adminService := NewAdminOrder(test.rSetup(t), test.lSetup(t))
+			page, err := adminService.Search(context.Background(), test.query)
+			assert.ErrorIs(t, err, test.want.err, "Search orders error")
+			assert.Equal(t, test.want.page, page, "Orders page")
+		})
+	}
+}
+
+func TestAdminOrder_Get(t *testing.T) {
+	tests := []struct {
+		name   string
+		rErr   error
+		lSetup func(t *testing.T) Logger
+		want   error
+	}{
+		{
+			name: "success",
+			rErr: nil,
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: nil,
+		},
+		{
+			name: "negative_not_found",
+			rErr: fmt.Errorf("failed to parse order: %w", repErrors.ErrNotFound),
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: srvErrors.ErrOrderNotFound,
+		},
+		{
+			name: "negative_repository_error",
+			rErr: fmt.Errorf("any error"),
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to get order", gomock.All(), gomock.Any())
+				return logger
+			},
+			want: srvErrors.ErrUnexpected,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := mocks.NewMockAdminOrderRepository(gomock.NewController(t))
+			repository.EXPECT().
+				GetDetails(gomock.All(), "5062821234567892").
+				Return(testOrderDetails, test.rErr)
+
+			adminService := NewAdminOrder(repository, test.lSetup(t))
+			order, err := adminService.Get(context.Background(), "5062821234567892")
+			assert.ErrorIs(t, err, test.want, "Get order error")
+			if test.want == nil {
+				assert.Equal(t, testAdminOrder, order, "Order")
+			}
+		})
+	}
+}
+
+func TestAdminOrder_Recheck(t *testing.T) {
+	requeued := testOrderDetails
+	requeued.Status = entity.OrderStatusNew
+
+	tests := []struct {
+		name   string
+		rErr   error
+		lSetup func(t *testing.T) Logger
+		want   error
+	}{
+		{
+			name: "success",
+			rErr: nil,
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: nil,
+		},
+		{
+			name: "negative_final_status",
+			rErr: fmt.Errorf("failed to update order: %w", repErrors.ErrNoRowsUpdated),
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: srvErrors.ErrOrderStatusConflict,
+		},
+		{
+			name: "negative_repository_error",
+			rErr: fmt.Errorf("any error"),
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to requeue order", gomock.All(), gomock.Any())
+				return logger
+			},
+			want: srvErrors.ErrUnexpected,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := mocks.NewMockAdminOrderRepository(gomock.NewController(t))
+			repository.EXPECT().
+				Requeue(gomock.All(), "5062821234567892", []string{
+					entity.OrderStatusNew,
+					entity.OrderStatusProcessing,
+					entity.OrderStatusFailed,
+				}).
+				Return(requeued, test.rErr)
+
+			adminService := NewAdminOrder(repository, test.lSetup(t))
+			order, err := adminService.Recheck(context.Background(), "5062821234567892")
+			assert.ErrorIs(t, err, test.want, "Recheck order error")
+			if test.want == nil {
+				assert.Equal(t, entity.OrderStatusNew, order.Status, "Order status")
+			}
+		})
+	}
+}
+
+func TestAdminOrder_SetStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		rSetup func(t *testing.T) AdminOrderRepository
+		want   error
+	}{
+		{
+			name:   "success_new",
+			status: "new",
+			rSetup: func(t *testing.T) AdminOrderRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockAdminOrderRepository(ctrl)
+				repository.EXPECT().
+					SetStatus(gomock.All(), "5062821234567892", entity.OrderStatusNew, []string{
+						entity.OrderStatusNew,
+						entity.OrderStatusProcessing,
+						entity.OrderStatusInvalid,
+						entity.OrderStatusFailed,
+					}).
+					Return(testOrderDetails, nil)
+				return repository
+			},
+			want: nil,
+		},
+		{
+			name:   "success_invalid",
+			status: "INVALID",
+			rSetup: func(t *testing.T) AdminOrderRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockAdminOrderRepository(ctrl)
+				repository.EXPECT().
+					SetStatus(gomock.All(), "5062821234567892", entity.OrderStatusInvalid, []string{
+						entity.OrderStatusNew,
+						entity.OrderStatusProcessing,
+						entity.OrderStatusFailed,
+					}).
+					Return(testOrderDetails, nil)
+				return repository
+			},
+			want: nil,
+		},
+		{
+			name:   "negative_processed_not_allowed",
+			status: "PROCESSED",
+			rSetup: func(t *testing.T) AdminOrderRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockAdminOrderRepository(ctrl)
+				repository.EXPECT().
+					SetStatus(gomock.All(), gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			want: srvErrors.ErrOrderInvalidStatus,
+		},
+		{
+			name:   "negative_status_conflict",
+			status: "NEW",
+			rSetup: func(t *testing.T) AdminOrderRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockAdminOrderRepository(ctrl)
+				repository.EXPECT().
+					SetStatus(gomock.All(), "5062821234567892", entity.OrderStatusNew, gomock.All()).
+					Return(entity.OrderDetails{}, fmt.Errorf("failed to update order: %w", repErrors.ErrNoRowsUpdated))
+				return repository
+			},
+			want: srvErrors.ErrOrderStatusConflict,
+		},
+		{
+			name:   "negative_not_found",
+			status: "NEW",
+			rSetup: func(t *testing.T) AdminOrderRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockAdminOrderRepository(ctrl)
+				repository.EXPECT().
+					SetStatus(gomock.All(), "5062821234567892", entity.OrderStatusNew, gomock.All()).
+					Return(entity.OrderDetails{}, fmt.Errorf("failed to parse order: %w", repErrors.ErrNotFound))
+				return repository
+			},
+			want: srvErrors.ErrOrderNotFound,
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			adminService := NewAdminOrder(test.rSetup(t), logger)
+			_, err := adminService.SetStatus(
+				context.Background(),
+				"5062821234567892",
+				dto.AdminOrderStatusReq{Status: test.status},
+			)
+			assert.ErrorIs(t, err, test.want, "Set order status error")
+		})
+	}
+}
@@ -2,6 +2,10 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strconv"
@@ -9,10 +13,12 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/jwtkeys"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
 	repErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/repository/errors"
 	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
 )
@@ -21,146 +27,412 @@ type UserRepository interface {
 	Create(ctx context.Context, user entity.User) (entity.User, error)
 	FindByLogin(ctx context.Context, login string) (entity.User, error)
 	GetByID(ctx context.Context, id uint64) (entity.User, error)
+	UpdateHash(ctx context.Context, id uint64, hash string) error
+	ChangePassword(ctx context.Context, id uint64, hash string) error
+	Delete(ctx context.Context, id uint64) (money.Amount, error)
+}
+
+type Throttler interface {
+	Allow(ctx context.Context, login, ip string) error
+	Fail(ctx context.Context, login, ip string)
+	Succeed(ctx context.Context, login string)
+}
+
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, hash string) (bool, error)
+	NeedsRehash(hash string) bool
+}
+
+type TokenRepository interface {
+	CreateRefresh(ctx context.Context, t entity.RefreshToken) error
+	FindRefresh(ctx context.Context, hash string) (entity.RefreshToken, error)
+	RotateRefresh(ctx context.Context, usedID uint64, next entity.RefreshToken) error
+	RevokeRefresh(ctx context.Context, userID uint64, hash string) error
+	RevokeAllRefresh(ctx context.Context, userID uint64) error
+	RevokeAccess(ctx context.Context, jti string, userID uint64, expires time.Time) error
+	IsAccessRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+const tokenType = "Bearer"
+
+type TokenTTL struct {
+	Access  time.Duration
+	Refresh time.Duration
 }
 
 type Auth struct {
 	repository UserRepository
+	tokens     TokenRepository
+	hasher     PasswordHasher
+	throttler  Throttler
 	logger     Logger
-	secret     string
+	keys       *jwtkeys.KeySet
+	ttl        TokenTTL
 }
 
-func NewAuth(r UserRepository, l Logger, jwtSecret string) *Auth {
-	return &Auth{repository: r, logger: l, secret: jwtSecret}
+func NewAuth(
+	r UserRepository,
+	t TokenRepository,
+	h PasswordHasher,
+	th Throttler,
+	l Logger,
+	keys *jwtkeys.KeySet,
+	ttl TokenTTL,
+) *Auth {
+	return &Auth{
+		repository: r,
+		tokens:     t,
+		hasher:     h,
+		throttler:  th,
+		logger:     l,
+		keys:       keys,
+		ttl:        ttl,
+	}
 }
 
-func (a *Auth) Register(ctx context.Context, c dto.Credentials) (token string, err error) {
+func (a *Auth) Register(ctx context.Context, c dto.Credentials) (tokens dto.AuthTokens, err error) {
+	ctx, span := tracer.Start(ctx, "Auth.Register")
+	defer span.End()
+
 	cr := trimCredentials(c)
 	if err := validateCredentials(cr); err != nil {
-		return token, err
+		return tokens, err
 	}
 
-	// bcrypt имеет недостатки, в дальнейшем планирую переделать на другой алгоритм
-	hash, err := bcrypt.GenerateFromPassword([]byte(cr.Password), bcrypt.DefaultCost)
+	hash, err := a.hasher.Hash(cr.Password)
 	if err != nil {
-		switch {
-		case errors.Is(err, bcrypt.ErrPasswordTooLong):
-			err = fmt.Errorf("%w: password too long, max 72 bytes", srvErrors.ErrAuthInvalidCredentials)
-			return token, err
-		default:
-			a.logger.Error("failed to hash password", err)
-			return token, srvErrors.ErrUnexpected
-		}
+		a.logger.Error("failed to hash password", err, logger.Request(ctx))
+		return tokens, srvErrors.ErrUnexpected
 	}
 
-	user := entity.User{Login: c.Login, Hash: string(hash)}
+	user := entity.User{Login: c.Login, Hash: hash}
 	user, err = a.repository.Create(ctx, user)
 
 	if err != nil {
 		switch {
 		case errors.Is(err, repErrors.ErrDuplicateKey):
-			return token, srvErrors.ErrAuthUserAlreadyExists
+			return tokens, srvErrors.ErrAuthUserAlreadyExists
 		default:
-			a.logger.Error("failed to create user", err)
-			return token, srvErrors.ErrUnexpected
+			a.logger.Error("failed to create user", err, logger.Request(ctx))
+			return tokens, srvErrors.ErrUnexpected
 		}
 	}
 
-	return a.generateToken(user)
+	return a.issueTokens(ctx, user)
 }
 
-func (a *Auth) Login(ctx context.Context, c dto.Credentials) (token string, err error) {
+// Login проверяет учетные данные, ip нужен для ограничения подбора паролей.
+func (a *Auth) Login(ctx context.Context, c dto.Credentials, ip string) (tokens dto.AuthTokens, err error) {
+	ctx, span := tracer.Start(ctx, "Auth.Login")
+	defer span.End()
+
 	cr := trimCredentials(c)
 
+	if err := a.throttler.Allow(ctx, cr.Login, ip); err != nil {
+		return tokens, err
+	}
+
 	user, err := a.repository.FindByLogin(ctx, cr.Login)
 	if err != nil {
 		if errors.Is(err, repErrors.ErrNotFound) {
-			return token, srvErrors.ErrAuthInvalidCredentials
+			a.throttler.Fail(ctx, cr.Login, ip)
+			return tokens, srvErrors.ErrAuthInvalidCredentials
 		} else {
-			a.logger.Error("failed to find user", err)
-			return token, srvErrors.ErrUnexpected
+			a.logger.Error("failed to find user", err, logger.Request(ctx))
+			return tokens, srvErrors.ErrUnexpected
 		}
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Hash), []byte(cr.Password))
+	ok, err := a.hasher.Verify(cr.Password, user.Hash)
 	if err != nil {
-		return token, srvErrors.ErrAuthInvalidCredentials
+		a.logger.Error("failed to verify password", err, logger.Request(ctx))
+		return tokens, srvErrors.ErrUnexpected
 	}
+	if !ok {
+		a.throttler.Fail(ctx, cr.Login, ip)
+		return tokens, srvErrors.ErrAuthInvalidCredentials
+	}
+
+	a.throttler.Succeed(ctx, cr.Login)
 
-	return a.generateToken(user)
+	if a.hasher.NeedsRehash(user.Hash) {
+		a.rehash(ctx, user.ID, cr.Password)
+	}
+
+	return a.issueTokens(ctx, user)
 }
 
-func (a *Auth) User(ctx context.Context, token string) (entity.User, error) {
-	var user entity.User
+// Refresh обменивает refresh токен на новую пару токенов.
+// Повторное использование уже обмененного токена считается признаком кражи,
+// поэтому в этом случае отзываются все refresh токены пользователя.
+// Роль в новом access токене берется из базы, поэтому смена роли видна после обмена.
+func (a *Auth) Refresh(ctx context.Context, refreshToken string) (tokens dto.AuthTokens, err error) {
+	ctx, span := tracer.Start(ctx, "Auth.Refresh")
+	defer span.End()
 
-	jt, err := jwt.Parse(
-		token,
-		func(t *jwt.Token) (any, error) {
-			return []byte(a.secret), nil
-		},
-	)
+	if refreshToken == "" {
+		return tokens, srvErrors.ErrAuthInvalidToken
+	}
 
+	used, err := a.tokens.FindRefresh(ctx, hashToken(refreshToken))
 	if err != nil {
-		if errors.Is(err, jwt.ErrTokenExpired) {
-			return user, srvErrors.ErrAuthTokenExpired
+		if errors.Is(err, repErrors.ErrNotFound) {
+			return tokens, srvErrors.ErrAuthInvalidToken
 		}
-		return user, srvErrors.ErrAuthInvalidToken
+		a.logger.Error("failed to find refresh token", err, logger.Request(ctx))
+		return tokens, srvErrors.ErrUnexpected
+	}
+
+	if used.IsRevoked() {
+		a.revokeAllRefresh(ctx, used.UserID)
+		return tokens, srvErrors.ErrAuthInvalidToken
+	}
+
+	if time.Now().After(used.Expires) {
+		return tokens, srvErrors.ErrAuthTokenExpired
 	}
 
-	userID, err := parseID(jt)
+	user, err := a.repository.GetByID(ctx, used.UserID)
 	if err != nil {
+		if errors.Is(err, repErrors.ErrNotFound) {
+			return tokens, srvErrors.ErrAuthInvalidToken
+		}
+		a.logger.Error("failed to find user by id", err, logger.UserID(used.UserID), logger.Request(ctx))
+		return tokens, srvErrors.ErrUnexpected
+	}
+
+	refresh, next, err := a.newRefreshToken(ctx, used.UserID)
+	if err != nil {
+		return tokens, err
+	}
+
+	err = a.tokens.RotateRefresh(ctx, used.ID, next)
+	if err != nil {
+		if errors.Is(err, repErrors.ErrNoRowsUpdated) {
+			a.revokeAllRefresh(ctx, used.UserID)
+			return tokens, srvErrors.ErrAuthInvalidToken
+		}
+		a.logger.Error("failed to rotate refresh token", err, logger.UserID(used.UserID), logger.Request(ctx))
+		return tokens, srvErrors.ErrUnexpected
+	}
+
+	return a.newTokens(ctx, user, refresh)
+}
+
+// Logout отзывает access токен и, если он передан, refresh токен.
+func (a *Auth) Logout(ctx context.Context, accessToken, refreshToken string) error {
+	ctx, span := tracer.Start(ctx, "Auth.Logout")
+	defer span.End()
+
+	claims, err := a.parseToken(accessToken)
+	if err != nil {
+		return err
+	}
+
+	err = a.tokens.RevokeAccess(ctx, claims.ID, claims.userID, claims.ExpiresAt.Time)
+	if err != nil {
+		a.logger.Error("failed to revoke access token", err, logger.Request(ctx))
+		return srvErrors.ErrUnexpected
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	err = a.tokens.RevokeRefresh(ctx, claims.userID, hashToken(refreshToken))
+	if err != nil {
+		a.logger.Error("failed to revoke refresh token", err, logger.Request(ctx))
+		return srvErrors.ErrUnexpected
+	}
+
+	return nil
+}
+
+func (a *Auth) User(ctx context.Context, token string) (entity.User, error) {
+	ctx, span := tracer.Start(ctx, "Auth.User")
+	defer span.End()
+
+	var user entity.User
+
+	claims, err := a.parseToken(token)
+	if err != nil {
+		return user, err
+	}
+
+	revoked, err := a.tokens.IsAccessRevoked(ctx, claims.ID)
+	if err != nil {
+		a.logger.Error("failed to check token revocation", err, logger.Request(ctx))
+		return user, srvErrors.ErrAuthInvalidToken
+	}
+	if revoked {
 		return user, srvErrors.ErrAuthInvalidToken
 	}
 
-	user, err = a.repository.GetByID(ctx, userID)
+	user, err = a.repository.GetByID(ctx, claims.userID)
 	if err != nil {
 		if !errors.Is(err, repErrors.ErrNotFound) {
-			a.logger.Error("failed to find user by id", err)
+			a.logger.Error("failed to find user by id", err, logger.UserID(claims.userID), logger.Request(ctx))
 		}
 		return user, srvErrors.ErrAuthInvalidToken
 	}
 
+	// После смены пароля или удаления аккаунта ранее выданные токены недействительны.
+	// Роль берется из базы, а не из токена, чтобы понижение роли действовало сразу.
+	if user.TokensValidAfter != nil &&
+		(claims.IssuedAt == nil || claims.IssuedAt.Time.Before(*user.TokensValidAfter)) {
+		return entity.User{}, srvErrors.ErrAuthInvalidToken
+	}
+
 	return user, nil
 }
 
-func parseID(token *jwt.Token) (uint64, error) {
-	var id uint64
+// JWKS возвращает публичные ключи для проверки токенов другими сервисами.
+func (a *Auth) JWKS() dto.JWKS {
+	return dto.JWKS{Keys: a.keys.JWKS()}
+}
+
+type accessClaims struct {
+	jwt.RegisteredClaims
+	Role   string `json:"role,omitempty"`
+	userID uint64
+}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok || !token.Valid {
-		return id, srvErrors.ErrAuthInvalidToken
+func (a *Auth) parseToken(token string) (accessClaims, error) {
+	var claims accessClaims
+
+	_, err := jwt.ParseWithClaims(
+		token,
+		&claims,
+		a.keys.Keyfunc,
+		jwt.WithValidMethods(a.keys.Methods()),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return claims, srvErrors.ErrAuthTokenExpired
+		}
+		return claims, srvErrors.ErrAuthInvalidToken
 	}
 
-	jti, ok := claims["jti"].(string)
-	if !ok {
-		return id, srvErrors.ErrAuthInvalidToken
+	if claims.ID == "" {
+		return claims, srvErrors.ErrAuthInvalidToken
+	}
+
+	claims.userID, err = strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil || claims.userID == 0 {
+		return claims, srvErrors.ErrAuthInvalidToken
 	}
 
-	id, err := strconv.ParseUint(jti, 10, 64)
+	return claims, nil
+}
+
+func (a *Auth) issueTokens(ctx context.Context, user entity.User) (dto.AuthTokens, error) {
+	refresh, stored, err := a.newRefreshToken(ctx, user.ID)
 	if err != nil {
-		return id, srvErrors.ErrAuthInvalidToken
+		return dto.AuthTokens{}, err
+	}
+
+	if err := a.tokens.CreateRefresh(ctx, stored); err != nil {
+		a.logger.Error("failed to save refresh token", err, logger.UserID(user.ID), logger.Request(ctx))
+		return dto.AuthTokens{}, srvErrors.ErrUnexpected
 	}
 
-	return id, nil
+	return a.newTokens(ctx, user, refresh)
 }
 
-func (a *Auth) generateToken(u entity.User) (string, error) {
-	token := jwt.NewWithClaims(
-		jwt.SigningMethodHS256,
-		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24)),
-			ID:        strconv.FormatUint(u.ID, 10),
-		},
-	)
+func (a *Auth) newTokens(ctx context.Context, user entity.User, refresh string) (dto.AuthTokens, error) {
+	access, err := a.generateToken(ctx, user)
+	if err != nil {
+		return dto.AuthTokens{}, err
+	}
+
+	tokens := dto.AuthTokens{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    tokenType,
+		ExpiresIn:    int64(a.ttl.Access.Seconds()),
+	}
+
+	return tokens, nil
+}
+
+func (a *Auth) generateToken(ctx context.Context, user entity.User) (string, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		a.logger.Error("failed to generate token id", err, logger.Request(ctx))
+		return "", srvErrors.ErrUnexpected
+	}
 
-	tokenStr, err := token.SignedString([]byte(a.secret))
+	now := time.Now()
+	tokenStr, err := a.keys.Sign(accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(user.ID, 10),
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(a.ttl.Access)),
+		},
+		Role: user.Role,
+	})
 	if err != nil {
-		a.logger.Error("failed to generate token", err)
+		a.logger.Error("failed to generate token", err, logger.Request(ctx))
 		return "", srvErrors.ErrUnexpected
 	}
 
 	return tokenStr, nil
 }
 
+// newRefreshToken возвращает сам токен для клиента и его представление для хранения.
+func (a *Auth) newRefreshToken(ctx context.Context, userID uint64) (string, entity.RefreshToken, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		a.logger.Error("failed to generate refresh token", err, logger.Request(ctx))
+		return "", entity.RefreshToken{}, srvErrors.ErrUnexpected
+	}
+
+	stored := entity.RefreshToken{
+		UserID:  userID,
+		Hash:    hashToken(token),
+		Expires: time.Now().Add(a.ttl.Refresh),
+	}
+
+	return token, stored, nil
+}
+
+// rehash переводит хеш пароля на текущий алгоритм после успешного входа.
+// Ошибка не мешает входу, попытка повторится при следующем.
+func (a *Auth) rehash(ctx context.Context, userID uint64, password string) {
+	hash, err := a.hasher.Hash(password)
+	if err != nil {
+		a.logger.Error("failed to rehash password", err, logger.UserID(userID), logger.Request(ctx))
+		return
+	}
+
+	if err := a.repository.UpdateHash(ctx, userID, hash); err != nil {
+		a.logger.Error("failed to update password hash", err, logger.UserID(userID), logger.Request(ctx))
+	}
+}
+
+func (a *Auth) revokeAllRefresh(ctx context.Context, userID uint64) {
+	if err := a.tokens.RevokeAllRefresh(ctx, userID); err != nil {
+		a.logger.Error("failed to revoke user refresh tokens", err, logger.UserID(userID), logger.Request(ctx))
+	}
+}
+
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 func trimCredentials(c dto.Credentials) dto.Credentials {
 	return dto.Credentials{
 		Login:    strings.TrimSpace(c.Login),
@@ -173,15 +445,27 @@ func validateCredentials(c dto.Credentials) error {
 		return fmt.Errorf("%w: login is empty", srvErrors.ErrAuthInvalidCredentials)
 	}
 
-	if c.Password == "" {
+	if len(c.Login) > entity.UserMaxLoginLen {
+		return fmt.Errorf(
+			"%w: login too long, max %d characters",
+			srvErrors.ErrAuthInvalidCredentials,
+			entity.UserMaxLoginLen,
+		)
+	}
+
+	return validatePassword(c.Password)
+}
+
+func validatePassword(password string) error {
+	if password == "" {
 		return fmt.Errorf("%w: password is empty", srvErrors.ErrAuthInvalidCredentials)
 	}
 
-	if len(c.Login) > entity.UserMaxLoginLen {
+	if len(password) > entity.UserMaxPasswordLen {
 		return fmt.Errorf(
-			"%w: password too long, max %d characters",
+			"%w: password too long, max %d bytes",
 			srvErrors.ErrAuthInvalidCredentials,
-			entity.UserMaxLoginLen,
+			entity.UserMaxPasswordLen,
 		)
 	}
 
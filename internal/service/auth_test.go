@@ -15,13 +15,21 @@ import (
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/jwtkeys"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/password"
 	repErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/repository/errors"
 	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/mocks"
 )
 
+var (
+	testTokenTTL = TokenTTL{Access: 15 * time.Minute, Refresh: time.Hour}
+	testIP       = "192.0.2.1"
+	testHasher   = password.New(password.Params{Memory: 1024, Time: 1, Threads: 1, SaltLen: 16, KeyLen: 32})
+)
+
 func TestAuth_Register(t *testing.T) {
-	jwtSecret := "secret"
+	keys := testKeySet(t)
 
 	goodCredentials := dto.Credentials{Login: "testLogin", Password: "t1estP5assword"}
 
@@ -31,7 +39,7 @@ func TestAuth_Register(t *testing.T) {
 	}
 
 	tooLongPasswordCr := dto.Credentials{Login: "testLogin", Password: "p"}
-	for range 72 {
+	for range entity.UserMaxPasswordLen {
 		tooLongPasswordCr.Password += "p"
 	}
 
@@ -44,6 +52,7 @@ func TestAuth_Register(t *testing.T) {
 		name        string
 		credentials dto.Credentials
 		rSetup      func(t *testing.T) UserRepository
+		tSetup      func(t *testing.T) TokenRepository
 		lSetup      func(t *testing.T) Logger
 		want        want
 	}{
@@ -55,9 +64,21 @@ func TestAuth_Register(t *testing.T) {
 				repository := mocks.NewMockUserRepository(ctrl)
 				repository.EXPECT().
 					Create(gomock.All(), gomock.All()).
-					Return(entity.User{ID: 13}, nil)
+					DoAndReturn(func(_ context.Context, u entity.User) (entity.User, error) {
+						assert.False(t, testHasher.NeedsRehash(u.Hash), "Stored hash is current")
+						u.ID = 13
+						return u, nil
+					})
 				return repository
 			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					CreateRefresh(gomock.All(), gomock.All()).
+					Return(nil)
+				return tokens
+			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
@@ -82,6 +103,14 @@ func TestAuth_Register(t *testing.T) {
 					Times(0)
 				return repository
 			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					CreateRefresh(gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
@@ -105,6 +134,14 @@ func TestAuth_Register(t *testing.T) {
 					Times(0)
 				return repository
 			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					CreateRefresh(gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
@@ -128,6 +165,14 @@ func TestAuth_Register(t *testing.T) {
 					Times(0)
 				return repository
 			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					CreateRefresh(gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
@@ -151,6 +196,14 @@ func TestAuth_Register(t *testing.T) {
 					Times(0)
 				return repository
 			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					CreateRefresh(gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
@@ -174,6 +227,14 @@ func TestAuth_Register(t *testing.T) {
 					Return(entity.User{}, repErrors.ErrDuplicateKey)
 				return repository
 			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					CreateRefresh(gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
@@ -197,11 +258,49 @@ func TestAuth_Register(t *testing.T) {
 					Return(entity.User{}, fmt.Errorf("any error"))
 				return repository
 			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					CreateRefresh(gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Error("failed to create user", gomock.All())
+					Error("failed to create user", gomock.All(), gomock.Any())
+				return logger
+			},
+			want: want{
+				err: srvErrors.ErrUnexpected,
+			},
+		},
+		{
+			name:        "negative_save_refresh_token_error",
+			credentials: goodCredentials,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					Create(gomock.All(), gomock.All()).
+					Return(entity.User{ID: 13}, nil)
+				return repository
+			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					CreateRefresh(gomock.All(), gomock.All()).
+					Return(fmt.Errorf("any error"))
+				return tokens
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to save refresh token", gomock.All(), gomock.Any())
 				return logger
 			},
 			want: want{
@@ -213,34 +312,38 @@ func TestAuth_Register(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			repository := test.rSetup(t)
+			tokens := test.tSetup(t)
+			throttler := mocks.NewMockThrottler(gomock.NewController(t))
 			logger := test.lSetup(t)
 			ctx := context.Background()
 
-			authService := NewAuth(repository, logger, jwtSecret)
-			token, err := authService.Register(ctx, test.credentials)
+			authService := NewAuth(repository, tokens, testHasher, throttler, logger, keys, testTokenTTL)
+			result, err := authService.Register(ctx, test.credentials)
 
 			assert.ErrorIs(t, err, test.want.err, "Register user error")
 			if err != nil {
 				return
 			}
 
-			jt, err := jwt.Parse(token, func(t *jwt.Token) (any, error) {
-				return []byte(jwtSecret), nil
-			})
+			assert.NotEmpty(t, result.RefreshToken, "Refresh token")
+			assert.Equal(t, "Bearer", result.TokenType, "Token type")
+			assert.Equal(t, int64(testTokenTTL.Access.Seconds()), result.ExpiresIn, "Token lifetime")
+			claims, err := authService.parseToken(result.AccessToken)
 			require.Nil(t, err, "Parse token")
-			userID, err := parseID(jt)
-			require.Nil(t, err, "Get ID from token")
+			userID := claims.userID
 			assert.Equal(t, test.want.userID, userID, "Registered userID form token")
 		})
 	}
 }
 
 func TestAuth_Login(t *testing.T) {
-	jwtSecret := "secret"
+	keys := testKeySet(t)
 
 	goodCredentials := dto.Credentials{Login: "testLogin", Password: "t1estP5assword"}
-	hash, err := bcrypt.GenerateFromPassword([]byte("t1estP5assword"), bcrypt.DefaultCost)
+	hash, err := testHasher.Hash("t1estP5assword")
 	require.Nil(t, err, "Generate hash for entity")
+	legacyHash, err := bcrypt.GenerateFromPassword([]byte("t1estP5assword"), bcrypt.MinCost)
+	require.Nil(t, err, "Generate legacy hash for entity")
 
 	badPasswordCredentials := dto.Credentials{Login: "testLogin", Password: "badPassword"}
 
@@ -253,6 +356,8 @@ func TestAuth_Login(t *testing.T) {
 		name        string
 		credentials dto.Credentials
 		rSetup      func(t *testing.T) UserRepository
+		tSetup      func(t *testing.T) TokenRepository
+		thSetup     func(t *testing.T) Throttler
 		lSetup      func(t *testing.T) Logger
 		want        want
 	}{
@@ -264,9 +369,77 @@ func TestAuth_Login(t *testing.T) {
 				repository := mocks.NewMockUserRepository(ctrl)
 				repository.EXPECT().
 					FindByLogin(gomock.All(), goodCredentials.Login).
-					Return(entity.User{ID: 13, Hash: string(hash)}, nil)
+					Return(entity.User{ID: 13, Hash: hash}, nil)
+				return repository
+			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					CreateRefresh(gomock.All(), gomock.All()).
+					Return(nil)
+				return tokens
+			},
+			thSetup: func(t *testing.T) Throttler {
+				ctrl := gomock.NewController(t)
+				throttler := mocks.NewMockThrottler(ctrl)
+				throttler.EXPECT().
+					Allow(gomock.All(), goodCredentials.Login, testIP).
+					Return(nil)
+				throttler.EXPECT().
+					Succeed(gomock.All(), goodCredentials.Login)
+				return throttler
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				userID: 13,
+				err:    nil,
+			},
+		},
+		{
+			name:        "success_legacy_hash_upgraded",
+			credentials: goodCredentials,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					FindByLogin(gomock.All(), goodCredentials.Login).
+					Return(entity.User{ID: 13, Hash: string(legacyHash)}, nil)
+				repository.EXPECT().
+					UpdateHash(gomock.All(), uint64(13), gomock.All()).
+					DoAndReturn(func(_ context.Context, _ uint64, hash string) error {
+						ok, err := testHasher.Verify(goodCredentials.Password, hash)
+						assert.True(t, ok && err == nil, "Upgraded hash matches password")
+						assert.False(t, testHasher.NeedsRehash(hash), "Upgraded hash is current")
+						return nil
+					})
 				return repository
 			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					CreateRefresh(gomock.All(), gomock.All()).
+					Return(nil)
+				return tokens
+			},
+			thSetup: func(t *testing.T) Throttler {
+				ctrl := gomock.NewController(t)
+				throttler := mocks.NewMockThrottler(ctrl)
+				throttler.EXPECT().
+					Allow(gomock.All(), goodCredentials.Login, testIP).
+					Return(nil)
+				throttler.EXPECT().
+					Succeed(gomock.All(), goodCredentials.Login)
+				return throttler
+			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
@@ -280,6 +453,94 @@ func TestAuth_Login(t *testing.T) {
 				err:    nil,
 			},
 		},
+		{
+			name:        "success_legacy_hash_update_failed",
+			credentials: goodCredentials,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					FindByLogin(gomock.All(), goodCredentials.Login).
+					Return(entity.User{ID: 13, Hash: string(legacyHash)}, nil)
+				repository.EXPECT().
+					UpdateHash(gomock.All(), uint64(13), gomock.All()).
+					Return(fmt.Errorf("any error"))
+				return repository
+			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					CreateRefresh(gomock.All(), gomock.All()).
+					Return(nil)
+				return tokens
+			},
+			thSetup: func(t *testing.T) Throttler {
+				ctrl := gomock.NewController(t)
+				throttler := mocks.NewMockThrottler(ctrl)
+				throttler.EXPECT().
+					Allow(gomock.All(), goodCredentials.Login, testIP).
+					Return(nil)
+				throttler.EXPECT().
+					Succeed(gomock.All(), goodCredentials.Login)
+				return throttler
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to update password hash", gomock.All(), gomock.Any())
+				return logger
+			},
+			want: want{
+				userID: 13,
+				err:    nil,
+			},
+		},
+		{
+			name:        "negative_legacy_hash_bad_password",
+			credentials: badPasswordCredentials,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					FindByLogin(gomock.All(), badPasswordCredentials.Login).
+					Return(entity.User{ID: 13, Hash: string(legacyHash)}, nil)
+				repository.EXPECT().
+					UpdateHash(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					CreateRefresh(gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
+			thSetup: func(t *testing.T) Throttler {
+				ctrl := gomock.NewController(t)
+				throttler := mocks.NewMockThrottler(ctrl)
+				throttler.EXPECT().
+					Allow(gomock.All(), badPasswordCredentials.Login, testIP).
+					Return(nil)
+				throttler.EXPECT().
+					Fail(gomock.All(), badPasswordCredentials.Login, testIP)
+				return throttler
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				err: srvErrors.ErrAuthInvalidCredentials,
+			},
+		},
 		{
 			name:        "negative_user_not_found",
 			credentials: goodCredentials,
@@ -291,6 +552,24 @@ func TestAuth_Login(t *testing.T) {
 					Return(entity.User{}, repErrors.ErrNotFound)
 				return repository
 			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					CreateRefresh(gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
+			thSetup: func(t *testing.T) Throttler {
+				ctrl := gomock.NewController(t)
+				throttler := mocks.NewMockThrottler(ctrl)
+				throttler.EXPECT().
+					Allow(gomock.All(), goodCredentials.Login, testIP).
+					Return(nil)
+				throttler.EXPECT().
+					Fail(gomock.All(), goodCredentials.Login, testIP)
+				return throttler
+			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
@@ -311,9 +590,27 @@ func TestAuth_Login(t *testing.T) {
 				repository := mocks.NewMockUserRepository(ctrl)
 				repository.EXPECT().
 					FindByLogin(gomock.All(), badPasswordCredentials.Login).
-					Return(entity.User{ID: 13, Hash: string(hash)}, nil)
+					Return(entity.User{ID: 13, Hash: hash}, nil)
 				return repository
 			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					CreateRefresh(gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
+			thSetup: func(t *testing.T) Throttler {
+				ctrl := gomock.NewController(t)
+				throttler := mocks.NewMockThrottler(ctrl)
+				throttler.EXPECT().
+					Allow(gomock.All(), badPasswordCredentials.Login, testIP).
+					Return(nil)
+				throttler.EXPECT().
+					Fail(gomock.All(), badPasswordCredentials.Login, testIP)
+				return throttler
+			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
@@ -326,6 +623,45 @@ func TestAuth_Login(t *testing.T) {
 				err: srvErrors.ErrAuthInvalidCredentials,
 			},
 		},
+		{
+			name:        "negative_too_many_attempts",
+			credentials: goodCredentials,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					FindByLogin(gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					CreateRefresh(gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
+			thSetup: func(t *testing.T) Throttler {
+				ctrl := gomock.NewController(t)
+				throttler := mocks.NewMockThrottler(ctrl)
+				throttler.EXPECT().
+					Allow(gomock.All(), goodCredentials.Login, testIP).
+					Return(&srvErrors.RetryError{Err: srvErrors.ErrAuthTooManyAttempts, After: time.Minute})
+				return throttler
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				err: srvErrors.ErrAuthTooManyAttempts,
+			},
+		},
 		{
 			name:        "negative_unexpected_repository_error",
 			credentials: goodCredentials,
@@ -337,11 +673,30 @@ func TestAuth_Login(t *testing.T) {
 					Return(entity.User{}, fmt.Errorf("any error"))
 				return repository
 			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					CreateRefresh(gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
+			thSetup: func(t *testing.T) Throttler {
+				ctrl := gomock.NewController(t)
+				throttler := mocks.NewMockThrottler(ctrl)
+				throttler.EXPECT().
+					Allow(gomock.All(), goodCredentials.Login, testIP).
+					Return(nil)
+				throttler.EXPECT().
+					Fail(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return throttler
+			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Error("failed to find user", gomock.All())
+					Error("failed to find user", gomock.All(), gomock.Any())
 				return logger
 			},
 			want: want{
@@ -353,34 +708,38 @@ func TestAuth_Login(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			repository := test.rSetup(t)
+			tokens := test.tSetup(t)
+			throttler := test.thSetup(t)
 			logger := test.lSetup(t)
 			ctx := context.Background()
 
-			authService := NewAuth(repository, logger, jwtSecret)
-			token, err := authService.Login(ctx, test.credentials)
+			authService := NewAuth(repository, tokens, testHasher, throttler, logger, keys, testTokenTTL)
+			result, err := authService.Login(ctx, test.credentials, testIP)
 
 			assert.ErrorIs(t, err, test.want.err, "Login user error")
 			if err != nil {
 				return
 			}
 
-			jt, err := jwt.Parse(token, func(t *jwt.Token) (any, error) {
-				return []byte(jwtSecret), nil
-			})
+			assert.NotEmpty(t, result.RefreshToken, "Refresh token")
+			assert.Equal(t, "Bearer", result.TokenType, "Token type")
+			assert.Equal(t, int64(testTokenTTL.Access.Seconds()), result.ExpiresIn, "Token lifetime")
+			claims, err := authService.parseToken(result.AccessToken)
 			require.Nil(t, err, "Parse token")
-			userID, err := parseID(jt)
-			require.Nil(t, err, "Get ID from token")
+			userID := claims.userID
 			assert.Equal(t, test.want.userID, userID, "Logged in userID form token")
 		})
 	}
 }
 
 func TestAuth_User(t *testing.T) {
-	jwtSecret := "secret"
-	goodToken := testGenerateToken(t, 13, jwtSecret, false)
-	expiredToken := testGenerateToken(t, 13, jwtSecret, true)
-	badSignedToken := testGenerateToken(t, 13, "bad secret", false)
-	badIDtoken := testGenerateToken(t, 0, jwtSecret, false)
+	keys := testKeySet(t)
+	goodToken := testGenerateToken(t, 13, keys, false)
+	expiredToken := testGenerateToken(t, 13, keys, true)
+	badSignedToken := testGenerateToken(t, 13, testKeySet(t), false)
+	badIDtoken := testGenerateToken(t, 0, keys, false)
+	validAfterPast := time.Now().Add(-24 * time.Hour)
+	validAfterNow := time.Now()
 
 	type want struct {
 		user entity.User
@@ -391,6 +750,7 @@ func TestAuth_User(t *testing.T) {
 		name   string
 		token  string
 		rSetup func(t *testing.T) UserRepository
+		tSetup func(t *testing.T) TokenRepository
 		lSetup func(t *testing.T) Logger
 		want   want
 	}{
@@ -405,6 +765,14 @@ func TestAuth_User(t *testing.T) {
 					Return(entity.User{ID: 13}, nil)
 				return repository
 			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					IsAccessRevoked(gomock.All(), "jti").
+					Return(false, nil)
+				return tokens
+			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
@@ -429,6 +797,14 @@ func TestAuth_User(t *testing.T) {
 					Times(0)
 				return repository
 			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					IsAccessRevoked(gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
@@ -453,6 +829,14 @@ func TestAuth_User(t *testing.T) {
 					Times(0)
 				return repository
 			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					IsAccessRevoked(gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
@@ -477,6 +861,110 @@ func TestAuth_User(t *testing.T) {
 					Times(0)
 				return repository
 			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					IsAccessRevoked(gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				user: entity.User{},
+				err:  srvErrors.ErrAuthInvalidToken,
+			},
+		},
+		{
+			name:  "negative_token_revoked",
+			token: goodToken,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					GetByID(gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					IsAccessRevoked(gomock.All(), "jti").
+					Return(true, nil)
+				return tokens
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				user: entity.User{},
+				err:  srvErrors.ErrAuthInvalidToken,
+			},
+		},
+		{
+			name:  "success_issued_after_password_change",
+			token: goodToken,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					GetByID(gomock.All(), uint64(13)).
+					Return(entity.User{ID: 13, TokensValidAfter: &validAfterPast}, nil)
+				return repository
+			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					IsAccessRevoked(gomock.All(), "jti").
+					Return(false, nil)
+				return tokens
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				user: entity.User{ID: 13, TokensValidAfter: &validAfterPast},
+				err:  nil,
+			},
+		},
+		{
+			name:  "negative_issued_before_password_change",
+			token: goodToken,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					GetByID(gomock.All(), uint64(13)).
+					Return(entity.User{ID: 13, TokensValidAfter: &validAfterNow}, nil)
+				return repository
+			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					IsAccessRevoked(gomock.All(), "jti").
+					Return(false, nil)
+				return tokens
+			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
@@ -501,11 +989,19 @@ func TestAuth_User(t *testing.T) {
 					Return(entity.User{}, fmt.Errorf("any error"))
 				return repository
 			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					IsAccessRevoked(gomock.All(), "jti").
+					Return(false, nil)
+				return tokens
+			},
 			lSetup: func(t *testing.T) Logger {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Error("failed to find user by id", gomock.All())
+					Error("failed to find user by id", gomock.All(), gomock.Any())
 				return logger
 			},
 			want: want{
@@ -518,10 +1014,12 @@ func TestAuth_User(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			repository := test.rSetup(t)
+			tokens := test.tSetup(t)
+			throttler := mocks.NewMockThrottler(gomock.NewController(t))
 			logger := test.lSetup(t)
 			ctx := context.Background()
 
-			authService := NewAuth(repository, logger, jwtSecret)
+			authService := NewAuth(repository, tokens, testHasher, throttler, logger, keys, testTokenTTL)
 			user, err := authService.User(ctx, test.token)
 
 			assert.Equal(t, test.want.user, user, "Get user entity")
@@ -530,7 +1028,457 @@ func TestAuth_User(t *testing.T) {
 	}
 }
 
-func testGenerateToken(t *testing.T, id uint64, secret string, expired bool) string {
+func TestAuth_Refresh(t *testing.T) {
+	keys := testKeySet(t)
+	refreshToken := "refreshToken"
+	refreshHash := hashToken(refreshToken)
+	revokedAt := time.Now().Add(-time.Minute)
+
+	active := entity.RefreshToken{ID: 7, UserID: 13, Hash: refreshHash, Expires: time.Now().Add(time.Hour)}
+	expired := entity.RefreshToken{ID: 7, UserID: 13, Hash: refreshHash, Expires: time.Now().Add(-time.Hour)}
+	revoked := entity.RefreshToken{
+		ID:      7,
+		UserID:  13,
+		Hash:    refreshHash,
+		Expires: time.Now().Add(time.Hour),
+		Revoked: &revokedAt,
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		rSetup func(t *testing.T) UserRepository
+		tSetup func(t *testing.T) TokenRepository
+		lSetup func(t *testing.T) Logger
+		err    error
+	}{
+		{
+			name:  "success",
+			token: refreshToken,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					GetByID(gomock.All(), uint64(13)).
+					Return(entity.User{ID: 13, Role: entity.UserRoleSupport}, nil)
+				return repository
+			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					FindRefresh(gomock.All(), refreshHash).
+					Return(active, nil)
+				tokens.EXPECT().
+					RotateRefresh(gomock.All(), uint64(7), gomock.All()).
+					DoAndReturn(func(_ context.Context, _ uint64, next entity.RefreshToken) error {
+						assert.Equal(t, uint64(13), next.UserID, "Next refresh token owner")
+						assert.NotEqual(t, refreshHash, next.Hash, "Next refresh token hash")
+						return nil
+					})
+				return tokens
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			err: nil,
+		},
+		{
+			name:  "negative_empty_token",
+			token: "",
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				return mocks.NewMockUserRepository(ctrl)
+			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					FindRefresh(gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			err: srvErrors.ErrAuthInvalidToken,
+		},
+		{
+			name:  "negative_token_not_found",
+			token: refreshToken,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				return mocks.NewMockUserRepository(ctrl)
+			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					FindRefresh(gomock.All(), refreshHash).
+					Return(entity.RefreshToken{}, repErrors.ErrNotFound)
+				tokens.EXPECT().
+					RotateRefresh(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			err: srvErrors.ErrAuthInvalidToken,
+		},
+		{
+			name:  "negative_token_expired",
+			token: refreshToken,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				return mocks.NewMockUserRepository(ctrl)
+			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					FindRefresh(gomock.All(), refreshHash).
+					Return(expired, nil)
+				tokens.EXPECT().
+					RotateRefresh(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			err: srvErrors.ErrAuthTokenExpired,
+		},
+		{
+			name:  "negative_revoked_token_reused",
+			token: refreshToken,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				return mocks.NewMockUserRepository(ctrl)
+			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					FindRefresh(gomock.All(), refreshHash).
+					Return(revoked, nil)
+				tokens.EXPECT().
+					RevokeAllRefresh(gomock.All(), uint64(13)).
+					Return(nil)
+				tokens.EXPECT().
+					RotateRefresh(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			err: srvErrors.ErrAuthInvalidToken,
+		},
+		{
+			name:  "negative_user_deleted",
+			token: refreshToken,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					GetByID(gomock.All(), uint64(13)).
+					Return(entity.User{}, repErrors.ErrNotFound)
+				return repository
+			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					FindRefresh(gomock.All(), refreshHash).
+					Return(active, nil)
+				tokens.EXPECT().
+					RotateRefresh(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			err: srvErrors.ErrAuthInvalidToken,
+		},
+		{
+			name:  "negative_concurrent_rotation",
+			token: refreshToken,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					GetByID(gomock.All(), uint64(13)).
+					Return(entity.User{ID: 13, Role: entity.UserRoleSupport}, nil)
+				return repository
+			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					FindRefresh(gomock.All(), refreshHash).
+					Return(active, nil)
+				tokens.EXPECT().
+					RotateRefresh(gomock.All(), uint64(7), gomock.All()).
+					Return(repErrors.ErrNoRowsUpdated)
+				tokens.EXPECT().
+					RevokeAllRefresh(gomock.All(), uint64(13)).
+					Return(nil)
+				return tokens
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			err: srvErrors.ErrAuthInvalidToken,
+		},
+		{
+			name:  "negative_unexpected_repository_error",
+			token: refreshToken,
+			rSetup: func(t *testing.T) UserRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockUserRepository(ctrl)
+				repository.EXPECT().
+					GetByID(gomock.All(), uint64(13)).
+					Return(entity.User{ID: 13, Role: entity.UserRoleSupport}, nil)
+				return repository
+			},
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					FindRefresh(gomock.All(), refreshHash).
+					Return(active, nil)
+				tokens.EXPECT().
+					RotateRefresh(gomock.All(), uint64(7), gomock.All()).
+					Return(fmt.Errorf("any error"))
+				return tokens
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to rotate refresh token", gomock.All(), gomock.Any())
+				return logger
+			},
+			err: srvErrors.ErrUnexpected,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := test.rSetup(t)
+			tokens := test.tSetup(t)
+			throttler := mocks.NewMockThrottler(gomock.NewController(t))
+			logger := test.lSetup(t)
+			ctx := context.Background()
+
+			authService := NewAuth(repository, tokens, testHasher, throttler, logger, keys, testTokenTTL)
+			result, err := authService.Refresh(ctx, test.token)
+
+			assert.ErrorIs(t, err, test.err, "Refresh token error")
+			if err != nil {
+				return
+			}
+
+			assert.NotEqual(t, refreshToken, result.RefreshToken, "Rotated refresh token")
+			claims, err := authService.parseToken(result.AccessToken)
+			require.Nil(t, err, "Parse token")
+			assert.Equal(t, uint64(13), claims.userID, "Refreshed userID form token")
+			assert.Equal(t, entity.UserRoleSupport, claims.Role, "Refreshed role form token")
+		})
+	}
+}
+
+func TestAuth_Logout(t *testing.T) {
+	keys := testKeySet(t)
+	goodToken := testGenerateToken(t, 13, keys, false)
+	expiredToken := testGenerateToken(t, 13, keys, true)
+
+	tests := []struct {
+		name         string
+		token        string
+		refreshToken string
+		tSetup       func(t *testing.T) TokenRepository
+		lSetup       func(t *testing.T) Logger
+		err          error
+	}{
+		{
+			name:         "success",
+			token:        goodToken,
+			refreshToken: "refreshToken",
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					RevokeAccess(gomock.All(), "jti", uint64(13), gomock.All()).
+					Return(nil)
+				tokens.EXPECT().
+					RevokeRefresh(gomock.All(), uint64(13), hashToken("refreshToken")).
+					Return(nil)
+				return tokens
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			err: nil,
+		},
+		{
+			name:         "success_without_refresh_token",
+			token:        goodToken,
+			refreshToken: "",
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					RevokeAccess(gomock.All(), "jti", uint64(13), gomock.All()).
+					Return(nil)
+				tokens.EXPECT().
+					RevokeRefresh(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			err: nil,
+		},
+		{
+			name:         "negative_token_expired",
+			token:        expiredToken,
+			refreshToken: "refreshToken",
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					RevokeAccess(gomock.All(), gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			err: srvErrors.ErrAuthTokenExpired,
+		},
+		{
+			name:         "negative_unexpected_repository_error",
+			token:        goodToken,
+			refreshToken: "refreshToken",
+			tSetup: func(t *testing.T) TokenRepository {
+				ctrl := gomock.NewController(t)
+				tokens := mocks.NewMockTokenRepository(ctrl)
+				tokens.EXPECT().
+					RevokeAccess(gomock.All(), "jti", uint64(13), gomock.All()).
+					Return(fmt.Errorf("any error"))
+				tokens.EXPECT().
+					RevokeRefresh(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return tokens
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to revoke access token", gomock.All(), gomock.Any())
+				return logger
+			},
+			err: srvErrors.ErrUnexpected,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repository := mocks.NewMockUserRepository(ctrl)
+			tokens := test.tSetup(t)
+			throttler := mocks.NewMockThrottler(gomock.NewController(t))
+			logger := test.lSetup(t)
+			ctx := context.Background()
+
+			authService := NewAuth(repository, tokens, testHasher, throttler, logger, keys, testTokenTTL)
+			err := authService.Logout(ctx, test.token, test.refreshToken)
+
+			assert.ErrorIs(t, err, test.err, "Logout error")
+		})
+	}
+}
+
+func TestAuth_JWKS(t *testing.T) {
+	signing, err := jwtkeys.Generate()
+	require.Nil(t, err, "Signing key generation")
+	previous, err := jwtkeys.Generate()
+	require.Nil(t, err, "Previous key generation")
+	keys, err := jwtkeys.NewKeySet(signing, previous)
+	require.Nil(t, err, "Key set")
+
+	ctrl := gomock.NewController(t)
+	authService := NewAuth(
+		mocks.NewMockUserRepository(ctrl),
+		mocks.NewMockTokenRepository(ctrl),
+		testHasher,
+		mocks.NewMockThrottler(ctrl),
+		mocks.NewMockLogger(ctrl),
+		keys,
+		testTokenTTL,
+	)
+
+	jwks := authService.JWKS()
+	require.Len(t, jwks.Keys, 2, "Published keys")
+	assert.Equal(t, signing.ID, jwks.Keys[0].Kid, "Signing key id")
+	assert.Equal(t, previous.ID, jwks.Keys[1].Kid, "Previous key id")
+}
+
+func testGenerateToken(t *testing.T, id uint64, keys *jwtkeys.KeySet, expired bool) string {
 	expires := time.Now().Add((-1) * time.Hour)
 	if !expired {
 		expires = expires.Add(25 * time.Hour)
@@ -541,16 +1489,22 @@ func testGenerateToken(t *testing.T, id uint64, secret string, expired bool) str
 		idStr = ""
 	}
 
-	token := jwt.NewWithClaims(
-		jwt.SigningMethodHS256,
-		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expires),
-			ID:        idStr,
-		},
-	)
-
-	tokenStr, err := token.SignedString([]byte(secret))
+	tokenStr, err := keys.Sign(jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(expires),
+		IssuedAt:  jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+		Subject:   idStr,
+		ID:        "jti",
+	})
 	require.Nil(t, err, "Token generation")
 
 	return tokenStr
 }
+
+func testKeySet(t *testing.T) *jwtkeys.KeySet {
+	key, err := jwtkeys.Generate()
+	require.Nil(t, err, "Key generation")
+	keys, err := jwtkeys.NewKeySet(key)
+	require.Nil(t, err, "Key set")
+
+	return keys
+}
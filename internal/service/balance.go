@@ -2,15 +2,24 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/middleware"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
-	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+	repErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/repository/errors"
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
 )
 
 type BalanceRepository interface {
 	GetByUser(ctx context.Context, userID uint64) (entity.Balance, error)
+	Rebuild(ctx context.Context, userID uint64) (entity.BalanceReconciliation, error)
+	Adjust(ctx context.Context, a entity.BalanceAdjustment) (entity.BalanceAdjustment, entity.Balance, error)
+	History(ctx context.Context, userID uint64, f entity.LedgerFilter) ([]entity.LedgerEntry, error)
 }
 
 type Balance struct {
@@ -23,16 +32,19 @@ func NewBalance(r BalanceRepository, l Logger) *Balance {
 }
 
 func (b *Balance) UserBalance(ctx context.Context) (balance dto.Balance, err error) {
+	ctx, span := tracer.Start(ctx, "Balance.UserBalance")
+	defer span.End()
+
 	userID, ok := ctx.Value(middleware.KeyUserID).(uint64)
 	if !ok {
-		b.logger.Error("failed to get user id", errors.ErrUnexpected)
-		return balance, errors.ErrUnexpected
+		b.logger.Error("failed to get user id", srvErrors.ErrUnexpected, logger.Request(ctx))
+		return balance, srvErrors.ErrUnexpected
 	}
 
 	entity, err := b.repository.GetByUser(ctx, userID)
 	if err != nil {
-		b.logger.Error("failed to get user balance", err)
-		return balance, errors.ErrUnexpected
+		b.logger.Error("failed to get user balance", err, logger.UserID(userID), logger.Request(ctx))
+		return balance, srvErrors.ErrUnexpected
 	}
 
 	balance.Current = entity.Balance
@@ -40,3 +52,160 @@ func (b *Balance) UserBalance(ctx context.Context) (balance dto.Balance, err err
 
 	return balance, nil
 }
+
+// Reconcile пересчитывает баланс пользователя по журналу операций.
+// Если сохраненный баланс разошелся с журналом, он перезаписывается,
+// а расхождение логируется.
+func (b *Balance) Reconcile(ctx context.Context, userID uint64) (entity.BalanceReconciliation, error) {
+	ctx, span := tracer.Start(ctx, "Balance.Reconcile")
+	defer span.End()
+
+	rec, err := b.repository.Rebuild(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repErrors.ErrNotFound) {
+			return rec, srvErrors.ErrUserNotFound
+		}
+		b.logger.Error("failed to rebuild user balance", err, logger.UserID(userID), logger.Request(ctx))
+		return rec, srvErrors.ErrUnexpected
+	}
+
+	if !rec.Consistent() {
+		err = fmt.Errorf(
+			"%w: user#%d cached %s/%s, ledger %s/%s",
+			srvErrors.ErrBalanceMismatch,
+			userID,
+			rec.Cached.Balance,
+			rec.Cached.Debited,
+			rec.Ledger.Balance,
+			rec.Ledger.Debited,
+		)
+		b.logger.Error("user balance diverged from ledger", err, logger.UserID(userID), logger.Request(ctx))
+	}
+
+	return rec, nil
+}
+
+// Adjust вручную начисляет или списывает баллы пользователя userID. Оператором
+// считается авторизованный пользователь, без него корректировка не выполняется.
+func (b *Balance) Adjust(
+	ctx context.Context,
+	userID uint64,
+	req dto.BalanceAdjustmentReq,
+) (adj dto.BalanceAdjustment, err error) {
+	ctx, span := tracer.Start(ctx, "Balance.Adjust")
+	defer span.End()
+
+	operatorID, ok := ctx.Value(middleware.KeyUserID).(uint64)
+	if !ok {
+		return adj, srvErrors.ErrAdjustmentOperatorRequired
+	}
+
+	if req.Amount == 0 {
+		return adj, srvErrors.ErrAdjustmentInvalidAmount
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return adj, fmt.Errorf("%w: reason is empty", srvErrors.ErrAdjustmentInvalidReason)
+	}
+	if utf8.RuneCountInString(reason) > entity.BalanceAdjustmentMaxReasonLen {
+		return adj, fmt.Errorf(
+			"%w: reason too long, max %d characters",
+			srvErrors.ErrAdjustmentInvalidReason,
+			entity.BalanceAdjustmentMaxReasonLen,
+		)
+	}
+
+	created, balance, err := b.repository.Adjust(ctx, entity.BalanceAdjustment{
+		UserID:     userID,
+		Amount:     req.Amount,
+		Reason:     reason,
+		OperatorID: operatorID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repErrors.ErrNotFound):
+			return adj, srvErrors.ErrUserNotFound
+		case errors.Is(err, repErrors.ErrNoRowsUpdated):
+			return adj, srvErrors.ErrAdjustmentInsufficientFunds
+		}
+		b.logger.Error("failed to adjust user balance", err, logger.UserID(userID), logger.Request(ctx))
+		return adj, srvErrors.ErrUnexpected
+	}
+
+	adj = dto.BalanceAdjustment{
+		ID:         created.ID,
+		UserID:     created.UserID,
+		Amount:     created.Amount,
+		Reason:     created.Reason,
+		OperatorID: created.OperatorID,
+		Current:    balance.Balance,
+		Withdrawn:  balance.Debited,
+		Created:    created.Created,
+	}
+
+	return adj, nil
+}
+
+// History возвращает журнал операций по балансу пользователя, включая ручные корректировки.
+func (b *Balance) History(ctx context.Context, q dto.ListQuery) (page dto.LedgerPage, err error) {
+	ctx, span := tracer.Start(ctx, "Balance.History")
+	defer span.End()
+
+	userID, ok := ctx.Value(middleware.KeyUserID).(uint64)
+	if !ok {
+		b.logger.Error("failed to get user id", srvErrors.ErrUnexpected, logger.Request(ctx))
+		return page, srvErrors.ErrUnexpected
+	}
+
+	filter, err := ledgerFilter(q)
+	if err != nil {
+		return page, err
+	}
+	limit := filter.Limit
+	// Лишняя запись показывает, есть ли следующая страница.
+	filter.Limit++
+
+	entries, err := b.repository.History(ctx, userID, filter)
+	if err != nil {
+		b.logger.Error("failed to get user balance history", err, logger.UserID(userID), logger.Request(ctx))
+		return page, srvErrors.ErrUnexpected
+	}
+
+	if len(entries) > limit {
+		entries = entries[:limit]
+		last := entries[limit-1]
+		page.NextCursor = encodeCursor(entity.LedgerCursor{Created: last.Created, ID: last.ID})
+	}
+
+	for _, entry := range entries {
+		page.Entries = append(page.Entries, dto.LedgerEntry{
+			Kind:    entry.Kind,
+			Amount:  entry.Amount,
+			Order:   entry.OrderNumber,
+			Reason:  entry.Reason,
+			Created: entry.Created,
+		})
+	}
+
+	return page, nil
+}
+
+func ledgerFilter(q dto.ListQuery) (f entity.LedgerFilter, err error) {
+	var after entity.LedgerCursor
+
+	f.Limit, f.Ascending, err = listParams(q, &after)
+	if err != nil {
+		return f, err
+	}
+	if q.Cursor != "" {
+		if after.Created.IsZero() || after.ID == 0 {
+			return f, fmt.Errorf("%w: invalid cursor", srvErrors.ErrListInvalidQuery)
+		}
+		f.After = &after
+	}
+
+	f.From, f.To = q.From, q.To
+
+	return f, nil
+}
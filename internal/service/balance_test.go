@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -11,6 +13,8 @@ import (
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/middleware"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+	repErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/repository/errors"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/mocks"
 )
@@ -39,7 +43,7 @@ func TestBalance_UserBalance(t *testing.T) {
 				repository := mocks.NewMockBalanceRepository(ctrl)
 				repository.EXPECT().
 					GetByUser(gomock.All(), userID).
-					Return(entity.Balance{Balance: 599.99, Debited: 400}, nil)
+					Return(entity.Balance{Balance: money.New(599, 99), Debited: money.New(400, 0)}, nil)
 				return repository
 			},
 			lSetup: func(t *testing.T) Logger {
@@ -51,7 +55,7 @@ func TestBalance_UserBalance(t *testing.T) {
 				return logger
 			},
 			want: want{
-				balance: dto.Balance{Current: 599.99, Withdrawn: 400},
+				balance: dto.Balance{Current: money.New(599, 99), Withdrawn: money.New(400, 0)},
 				err:     nil,
 			},
 		},
@@ -70,7 +74,7 @@ func TestBalance_UserBalance(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Error("failed to get user id", gomock.All())
+					Error("failed to get user id", gomock.All(), gomock.Any())
 				return logger
 			},
 			want: want{
@@ -93,7 +97,7 @@ func TestBalance_UserBalance(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Error("failed to get user balance", gomock.All())
+					Error("failed to get user balance", gomock.All(), gomock.Any())
 				return logger
 			},
 			want: want{
@@ -116,3 +120,478 @@ func TestBalance_UserBalance(t *testing.T) {
 		})
 	}
 }
+
+func TestBalance_Reconcile(t *testing.T) {
+	userID := uint64(13)
+	consistent := entity.BalanceReconciliation{
+		Cached: entity.Balance{UserID: userID, Balance: money.New(599, 99), Debited: money.New(400, 0)},
+		Ledger: entity.Balance{UserID: userID, Balance: money.New(599, 99), Debited: money.New(400, 0)},
+	}
+	diverged := entity.BalanceReconciliation{
+		Cached: entity.Balance{UserID: userID, Balance: money.New(999, 99), Debited: money.New(0, 0)},
+		Ledger: entity.Balance{UserID: userID, Balance: money.New(599, 99), Debited: money.New(400, 0)},
+	}
+
+	type want struct {
+		rec entity.BalanceReconciliation
+		err error
+	}
+
+	tests := []struct {
+		name   string
+		rSetup func(t *testing.T) BalanceRepository
+		lSetup func(t *testing.T) Logger
+		want   want
+	}{
+		{
+			name: "success_consistent",
+			rSetup: func(t *testing.T) BalanceRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockBalanceRepository(ctrl)
+				repository.EXPECT().
+					Rebuild(gomock.All(), userID).
+					Return(consistent, nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				rec: consistent,
+				err: nil,
+			},
+		},
+		{
+			name: "success_diverged",
+			rSetup: func(t *testing.T) BalanceRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockBalanceRepository(ctrl)
+				repository.EXPECT().
+					Rebuild(gomock.All(), userID).
+					Return(diverged, nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("user balance diverged from ledger", gomock.All(), gomock.Any())
+				return logger
+			},
+			want: want{
+				rec: diverged,
+				err: nil,
+			},
+		},
+		{
+			name: "negative_user_not_found",
+			rSetup: func(t *testing.T) BalanceRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockBalanceRepository(ctrl)
+				repository.EXPECT().
+					Rebuild(gomock.All(), userID).
+					Return(entity.BalanceReconciliation{}, fmt.Errorf("wrapped: %w", repErrors.ErrNotFound))
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				rec: entity.BalanceReconciliation{},
+				err: errors.ErrUserNotFound,
+			},
+		},
+		{
+			name: "negative_repository_error",
+			rSetup: func(t *testing.T) BalanceRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockBalanceRepository(ctrl)
+				repository.EXPECT().
+					Rebuild(gomock.All(), userID).
+					Return(entity.BalanceReconciliation{}, fmt.Errorf("any error"))
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to rebuild user balance", gomock.All(), gomock.Any())
+				return logger
+			},
+			want: want{
+				rec: entity.BalanceReconciliation{},
+				err: errors.ErrUnexpected,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := test.rSetup(t)
+			logger := test.lSetup(t)
+
+			balanceService := NewBalance(repository, logger)
+			rec, err := balanceService.Reconcile(context.Background(), userID)
+
+			assert.Equal(t, test.want.rec, rec, "Balance reconciliation")
+			assert.ErrorIs(t, err, test.want.err, "Balance reconciliation error")
+		})
+	}
+}
+
+func TestBalance_Adjust(t *testing.T) {
+	operatorID := uint64(7)
+	operatorCtx := context.WithValue(context.Background(), middleware.KeyUserID, operatorID)
+	created := time.Date(2025, 10, 14, 10, 0, 0, 0, time.UTC)
+	adjustment := entity.BalanceAdjustment{
+		UserID:     13,
+		Amount:     money.New(-50, 0),
+		Reason:     "mistaken accrual for order 5062821234567892",
+		OperatorID: operatorID,
+	}
+
+	type want struct {
+		adj dto.BalanceAdjustment
+		err error
+	}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		req    dto.BalanceAdjustmentReq
+		rSetup func(t *testing.T) BalanceRepository
+		lSetup func(t *testing.T) Logger
+		want   want
+	}{
+		{
+			name: "success",
+			ctx:  operatorCtx,
+			req:  dto.BalanceAdjustmentReq{Amount: money.New(-50, 0), Reason: " mistaken accrual for order 5062821234567892 "},
+			rSetup: func(t *testing.T) BalanceRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockBalanceRepository(ctrl)
+				saved := adjustment
+				saved.ID, saved.Created = 3, created
+				repository.EXPECT().
+					Adjust(gomock.All(), adjustment).
+					Return(saved, entity.Balance{UserID: 13, Balance: money.New(450, 0), Debited: money.New(100, 0)}, nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				adj: dto.BalanceAdjustment{
+					ID:         3,
+					UserID:     13,
+					Amount:     money.New(-50, 0),
+					Reason:     "mistaken accrual for order 5062821234567892",
+					OperatorID: operatorID,
+					Current:    money.New(450, 0),
+					Withdrawn:  money.New(100, 0),
+					Created:    created,
+				},
+				err: nil,
+			},
+		},
+		{
+			name: "negative_without_operator",
+			ctx:  context.Background(),
+			req:  dto.BalanceAdjustmentReq{Amount: money.New(50, 0), Reason: "compensation"},
+			rSetup: func(t *testing.T) BalanceRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockBalanceRepository(ctrl)
+				repository.EXPECT().
+					Adjust(gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{err: errors.ErrAdjustmentOperatorRequired},
+		},
+		{
+			name: "negative_zero_amount",
+			ctx:  operatorCtx,
+			req:  dto.BalanceAdjustmentReq{Amount: 0, Reason: "compensation"},
+			rSetup: func(t *testing.T) BalanceRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockBalanceRepository(ctrl)
+				repository.EXPECT().
+					Adjust(gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{err: errors.ErrAdjustmentInvalidAmount},
+		},
+		{
+			name: "negative_empty_reason",
+			ctx:  operatorCtx,
+			req:  dto.BalanceAdjustmentReq{Amount: money.New(50, 0), Reason: "  "},
+			rSetup: func(t *testing.T) BalanceRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockBalanceRepository(ctrl)
+				repository.EXPECT().
+					Adjust(gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{err: errors.ErrAdjustmentInvalidReason},
+		},
+		{
+			name: "negative_long_reason",
+			ctx:  operatorCtx,
+			req: dto.BalanceAdjustmentReq{
+				Amount: money.New(50, 0),
+				Reason: strings.Repeat("я", entity.BalanceAdjustmentMaxReasonLen+1),
+			},
+			rSetup: func(t *testing.T) BalanceRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockBalanceRepository(ctrl)
+				repository.EXPECT().
+					Adjust(gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{err: errors.ErrAdjustmentInvalidReason},
+		},
+		{
+			name: "negative_user_not_found",
+			ctx:  operatorCtx,
+			req:  dto.BalanceAdjustmentReq{Amount: money.New(-50, 0), Reason: adjustment.Reason},
+			rSetup: func(t *testing.T) BalanceRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockBalanceRepository(ctrl)
+				repository.EXPECT().
+					Adjust(gomock.All(), adjustment).
+					Return(entity.BalanceAdjustment{}, entity.Balance{}, repErrors.ErrNotFound)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{err: errors.ErrUserNotFound},
+		},
+		{
+			name: "negative_insufficient_funds",
+			ctx:  operatorCtx,
+			req:  dto.BalanceAdjustmentReq{Amount: money.New(-50, 0), Reason: adjustment.Reason},
+			rSetup: func(t *testing.T) BalanceRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockBalanceRepository(ctrl)
+				repository.EXPECT().
+					Adjust(gomock.All(), adjustment).
+					Return(entity.BalanceAdjustment{}, entity.Balance{}, repErrors.ErrNoRowsUpdated)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{err: errors.ErrAdjustmentInsufficientFunds},
+		},
+		{
+			name: "negative_repository_error",
+			ctx:  operatorCtx,
+			req:  dto.BalanceAdjustmentReq{Amount: money.New(-50, 0), Reason: adjustment.Reason},
+			rSetup: func(t *testing.T) BalanceRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockBalanceRepository(ctrl)
+				repository.EXPECT().
+					Adjust(gomock.All(), adjustment).
+					Return(entity.BalanceAdjustment{}, entity.Balance{}, fmt.Errorf("any error"))
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to adjust user balance", gomock.All(), gomock.Any())
+				return logger
+			},
+			want: want{err: errors.ErrUnexpected},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			balanceService := NewBalance(test.rSetup(t), test.lSetup(t))
+			adj, err := balanceService.Adjust(test.ctx, 13, test.req)
+
+			assert.Equal(t, test.want.adj, adj, "Balance adjustment dto")
+			assert.ErrorIs(t, err, test.want.err, "Balance adjustment error")
+		})
+	}
+}
+
+func TestBalance_History(t *testing.T) {
+	userID := uint64(13)
+	userIDctx := context.WithValue(context.Background(), middleware.KeyUserID, userID)
+	created := time.Date(2025, 10, 14, 10, 0, 0, 0, time.UTC)
+	entries := []entity.LedgerEntry{
+		{
+			ID:      5,
+			UserID:  userID,
+			Kind:    entity.LedgerKindAdjustment,
+			Amount:  money.New(-50, 0),
+			Reason:  "mistake",
+			Created: created,
+		},
+		{
+			ID:          4,
+			UserID:      userID,
+			Kind:        entity.LedgerKindAccrual,
+			Amount:      money.New(50, 0),
+			OrderNumber: "5062821234567892",
+			Created:     created,
+		},
+	}
+
+	type want struct {
+		page dto.LedgerPage
+		err  error
+	}
+
+	tests := []struct {
+		name   string
+		query  dto.ListQuery
+		rSetup func(t *testing.T) BalanceRepository
+		lSetup func(t *testing.T) Logger
+		want   want
+	}{
+		{
+			name:  "success_next_page",
+			query: dto.ListQuery{Limit: 1},
+			rSetup: func(t *testing.T) BalanceRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockBalanceRepository(ctrl)
+				repository.EXPECT().
+					History(gomock.All(), userID, entity.LedgerFilter{Limit: 2}).
+					Return(entries, nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				page: dto.LedgerPage{
+					Entries: []dto.LedgerEntry{
+						{Kind: entity.LedgerKindAdjustment, Amount: money.New(-50, 0), Reason: "mistake", Created: created},
+					},
+					NextCursor: encodeCursor(entity.LedgerCursor{Created: created, ID: 5}),
+				},
+				err: nil,
+			},
+		},
+		{
+			name:  "negative_invalid_cursor",
+			query: dto.ListQuery{Cursor: encodeCursor(entity.WithdrawalsCursor{})},
+			rSetup: func(t *testing.T) BalanceRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockBalanceRepository(ctrl)
+				repository.EXPECT().
+					History(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{err: errors.ErrListInvalidQuery},
+		},
+		{
+			name:  "negative_repository_error",
+			query: dto.ListQuery{},
+			rSetup: func(t *testing.T) BalanceRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockBalanceRepository(ctrl)
+				repository.EXPECT().
+					History(gomock.All(), userID, gomock.All()).
+					Return(nil, fmt.Errorf("any error"))
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to get user balance history", gomock.All(), gomock.Any())
+				return logger
+			},
+			want: want{err: errors.ErrUnexpected},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			balanceService := NewBalance(test.rSetup(t), test.lSetup(t))
+			page, err := balanceService.History(userIDctx, test.query)
+
+			assert.Equal(t, test.want.page, page, "Balance history page")
+			assert.ErrorIs(t, err, test.want.err, "Balance history error")
+		})
+	}
+}
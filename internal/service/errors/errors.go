@@ -1,16 +1,53 @@
 package errors
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
-	ErrUnexpected                 = errors.New("unexpected error")
-	ErrAuthUserAlreadyExists      = errors.New("user already exists")
-	ErrAuthInvalidCredentials     = errors.New("invalid credentials")
-	ErrAuthInvalidToken           = errors.New("invalid token")
-	ErrAuthTokenExpired           = errors.New("token expired")
-	ErrOrderUploadedByUser        = errors.New("order already uploaded by user")
-	ErrOrderUploadedByAnotherUser = errors.New("order already uploaded by another user")
-	ErrOrderInvalidNumber         = errors.New("invalid order number")
-	ErrWithdrawInvalidSum         = errors.New("invalid withdraw sum")
-	ErrWithdrawInsufficientFunds  = errors.New("insufficient funds")
+	ErrUnexpected                   = errors.New("unexpected error")
+	ErrUserNotFound                 = errors.New("user not found")
+	ErrAuthUserAlreadyExists        = errors.New("user already exists")
+	ErrAuthInvalidCredentials       = errors.New("invalid credentials")
+	ErrAuthInvalidToken             = errors.New("invalid token")
+	ErrAuthTokenExpired             = errors.New("token expired")
+	ErrAuthTooManyAttempts          = errors.New("too many login attempts")
+	ErrAuthWrongPassword            = errors.New("wrong password")
+	ErrOrderUploadedByUser          = errors.New("order already uploaded by user")
+	ErrOrderUploadedByAnotherUser   = errors.New("order already uploaded by another user")
+	ErrOrderInvalidNumber           = errors.New("invalid order number")
+	ErrWithdrawInvalidSum           = errors.New("invalid withdraw sum")
+	ErrWithdrawInsufficientFunds    = errors.New("insufficient funds")
+	ErrWithdrawOrderAlreadyPaid     = errors.New("order already paid")
+	ErrBalanceMismatch              = errors.New("balance does not match ledger")
+	ErrIdempotencyKeyInvalid        = errors.New("invalid idempotency key")
+	ErrIdempotencyKeyReused         = errors.New("idempotency key reused with another request")
+	ErrIdempotencyRequestInProgress = errors.New("request with idempotency key is in progress")
+	ErrListInvalidQuery             = errors.New("invalid list query")
+	ErrWebhookInvalidURL            = errors.New("invalid webhook url")
+	ErrWebhookAlreadyExists         = errors.New("webhook already exists")
+	ErrWebhookLimitReached          = errors.New("webhook limit reached")
+	ErrWebhookNotFound              = errors.New("webhook not found")
+	ErrOrderNotFound                = errors.New("order not found")
+	ErrOrderInvalidStatus           = errors.New("invalid order status")
+	ErrOrderStatusConflict          = errors.New("order status does not allow this change")
+	ErrAdjustmentInvalidAmount      = errors.New("adjustment amount must not be zero")
+	ErrAdjustmentInvalidReason      = errors.New("invalid adjustment reason")
+	ErrAdjustmentInsufficientFunds  = errors.New("insufficient funds for adjustment")
+	ErrAdjustmentOperatorRequired   = errors.New("adjustment requires an operator account")
 )
+
+// RetryError сообщает, через сколько можно повторить отклоненный запрос.
+type RetryError struct {
+	Err   error
+	After time.Duration
+}
+
+func (e *RetryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/middleware"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+)
+
+type EventHub interface {
+	Subscribe(userID uint64) (<-chan entity.UserEvent, func())
+}
+
+type Events struct {
+	hub    EventHub
+	logger Logger
+}
+
+func NewEvents(h EventHub, l Logger) *Events {
+	return &Events{hub: h, logger: l}
+}
+
+// Subscribe канал закрывается после отписки или при отключении подписчика хабом.
+func (s *Events) Subscribe(ctx context.Context) (<-chan dto.Event, func(), error) {
+	userID, ok := ctx.Value(middleware.KeyUserID).(uint64)
+	if !ok {
+		s.logger.Error("failed to get user id", srvErrors.ErrUnexpected, logger.Request(ctx))
+		return nil, nil, srvErrors.ErrUnexpected
+	}
+
+	source, unsubscribe := s.hub.Subscribe(userID)
+	events := make(chan dto.Event)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(events)
+		for event := range source {
+			e, ok := eventDTO(event)
+			if !ok {
+				continue
+			}
+			select {
+			case events <- e:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			close(done)
+			unsubscribe()
+		})
+	}
+
+	return events, stop, nil
+}
+
+func eventDTO(event entity.UserEvent) (dto.Event, bool) {
+	switch {
+	case event.Type == entity.UserEventOrder && event.Order != nil:
+		order := dto.OrderEvent{Number: event.Order.Number, Status: event.Order.Status}
+		if event.Order.Accrual > 0 {
+			order.Accrual = &event.Order.Accrual
+		}
+		return dto.Event{Type: event.Type, Data: order}, true
+	case event.Type == entity.UserEventBalance && event.Balance != nil:
+		return dto.Event{
+			Type: event.Type,
+			Data: dto.Balance{Current: event.Balance.Balance, Withdrawn: event.Balance.Debited},
+		}, true
+	default:
+		return dto.Event{}, false
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/middleware"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/mocks"
+)
+
+func TestEvents_Subscribe(t *testing.T) {
+	userID := uint64(13)
+	userIDctx := context.WithValue(context.Background(), middleware.KeyUserID, userID)
+	accrual := money.New(729, 98)
+
+	source := make(chan entity.UserEvent, 3)
+	source <- entity.UserEvent{
+		UserID: userID,
+		Type:   entity.UserEventOrder,
+		Order:  &entity.OrderEvent{Number: "5062821234567892", Status: entity.OrderStatusProcessed, Accrual: accrual},
+	}
+	source <- entity.UserEvent{UserID: userID, Type: "unknown"}
+	source <- entity.UserEvent{
+		UserID:  userID,
+		Type:    entity.UserEventBalance,
+		Balance: &entity.BalanceEvent{Balance: money.New(500, 5), Debited: money.New(42, 0)},
+	}
+	close(source)
+
+	ctrl := gomock.NewController(t)
+	hub := mocks.NewMockEventHub(ctrl)
+	hub.EXPECT().
+		Subscribe(userID).
+		Return(source, func() {})
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	events, unsubscribe, err := NewEvents(hub, logger).Subscribe(userIDctx)
+	require.Nil(t, err, "Subscribe error")
+	defer unsubscribe()
+
+	var received []dto.Event
+	for event := range events {
+		received = append(received, event)
+	}
+
+	assert.Equal(t, []dto.Event{
+		{
+			Type: entity.UserEventOrder,
+			Data: dto.OrderEvent{Number: "5062821234567892", Status: entity.OrderStatusProcessed, Accrual: &accrual},
+		},
+		{
+			Type: entity.UserEventBalance,
+			Data: dto.Balance{Current: money.New(500, 5), Withdrawn: money.New(42, 0)},
+		},
+	}, received, "Events")
+}
+
+func TestEvents_Subscribe_unsubscribe(t *testing.T) {
+	userID := uint64(13)
+	userIDctx := context.WithValue(context.Background(), middleware.KeyUserID, userID)
+
+	source := make(chan entity.UserEvent, 1)
+	source <- entity.UserEvent{UserID: userID, Type: entity.UserEventBalance, Balance: &entity.BalanceEvent{}}
+	unsubscribed := 0
+
+	ctrl := gomock.NewController(t)
+	hub := mocks.NewMockEventHub(ctrl)
+	hub.EXPECT().
+		Subscribe(userID).
+		Return(source, func() { unsubscribed++ })
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("", gomock.All()).Times(0)
+
+	events, unsubscribe, err := NewEvents(hub, logger).Subscribe(userIDctx)
+	require.Nil(t, err, "Subscribe error")
+
+	unsubscribe()
+	unsubscribe()
+	close(source)
+
+	for range events {
+	}
+	assert.Equal(t, 1, unsubscribed, "Unsubscribed once")
+}
+
+func TestEvents_Subscribe_withoutUserID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	hub := mocks.NewMockEventHub(ctrl)
+	hub.EXPECT().
+		Subscribe(gomock.All()).
+		Times(0)
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().Error("failed to get user id", gomock.All(), gomock.Any())
+
+	_, _, err := NewEvents(hub, logger).Subscribe(context.Background())
+	assert.ErrorIs(t, err, srvErrors.ErrUnexpected, "Subscribe error")
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/breaker"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+)
+
+const healthCheckTimeout = 2 * time.Second
+
+var (
+	errHealthShuttingDown    = errors.New("server is shutting down")
+	errHealthMigrationsDirty = errors.New("migration is dirty")
+	errHealthMigrationsOld   = errors.New("schema version is older than expected")
+	errHealthNoConsumers     = errors.New("no consumers running")
+)
+
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (version uint, dirty bool, err error)
+}
+
+type ProcessorMonitor interface {
+	Consumers() int
+	BreakerState() breaker.State
+}
+
+// Health проверки готовности экземпляра принимать запросы.
+// Открытый circuit breaker не снимает готовность: API работает и без системы расчета.
+type Health struct {
+	repository HealthRepository
+	processor  ProcessorMonitor
+	migration  uint
+	draining   atomic.Bool
+}
+
+func NewHealth(r HealthRepository, p ProcessorMonitor, migration uint) *Health {
+	return &Health{repository: r, processor: p, migration: migration}
+}
+
+// Drain переводит готовность в fail перед остановкой сервера.
+func (s *Health) Drain() {
+	s.draining.Store(true)
+}
+
+// Ready возвращает состояние компонентов и false, если хотя бы один из них неработоспособен.
+func (s *Health) Ready(ctx context.Context) (dto.Health, bool) {
+	ctx, span := tracer.Start(ctx, "Health.Ready")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	components := map[string]dto.ComponentHealth{
+		"database":   s.database(ctx),
+		"migrations": s.migrations(ctx),
+		"processor":  s.consumers(),
+		"accrual":    s.accrual(),
+	}
+	if s.draining.Load() {
+		components["server"] = failed(errHealthShuttingDown, nil)
+	}
+
+	status := dto.HealthStatusOK
+	for _, c := range components {
+		if c.Status == dto.HealthStatusFail {
+			status = dto.HealthStatusFail
+			break
+		}
+		if c.Status == dto.HealthStatusDegraded {
+			status = dto.HealthStatusDegraded
+		}
+	}
+
+	return dto.Health{Status: status, Components: components}, status != dto.HealthStatusFail
+}
+
+func (s *Health) database(ctx context.Context) dto.ComponentHealth {
+	if err := s.repository.Ping(ctx); err != nil {
+		return failed(err, nil)
+	}
+	return dto.ComponentHealth{Status: dto.HealthStatusOK}
+}
+
+func (s *Health) migrations(ctx context.Context) dto.ComponentHealth {
+	version, dirty, err := s.repository.MigrationVersion(ctx)
+	if err != nil {
+		return failed(err, nil)
+	}
+
+	details := dto.MigrationsHealth{Version: version, Expected: s.migration, Dirty: dirty}
+	switch {
+	case dirty:
+		return failed(errHealthMigrationsDirty, details)
+	case version < s.migration:
+		return failed(errHealthMigrationsOld, details)
+	}
+
+	return dto.ComponentHealth{Status: dto.HealthStatusOK, Details: details}
+}
+
+func (s *Health) consumers() dto.ComponentHealth {
+	details := dto.ProcessorHealth{Consumers: s.processor.Consumers()}
+	if details.Consumers == 0 {
+		return failed(errHealthNoConsumers, details)
+	}
+	return dto.ComponentHealth{Status: dto.HealthStatusOK, Details: details}
+}
+
+func (s *Health) accrual() dto.ComponentHealth {
+	state := s.processor.BreakerState()
+	if state.State == breaker.StateOpen {
+		return dto.ComponentHealth{Status: dto.HealthStatusDegraded, Details: state}
+	}
+	return dto.ComponentHealth{Status: dto.HealthStatusOK, Details: state}
+}
+
+func failed(err error, details any) dto.ComponentHealth {
+	return dto.ComponentHealth{Status: dto.HealthStatusFail, Error: err.Error(), Details: details}
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/breaker"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/mocks"
+)
+
+func TestHealth_Ready(t *testing.T) {
+	closed := breaker.State{State: breaker.StateClosed}
+	open := breaker.State{State: breaker.StateOpen, Failures: 5, Trips: 1}
+	errDB := errors.New("connection refused")
+
+	tests := []struct {
+		name      string
+		rSetup    func(r *mocks.MockHealthRepository)
+		pSetup    func(p *mocks.MockProcessorMonitor)
+		drain     bool
+		wantReady bool
+		want      dto.Health
+	}{
+		{
+			name: "ready",
+			rSetup: func(r *mocks.MockHealthRepository) {
+				r.EXPECT().Ping(gomock.Any()).Return(nil)
+				r.EXPECT().MigrationVersion(gomock.Any()).Return(uint(20251015100000), false, nil)
+			},
+			pSetup: func(p *mocks.MockProcessorMonitor) {
+				p.EXPECT().Consumers().Return(10)
+				p.EXPECT().BreakerState().Return(closed)
+			},
+			wantReady: true,
+			want: dto.Health{
+				Status: dto.HealthStatusOK,
+				Components: map[string]dto.ComponentHealth{
+					"database": {Status: dto.HealthStatusOK},
+					"migrations": {
+						Status:  dto.HealthStatusOK,
+						Details: dto.MigrationsHealth{Version: 20251015100000, Expected: 20251015100000},
+					},
+					"processor": {Status: dto.HealthStatusOK, Details: dto.ProcessorHealth{Consumers: 10}},
+					"accrual":   {Status: dto.HealthStatusOK, Details: closed},
+				},
+			},
+		},
+		{
+			name: "degraded_breaker_open",
+			rSetup: func(r *mocks.MockHealthRepository) {
+				r.EXPECT().Ping(gomock.Any()).Return(nil)
+				r.EXPECT().MigrationVersion(gomock.Any()).Return(uint(20251015100000), false, nil)
+			},
+			pSetup: func(p *mocks.MockProcessorMonitor) {
+				p.EXPECT().Consumers().Return(10)
+				p.EXPECT().BreakerState().Return(open)
+			},
+			wantReady: true,
+			want: dto.Health{
+				Status: dto.HealthStatusDegraded,
+				Components: map[string]dto.ComponentHealth{
+					"database": {Status: dto.HealthStatusOK},
+					"migrations": {
+						Status:  dto.HealthStatusOK,
+						Details: dto.MigrationsHealth{Version: 20251015100000, Expected: 20251015100000},
+					},
+					"processor": {Status: dto.HealthStatusOK, Details: dto.ProcessorHealth{Consumers: 10}},
+					"accrual":   {Status: dto.HealthStatusDegraded, Details: open},
+				},
+			},
+		},
+		{
+			name: "negative_database_unreachable",
+			rSetup: func(r *mocks.MockHealthRepository) {
+				r.EXPECT().Ping(gomock.Any()).Return(errDB)
+				r.EXPECT().MigrationVersion(gomock.Any()).Return(uint(0), false, errDB)
+			},
+			pSetup: func(p *mocks.MockProcessorMonitor) {
+				p.EXPECT().Consumers().Return(10)
+				p.EXPECT().BreakerState().Return(closed)
+			},
+			want: dto.Health{
+				Status: dto.HealthStatusFail,
+				Components: map[string]dto.ComponentHealth{
+					"database":   {Status: dto.HealthStatusFail, Error: errDB.Error()},
+					"migrations": {Status: dto.HealthStatusFail, Error: errDB.Error()},
+					"processor":  {Status: dto.HealthStatusOK, Details: dto.ProcessorHealth{Consumers: 10}},
+					"accrual":    {Status: dto.HealthStatusOK, Details: closed},
+				},
+			},
+		},
+		{
+			name: "negative_migrations_dirty",
+			rSetup: func(r *mocks.MockHealthRepository) {
+				r.EXPECT().Ping(gomock.Any()).Return(nil)
+				r.EXPECT().MigrationVersion(gomock.Any()).Return(uint(20251015100000), true, nil)
+			},
+			pSetup: func(p *mocks.MockProcessorMonitor) {
+				p.EXPECT().Consumers().Return(10)
+				p.EXPECT().BreakerState().Return(closed)
+			},
+			want: dto.Health{
+				Status: dto.HealthStatusFail,
+				Components: map[string]dto.ComponentHealth{
+					"database": {Status: dto.HealthStatusOK},
+					"migrations": {
+						Status:  dto.HealthStatusFail,
+						Error:   errHealthMigrationsDirty.Error(),
+						Details: dto.MigrationsHealth{Version: 20251015100000, Expected: 20251015100000, Dirty: true},
+					},
+					"processor": {Status: dto.HealthStatusOK, Details: dto.ProcessorHealth{Consumers: 10}},
+					"accrual":   {Status: dto.HealthStatusOK, Details: closed},
+				},
+			},
+		},
+		{
+			name: "negative_migrations_behind",
+			rSetup: func(r *mocks.MockHealthRepository) {
+				r.EXPECT().Ping(gomock.Any()).Return(nil)
+				r.EXPECT().MigrationVersion(gomock.Any()).Return(uint(20251014100000), false, nil)
+			},
+			pSetup: func(p *mocks.MockProcessorMonitor) {
+				p.EXPECT().Consumers().Return(10)
+				p.EXPECT().BreakerState().Return(closed)
+			},
+			want: dto.Health{
+				Status: dto.HealthStatusFail,
+				Components: map[string]dto.ComponentHealth{
+					"database": {Status: dto.HealthStatusOK},
+					"migrations": {
+						Status:  dto.HealthStatusFail,
+						Error:   errHealthMigrationsOld.Error(),
+						Details: dto.MigrationsHealth{Version: 20251014100000, Expected: 20251015100000},
+					},
+					"processor": {Status: dto.HealthStatusOK, Details: dto.ProcessorHealth{Consumers: 10}},
+					"accrual":   {Status: dto.HealthStatusOK, Details: closed},
+				},
+			},
+		},
+		{
+			name: "negative_no_consumers",
+			rSetup: func(r *mocks.MockHealthRepository) {
+				r.EXPECT().Ping(gomock.Any()).Return(nil)
+				r.EXPECT().MigrationVersion(gomock.Any()).Return(uint(20251015100000), false, nil)
+			},
+			pSetup: func(p *mocks.MockProcessorMonitor) {
+				p.EXPECT().Consumers().Return(0)
+				p.EXPECT().BreakerState().Return(closed)
+			},
+			want: dto.Health{
+				Status: dto.HealthStatusFail,
+				Components: map[string]dto.ComponentHealth{
+					"database": {Status: dto.HealthStatusOK},
+					"migrations": {
+						Status:  dto.HealthStatusOK,
+						Details: dto.MigrationsHealth{Version: 20251015100000, Expected: 20251015100000},
+					},
+					"processor": {
+						Status:  dto.HealthStatusFail,
+						Error:   errHealthNoConsumers.Error(),
+						Details: dto.ProcessorHealth{Consumers: 0},
+					},
+					"accrual": {Status: dto.HealthStatusOK, Details: closed},
+				},
+			},
+		},
+		{
+			name: "negative_draining",
+			rSetup: func(r *mocks.MockHealthRepository) {
+				r.EXPECT().Ping(gomock.Any()).Return(nil)
+				r.EXPECT().MigrationVersion(gomock.Any()).Return(uint(20251015100000), false, nil)
+			},
+			pSetup: func(p *mocks.MockProcessorMonitor) {
+				p.EXPECT().Consumers().Return(10)
+				p.EXPECT().BreakerState().Return(closed)
+			},
+			drain: true,
+			want: dto.Health{
+				Status: dto.HealthStatusFail,
+				Components: map[string]dto.ComponentHealth{
+					"database": {Status: dto.HealthStatusOK},
+					"migrations": {
+						Status:  dto.HealthStatusOK,
+						Details: dto.MigrationsHealth{Version: 20251015100000, Expected: 20251015100000},
+					},
+					"processor": {Status: dto.HealthStatusOK, Details: dto.ProcessorHealth{Consumers: 10}},
+					"accrual":   {Status: dto.HealthStatusOK, Details: closed},
+					"server":    {Status: dto.HealthStatusFail, Error: errHealthShuttingDown.Error()},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repository := mocks.NewMockHealthRepository(ctrl)
+			test.rSetup(repository)
+			processor := mocks.NewMockProcessorMonitor(ctrl)
+			test.pSetup(processor)
+
+			service := NewHealth(repository, processor, 20251015100000)
+			if test.drain {
+				service.Drain()
+			}
+
+			got, ready := service.Ready(context.Background())
+			assert.Equal(t, test.wantReady, ready, "Ready")
+			assert.Equal(t, test.want, got, "Health report")
+		})
+	}
+}
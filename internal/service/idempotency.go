@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/middleware"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+)
+
+// idempotencyLease удерживает ключ незавершенного запроса. Если экземпляр упал,
+// не сохранив ответ, повтор запроса получает 409 не дольше этого времени.
+const idempotencyLease = time.Minute
+
+type IdempotencyRepository interface {
+	Reserve(
+		ctx context.Context,
+		key entity.IdempotencyKey,
+		lease time.Duration,
+	) (stored entity.IdempotencyKey, created bool, err error)
+	Complete(ctx context.Context, key entity.IdempotencyKey, ttl time.Duration) error
+	Delete(ctx context.Context, k entity.IdempotencyKey) error
+	DeleteExpired(ctx context.Context) error
+}
+
+type Idempotency struct {
+	repository IdempotencyRepository
+	logger     Logger
+	ttl        time.Duration
+}
+
+func NewIdempotency(r IdempotencyRepository, l Logger, ttl time.Duration) *Idempotency {
+	return &Idempotency{repository: r, logger: l, ttl: ttl}
+}
+
+// Begin резервирует ключ для запроса с хэшем requestHash.
+// Если запрос с таким ключом уже был выполнен, возвращает сохраненный ответ и replay = true.
+func (s *Idempotency) Begin(
+	ctx context.Context,
+	key string,
+	requestHash string,
+) (stored entity.IdempotencyKey, replay bool, err error) {
+	ctx, span := tracer.Start(ctx, "Idempotency.Begin")
+	defer span.End()
+
+	userID, ok := ctx.Value(middleware.KeyUserID).(uint64)
+	if !ok {
+		s.logger.Error("failed to get user id", srvErrors.ErrUnexpected, logger.Request(ctx))
+		return stored, false, srvErrors.ErrUnexpected
+	}
+
+	if len(key) > entity.IdempotencyKeyMaxLen {
+		err = fmt.Errorf(
+			"%w: key too long, max %d characters",
+			srvErrors.ErrIdempotencyKeyInvalid,
+			entity.IdempotencyKeyMaxLen,
+		)
+		return stored, false, err
+	}
+
+	k := entity.IdempotencyKey{UserID: userID, Key: key, RequestHash: requestHash}
+	stored, created, err := s.repository.Reserve(ctx, k, idempotencyLease)
+	if err != nil {
+		s.logger.Error("failed to reserve idempotency key", err, logger.UserID(userID), logger.Request(ctx))
+		return stored, false, srvErrors.ErrUnexpected
+	}
+
+	switch {
+	case created:
+		return stored, false, nil
+	case stored.RequestHash != requestHash:
+		return stored, false, srvErrors.ErrIdempotencyKeyReused
+	case !stored.Completed():
+		return stored, false, srvErrors.ErrIdempotencyRequestInProgress
+	}
+
+	return stored, true, nil
+}
+
+// Complete сохраняет ответ на запрос с хэшем requestHash для повторной выдачи.
+func (s *Idempotency) Complete(
+	ctx context.Context,
+	key string,
+	requestHash string,
+	status int,
+	contentType string,
+	body []byte,
+) {
+	ctx, span := tracer.Start(ctx, "Idempotency.Complete")
+	defer span.End()
+
+	userID, ok := ctx.Value(middleware.KeyUserID).(uint64)
+	if !ok {
+		s.logger.Error("failed to get user id", srvErrors.ErrUnexpected, logger.Request(ctx))
+		return
+	}
+
+	k := entity.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: requestHash,
+		StatusCode:  status,
+		ContentType: contentType,
+		Body:        body,
+	}
+
+	if err := s.repository.Complete(ctx, k, s.ttl); err != nil {
+		s.logger.Error("failed to save idempotent response", err, logger.UserID(userID), logger.Request(ctx))
+	}
+}
+
+// Release освобождает ключ, чтобы запрос можно было повторить.
+func (s *Idempotency) Release(ctx context.Context, key string, requestHash string) {
+	ctx, span := tracer.Start(ctx, "Idempotency.Release")
+	defer span.End()
+
+	userID, ok := ctx.Value(middleware.KeyUserID).(uint64)
+	if !ok {
+		s.logger.Error("failed to get user id", srvErrors.ErrUnexpected, logger.Request(ctx))
+		return
+	}
+
+	k := entity.IdempotencyKey{UserID: userID, Key: key, RequestHash: requestHash}
+	if err := s.repository.Delete(ctx, k); err != nil {
+		s.logger.Error("failed to release idempotency key", err, logger.UserID(userID), logger.Request(ctx))
+	}
+}
+
+// Cleanup удаляет устаревшие ключи, вызывается периодически в фоне.
+func (s *Idempotency) Cleanup(ctx context.Context) {
+	if err := s.repository.DeleteExpired(ctx); err != nil {
+		s.logger.Error("failed to delete expired idempotency keys", err)
+	}
+}
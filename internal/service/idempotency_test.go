@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/middleware"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/mocks"
+)
+
+func TestIdempotency_Begin(t *testing.T) {
+	userID := uint64(13)
+	userIDctx := context.WithValue(context.Background(), middleware.KeyUserID, userID)
+	ttl := 24 * time.Hour
+	key := "4b1d2c6e-5a1f-4f0e-9d6b-0f6b1c2a3d4e"
+	hash := "7f83b1657ff1fc53b92dc18148a1d65dfc2d4b1fa3d677284addd200126d9069"
+	reserved := entity.IdempotencyKey{UserID: userID, Key: key, RequestHash: hash}
+	completed := entity.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: hash,
+		StatusCode:  202,
+	}
+
+	type want struct {
+		stored entity.IdempotencyKey
+		replay bool
+		err    error
+	}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		key    string
+		rSetup func(t *testing.T) IdempotencyRepository
+		lSetup func(t *testing.T) Logger
+		want   want
+	}{
+		{
+			name: "success_new_key",
+			ctx:  userIDctx,
+			key:  key,
+			rSetup: func(t *testing.T) IdempotencyRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockIdempotencyRepository(ctrl)
+				repository.EXPECT().
+					Reserve(gomock.All(), reserved, idempotencyLease).
+					Return(reserved, true, nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{stored: reserved, replay: false, err: nil},
+		},
+		{
+			name: "success_replay",
+			ctx:  userIDctx,
+			key:  key,
+			rSetup: func(t *testing.T) IdempotencyRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockIdempotencyRepository(ctrl)
+				repository.EXPECT().
+					Reserve(gomock.All(), reserved, idempotencyLease).
+					Return(completed, false, nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{stored: completed, replay: true, err: nil},
+		},
+		{
+			name: "negative_in_progress",
+			ctx:  userIDctx,
+			key:  key,
+			rSetup: func(t *testing.T) IdempotencyRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockIdempotencyRepository(ctrl)
+				repository.EXPECT().
+					Reserve(gomock.All(), reserved, idempotencyLease).
+					Return(reserved, false, nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{stored: reserved, replay: false, err: srvErrors.ErrIdempotencyRequestInProgress},
+		},
+		{
+			name: "negative_reused_with_another_request",
+			ctx:  userIDctx,
+			key:  key,
+			rSetup: func(t *testing.T) IdempotencyRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockIdempotencyRepository(ctrl)
+				another := completed
+				another.RequestHash = strings.Repeat("0", 64)
+				repository.EXPECT().
+					Reserve(gomock.All(), reserved, idempotencyLease).
+					Return(another, false, nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				stored: entity.IdempotencyKey{
+					UserID:      userID,
+					Key:         key,
+					RequestHash: strings.Repeat("0", 64),
+					StatusCode:  202,
+				},
+				replay: false,
+				err:    srvErrors.ErrIdempotencyKeyReused,
+			},
+		},
+		{
+			name: "negative_key_too_long",
+			ctx:  userIDctx,
+			key:  strings.Repeat("k", entity.IdempotencyKeyMaxLen+1),
+			rSetup: func(t *testing.T) IdempotencyRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockIdempotencyRepository(ctrl)
+				repository.EXPECT().
+					Reserve(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{err: srvErrors.ErrIdempotencyKeyInvalid},
+		},
+		{
+			name: "negative_without_userID",
+			ctx:  context.Background(),
+			key:  key,
+			rSetup: func(t *testing.T) IdempotencyRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockIdempotencyRepository(ctrl)
+				repository.EXPECT().
+					Reserve(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to get user id", gomock.All(), gomock.Any())
+				return logger
+			},
+			want: want{err: srvErrors.ErrUnexpected},
+		},
+		{
+			name: "negative_repository_error",
+			ctx:  userIDctx,
+			key:  key,
+			rSetup: func(t *testing.T) IdempotencyRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockIdempotencyRepository(ctrl)
+				repository.EXPECT().
+					Reserve(gomock.All(), reserved, idempotencyLease).
+					Return(entity.IdempotencyKey{}, false, fmt.Errorf("any error"))
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to reserve idempotency key", gomock.All(), gomock.Any())
+				return logger
+			},
+			want: want{err: srvErrors.ErrUnexpected},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := test.rSetup(t)
+			logger := test.lSetup(t)
+			service := NewIdempotency(repository, logger, ttl)
+
+			stored, replay, err := service.Begin(test.ctx, test.key, hash)
+			assert.Equal(t, test.want.stored, stored, "Stored idempotency key")
+			assert.Equal(t, test.want.replay, replay, "Replay stored response")
+			assert.ErrorIs(t, err, test.want.err, "Begin idempotent request error")
+		})
+	}
+}
+
+func TestIdempotency_Complete(t *testing.T) {
+	userID := uint64(13)
+	userIDctx := context.WithValue(context.Background(), middleware.KeyUserID, userID)
+	key := "4b1d2c6e-5a1f-4f0e-9d6b-0f6b1c2a3d4e"
+	completed := entity.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: "hash",
+		StatusCode:  200,
+		ContentType: "text/plain",
+		Body:        []byte("ok"),
+	}
+
+	tests := []struct {
+		name   string
+		rSetup func(t *testing.T) IdempotencyRepository
+		lSetup func(t *testing.T) Logger
+	}{
+		{
+			name: "success",
+			rSetup: func(t *testing.T) IdempotencyRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockIdempotencyRepository(ctrl)
+				repository.EXPECT().
+					Complete(gomock.All(), completed, time.Hour).
+					Return(nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+		},
+		{
+			name: "negative_repository_error",
+			rSetup: func(t *testing.T) IdempotencyRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockIdempotencyRepository(ctrl)
+				repository.EXPECT().
+					Complete(gomock.All(), completed, time.Hour).
+					Return(fmt.Errorf("any error"))
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to save idempotent response", gomock.All(), gomock.Any())
+				return logger
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := NewIdempotency(test.rSetup(t), test.lSetup(t), time.Hour)
+			service.Complete(userIDctx, key, "hash", 200, "text/plain", []byte("ok"))
+		})
+	}
+}
+
+func TestIdempotency_Cleanup(t *testing.T) {
+	tests := []struct {
+		name   string
+		rSetup func(t *testing.T) IdempotencyRepository
+		lSetup func(t *testing.T) Logger
+	}{
+		{
+			name: "success",
+			rSetup: func(t *testing.T) IdempotencyRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockIdempotencyRepository(ctrl)
+				repository.EXPECT().
+					DeleteExpired(gomock.All()).
+					Return(nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+		},
+		{
+			name: "negative_repository_error",
+			rSetup: func(t *testing.T) IdempotencyRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockIdempotencyRepository(ctrl)
+				repository.EXPECT().
+					DeleteExpired(gomock.All()).
+					Return(fmt.Errorf("any error"))
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to delete expired idempotency keys", gomock.All())
+				return logger
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := NewIdempotency(test.rSetup(t), test.lSetup(t), time.Hour)
+			service.Cleanup(context.Background())
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+// listParams проверяет общие параметры списка, курсор разбирается в cursor.
+func listParams(q dto.ListQuery, cursor any) (limit int, ascending bool, err error) {
+	limit = q.Limit
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+	if limit < 0 || limit > maxListLimit {
+		return 0, false, fmt.Errorf("%w: limit must be between 1 and %d", srvErrors.ErrListInvalidQuery, maxListLimit)
+	}
+
+	switch q.Sort {
+	case "", dto.SortDesc:
+	case dto.SortAsc:
+		ascending = true
+	default:
+		return 0, false, fmt.Errorf("%w: sort must be asc or desc", srvErrors.ErrListInvalidQuery)
+	}
+
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		return 0, false, fmt.Errorf("%w: from must be before to", srvErrors.ErrListInvalidQuery)
+	}
+
+	if q.Cursor != "" {
+		if err := decodeCursor(q.Cursor, cursor); err != nil {
+			return 0, false, fmt.Errorf("%w: invalid cursor", srvErrors.ErrListInvalidQuery)
+		}
+	}
+
+	return limit, ascending, nil
+}
+
+// Курсор непрозрачен для клиента: это позиция последней записи страницы в base64.
+func encodeCursor(v any) string {
+	// Курсоры состоят из времени и ключа, ошибки кодирования здесь невозможны.
+	b, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
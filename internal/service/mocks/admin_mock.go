@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: admin.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAdminOrderRepository is a mock of AdminOrderRepository interface.
+type MockAdminOrderRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminOrderRepositoryMockRecorder
+}
+
+// MockAdminOrderRepositoryMockRecorder is the mock recorder for MockAdminOrderRepository.
+type MockAdminOrderRepositoryMockRecorder struct {
+	mock *MockAdminOrderRepository
+}
+
+// NewMockAdminOrderRepository creates a new mock instance.
+func NewMockAdminOrderRepository(ctrl *gomock.Controller) *MockAdminOrderRepository {
+	mock := &MockAdminOrderRepository{ctrl: ctrl}
+	mock.recorder = &MockAdminOrderRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminOrderRepository) EXPECT() *MockAdminOrderRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetDetails mocks base method.
+func (m *MockAdminOrderRepository) GetDetails(ctx context.Context, number string) (entity.OrderDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDetails", ctx, number)
+	ret0, _ := ret[0].(entity.OrderDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDetails indicates an expected call of GetDetails.
+func (mr *MockAdminOrderRepositoryMockRecorder) GetDetails(ctx, number interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDetails", reflect.TypeOf((*MockAdminOrderRepository)(nil).GetDetails), ctx, number)
+}
+
+// Requeue mocks base method.
+func (m *MockAdminOrderRepository) Requeue(ctx context.Context, number string, from []string) (entity.OrderDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Requeue", ctx, number, from)
+	ret0, _ := ret[0].(entity.OrderDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Requeue indicates an expected call of Requeue.
+func (mr *MockAdminOrderRepositoryMockRecorder) Requeue(ctx, number, from interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Requeue", reflect.TypeOf((*MockAdminOrderRepository)(nil).Requeue), ctx, number, from)
+}
+
+// Search mocks base method.
+func (m *MockAdminOrderRepository) Search(ctx context.Context, f entity.AdminOrderFilter) ([]entity.OrderDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, f)
+	ret0, _ := ret[0].([]entity.OrderDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockAdminOrderRepositoryMockRecorder) Search(ctx, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockAdminOrderRepository)(nil).Search), ctx, f)
+}
+
+// SetStatus mocks base method.
+func (m *MockAdminOrderRepository) SetStatus(ctx context.Context, number, status string, from []string) (entity.OrderDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStatus", ctx, number, status, from)
+	ret0, _ := ret[0].(entity.OrderDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetStatus indicates an expected call of SetStatus.
+func (mr *MockAdminOrderRepositoryMockRecorder) SetStatus(ctx, number, status, from interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStatus", reflect.TypeOf((*MockAdminOrderRepository)(nil).SetStatus), ctx, number, status, from)
+}
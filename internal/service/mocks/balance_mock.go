@@ -35,6 +35,22 @@ func (m *MockBalanceRepository) EXPECT() *MockBalanceRepositoryMockRecorder {
 	return m.recorder
 }
 
+// Adjust mocks base method.
+func (m *MockBalanceRepository) Adjust(ctx context.Context, a entity.BalanceAdjustment) (entity.BalanceAdjustment, entity.Balance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Adjust", ctx, a)
+	ret0, _ := ret[0].(entity.BalanceAdjustment)
+	ret1, _ := ret[1].(entity.Balance)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Adjust indicates an expected call of Adjust.
+func (mr *MockBalanceRepositoryMockRecorder) Adjust(ctx, a interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Adjust", reflect.TypeOf((*MockBalanceRepository)(nil).Adjust), ctx, a)
+}
+
 // GetByUser mocks base method.
 func (m *MockBalanceRepository) GetByUser(ctx context.Context, userID uint64) (entity.Balance, error) {
 	m.ctrl.T.Helper()
@@ -49,3 +65,33 @@ func (mr *MockBalanceRepositoryMockRecorder) GetByUser(ctx, userID interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUser", reflect.TypeOf((*MockBalanceRepository)(nil).GetByUser), ctx, userID)
 }
+
+// History mocks base method.
+func (m *MockBalanceRepository) History(ctx context.Context, userID uint64, f entity.LedgerFilter) ([]entity.LedgerEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, userID, f)
+	ret0, _ := ret[0].([]entity.LedgerEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockBalanceRepositoryMockRecorder) History(ctx, userID, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockBalanceRepository)(nil).History), ctx, userID, f)
+}
+
+// Rebuild mocks base method.
+func (m *MockBalanceRepository) Rebuild(ctx context.Context, userID uint64) (entity.BalanceReconciliation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rebuild", ctx, userID)
+	ret0, _ := ret[0].(entity.BalanceReconciliation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rebuild indicates an expected call of Rebuild.
+func (mr *MockBalanceRepositoryMockRecorder) Rebuild(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rebuild", reflect.TypeOf((*MockBalanceRepository)(nil).Rebuild), ctx, userID)
+}
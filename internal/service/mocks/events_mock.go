@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: events.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	entity "github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockEventHub is a mock of EventHub interface.
+type MockEventHub struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventHubMockRecorder
+}
+
+// MockEventHubMockRecorder is the mock recorder for MockEventHub.
+type MockEventHubMockRecorder struct {
+	mock *MockEventHub
+}
+
+// NewMockEventHub creates a new mock instance.
+func NewMockEventHub(ctrl *gomock.Controller) *MockEventHub {
+	mock := &MockEventHub{ctrl: ctrl}
+	mock.recorder = &MockEventHubMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventHub) EXPECT() *MockEventHubMockRecorder {
+	return m.recorder
+}
+
+// Subscribe mocks base method.
+func (m *MockEventHub) Subscribe(userID uint64) (<-chan entity.UserEvent, func()) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", userID)
+	ret0, _ := ret[0].(<-chan entity.UserEvent)
+	ret1, _ := ret[1].(func())
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockEventHubMockRecorder) Subscribe(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockEventHub)(nil).Subscribe), userID)
+}
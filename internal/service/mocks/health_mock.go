@@ -0,0 +1,117 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: health.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	breaker "github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/breaker"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockHealthRepository is a mock of HealthRepository interface.
+type MockHealthRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthRepositoryMockRecorder
+}
+
+// MockHealthRepositoryMockRecorder is the mock recorder for MockHealthRepository.
+type MockHealthRepositoryMockRecorder struct {
+	mock *MockHealthRepository
+}
+
+// NewMockHealthRepository creates a new mock instance.
+func NewMockHealthRepository(ctrl *gomock.Controller) *MockHealthRepository {
+	mock := &MockHealthRepository{ctrl: ctrl}
+	mock.recorder = &MockHealthRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHealthRepository) EXPECT() *MockHealthRepositoryMockRecorder {
+	return m.recorder
+}
+
+// MigrationVersion mocks base method.
+func (m *MockHealthRepository) MigrationVersion(ctx context.Context) (uint, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrationVersion", ctx)
+	ret0, _ := ret[0].(uint)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// MigrationVersion indicates an expected call of MigrationVersion.
+func (mr *MockHealthRepositoryMockRecorder) MigrationVersion(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrationVersion", reflect.TypeOf((*MockHealthRepository)(nil).MigrationVersion), ctx)
+}
+
+// Ping mocks base method.
+func (m *MockHealthRepository) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockHealthRepositoryMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockHealthRepository)(nil).Ping), ctx)
+}
+
+// MockProcessorMonitor is a mock of ProcessorMonitor interface.
+type MockProcessorMonitor struct {
+	ctrl     *gomock.Controller
+	recorder *MockProcessorMonitorMockRecorder
+}
+
+// MockProcessorMonitorMockRecorder is the mock recorder for MockProcessorMonitor.
+type MockProcessorMonitorMockRecorder struct {
+	mock *MockProcessorMonitor
+}
+
+// NewMockProcessorMonitor creates a new mock instance.
+func NewMockProcessorMonitor(ctrl *gomock.Controller) *MockProcessorMonitor {
+	mock := &MockProcessorMonitor{ctrl: ctrl}
+	mock.recorder = &MockProcessorMonitorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProcessorMonitor) EXPECT() *MockProcessorMonitorMockRecorder {
+	return m.recorder
+}
+
+// BreakerState mocks base method.
+func (m *MockProcessorMonitor) BreakerState() breaker.State {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BreakerState")
+	ret0, _ := ret[0].(breaker.State)
+	return ret0
+}
+
+// BreakerState indicates an expected call of BreakerState.
+func (mr *MockProcessorMonitorMockRecorder) BreakerState() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BreakerState", reflect.TypeOf((*MockProcessorMonitor)(nil).BreakerState))
+}
+
+// Consumers mocks base method.
+func (m *MockProcessorMonitor) Consumers() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consumers")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Consumers indicates an expected call of Consumers.
+func (mr *MockProcessorMonitorMockRecorder) Consumers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consumers", reflect.TypeOf((*MockProcessorMonitor)(nil).Consumers))
+}
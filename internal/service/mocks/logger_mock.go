@@ -7,6 +7,7 @@ package mocks
 import (
 	reflect "reflect"
 
+	logger "github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -34,13 +35,18 @@ func (m *MockLogger) EXPECT() *MockLoggerMockRecorder {
 }
 
 // Error mocks base method.
-func (m *MockLogger) Error(message string, err error) {
+func (m *MockLogger) Error(message string, err error, fields ...logger.Field) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Error", message, err)
+	varargs := []interface{}{message, err}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Error", varargs...)
 }
 
 // Error indicates an expected call of Error.
-func (mr *MockLoggerMockRecorder) Error(message, err interface{}) *gomock.Call {
+func (mr *MockLoggerMockRecorder) Error(message, err interface{}, fields ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Error", reflect.TypeOf((*MockLogger)(nil).Error), message, err)
+	varargs := append([]interface{}{message, err}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Error", reflect.TypeOf((*MockLogger)(nil).Error), varargs...)
 }
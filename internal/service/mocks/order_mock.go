@@ -49,32 +49,32 @@ func (mr *MockOrderRepositoryMockRecorder) Create(ctx, order interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOrderRepository)(nil).Create), ctx, order)
 }
 
-// GetAllByUser mocks base method.
-func (m *MockOrderRepository) GetAllByUser(ctx context.Context, userID uint64) ([]entity.Order, error) {
+// GetByNumber mocks base method.
+func (m *MockOrderRepository) GetByNumber(ctx context.Context, number string) (entity.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllByUser", ctx, userID)
-	ret0, _ := ret[0].([]entity.Order)
+	ret := m.ctrl.Call(m, "GetByNumber", ctx, number)
+	ret0, _ := ret[0].(entity.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllByUser indicates an expected call of GetAllByUser.
-func (mr *MockOrderRepositoryMockRecorder) GetAllByUser(ctx, userID interface{}) *gomock.Call {
+// GetByNumber indicates an expected call of GetByNumber.
+func (mr *MockOrderRepositoryMockRecorder) GetByNumber(ctx, number interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUser", reflect.TypeOf((*MockOrderRepository)(nil).GetAllByUser), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByNumber", reflect.TypeOf((*MockOrderRepository)(nil).GetByNumber), ctx, number)
 }
 
-// GetByNumber mocks base method.
-func (m *MockOrderRepository) GetByNumber(ctx context.Context, number string) (entity.Order, error) {
+// ListByUser mocks base method.
+func (m *MockOrderRepository) ListByUser(ctx context.Context, userID uint64, f entity.OrderFilter) ([]entity.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetByNumber", ctx, number)
-	ret0, _ := ret[0].(entity.Order)
+	ret := m.ctrl.Call(m, "ListByUser", ctx, userID, f)
+	ret0, _ := ret[0].([]entity.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetByNumber indicates an expected call of GetByNumber.
-func (mr *MockOrderRepositoryMockRecorder) GetByNumber(ctx, number interface{}) *gomock.Call {
+// ListByUser indicates an expected call of ListByUser.
+func (mr *MockOrderRepositoryMockRecorder) ListByUser(ctx, userID, f interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByNumber", reflect.TypeOf((*MockOrderRepository)(nil).GetByNumber), ctx, number)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUser", reflect.TypeOf((*MockOrderRepository)(nil).ListByUser), ctx, userID, f)
 }
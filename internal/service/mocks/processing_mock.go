@@ -35,33 +35,33 @@ func (m *MockProcessingRepository) EXPECT() *MockProcessingRepositoryMockRecorde
 	return m.recorder
 }
 
-// MarkOrderForRetryOrInvalid mocks base method.
-func (m *MockProcessingRepository) MarkOrderForRetryOrInvalid(ctx context.Context, number, toStatus, ivalidStatus string) error {
+// ClaimOrdersForProcess mocks base method.
+func (m *MockProcessingRepository) ClaimOrdersForProcess(ctx context.Context, statuses []string, lease entity.OrderLease) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "MarkOrderForRetryOrInvalid", ctx, number, toStatus, ivalidStatus)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ClaimOrdersForProcess", ctx, statuses, lease)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// MarkOrderForRetryOrInvalid indicates an expected call of MarkOrderForRetryOrInvalid.
-func (mr *MockProcessingRepositoryMockRecorder) MarkOrderForRetryOrInvalid(ctx, number, toStatus, ivalidStatus interface{}) *gomock.Call {
+// ClaimOrdersForProcess indicates an expected call of ClaimOrdersForProcess.
+func (mr *MockProcessingRepositoryMockRecorder) ClaimOrdersForProcess(ctx, statuses, lease interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOrderForRetryOrInvalid", reflect.TypeOf((*MockProcessingRepository)(nil).MarkOrderForRetryOrInvalid), ctx, number, toStatus, ivalidStatus)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimOrdersForProcess", reflect.TypeOf((*MockProcessingRepository)(nil).ClaimOrdersForProcess), ctx, statuses, lease)
 }
 
-// OrderNubmersForProcess mocks base method.
-func (m *MockProcessingRepository) OrderNubmersForProcess(ctx context.Context, statuses []string) ([]string, error) {
+// MarkOrderForRetry mocks base method.
+func (m *MockProcessingRepository) MarkOrderForRetry(ctx context.Context, number string, f entity.OrderFailure) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "OrderNubmersForProcess", ctx, statuses)
-	ret0, _ := ret[0].([]string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "MarkOrderForRetry", ctx, number, f)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// OrderNubmersForProcess indicates an expected call of OrderNubmersForProcess.
-func (mr *MockProcessingRepositoryMockRecorder) OrderNubmersForProcess(ctx, statuses interface{}) *gomock.Call {
+// MarkOrderForRetry indicates an expected call of MarkOrderForRetry.
+func (mr *MockProcessingRepositoryMockRecorder) MarkOrderForRetry(ctx, number, f interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrderNubmersForProcess", reflect.TypeOf((*MockProcessingRepository)(nil).OrderNubmersForProcess), ctx, statuses)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOrderForRetry", reflect.TypeOf((*MockProcessingRepository)(nil).MarkOrderForRetry), ctx, number, f)
 }
 
 // ProcessOrder mocks base method.
@@ -77,3 +77,17 @@ func (mr *MockProcessingRepositoryMockRecorder) ProcessOrder(ctx, order interfac
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessOrder", reflect.TypeOf((*MockProcessingRepository)(nil).ProcessOrder), ctx, order)
 }
+
+// ReleaseOrders mocks base method.
+func (m *MockProcessingRepository) ReleaseOrders(ctx context.Context, owner string, numbers []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseOrders", ctx, owner, numbers)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseOrders indicates an expected call of ReleaseOrders.
+func (mr *MockProcessingRepositoryMockRecorder) ReleaseOrders(ctx, owner, numbers interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseOrders", reflect.TypeOf((*MockProcessingRepository)(nil).ReleaseOrders), ctx, owner, numbers)
+}
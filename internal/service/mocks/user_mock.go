@@ -7,8 +7,10 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	entity "github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	money "github.com/EshkinKot1980/gophermart-loyalty/internal/money"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -35,6 +37,20 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ChangePassword mocks base method.
+func (m *MockUserRepository) ChangePassword(ctx context.Context, id uint64, hash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, id, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockUserRepositoryMockRecorder) ChangePassword(ctx, id, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockUserRepository)(nil).ChangePassword), ctx, id, hash)
+}
+
 // Create mocks base method.
 func (m *MockUserRepository) Create(ctx context.Context, user entity.User) (entity.User, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +66,21 @@ func (mr *MockUserRepositoryMockRecorder) Create(ctx, user interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserRepository)(nil).Create), ctx, user)
 }
 
+// Delete mocks base method.
+func (m *MockUserRepository) Delete(ctx context.Context, id uint64) (money.Amount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(money.Amount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUserRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserRepository)(nil).Delete), ctx, id)
+}
+
 // FindByLogin mocks base method.
 func (m *MockUserRepository) FindByLogin(ctx context.Context, login string) (entity.User, error) {
 	m.ctrl.T.Helper()
@@ -79,3 +110,268 @@ func (mr *MockUserRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockUserRepository)(nil).GetByID), ctx, id)
 }
+
+// UpdateHash mocks base method.
+func (m *MockUserRepository) UpdateHash(ctx context.Context, id uint64, hash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHash", ctx, id, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHash indicates an expected call of UpdateHash.
+func (mr *MockUserRepositoryMockRecorder) UpdateHash(ctx, id, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHash", reflect.TypeOf((*MockUserRepository)(nil).UpdateHash), ctx, id, hash)
+}
+
+// MockThrottler is a mock of Throttler interface.
+type MockThrottler struct {
+	ctrl     *gomock.Controller
+	recorder *MockThrottlerMockRecorder
+}
+
+// MockThrottlerMockRecorder is the mock recorder for MockThrottler.
+type MockThrottlerMockRecorder struct {
+	mock *MockThrottler
+}
+
+// NewMockThrottler creates a new mock instance.
+func NewMockThrottler(ctrl *gomock.Controller) *MockThrottler {
+	mock := &MockThrottler{ctrl: ctrl}
+	mock.recorder = &MockThrottlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockThrottler) EXPECT() *MockThrottlerMockRecorder {
+	return m.recorder
+}
+
+// Allow mocks base method.
+func (m *MockThrottler) Allow(ctx context.Context, login, ip string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Allow", ctx, login, ip)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Allow indicates an expected call of Allow.
+func (mr *MockThrottlerMockRecorder) Allow(ctx, login, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Allow", reflect.TypeOf((*MockThrottler)(nil).Allow), ctx, login, ip)
+}
+
+// Fail mocks base method.
+func (m *MockThrottler) Fail(ctx context.Context, login, ip string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Fail", ctx, login, ip)
+}
+
+// Fail indicates an expected call of Fail.
+func (mr *MockThrottlerMockRecorder) Fail(ctx, login, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fail", reflect.TypeOf((*MockThrottler)(nil).Fail), ctx, login, ip)
+}
+
+// Succeed mocks base method.
+func (m *MockThrottler) Succeed(ctx context.Context, login string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Succeed", ctx, login)
+}
+
+// Succeed indicates an expected call of Succeed.
+func (mr *MockThrottlerMockRecorder) Succeed(ctx, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Succeed", reflect.TypeOf((*MockThrottler)(nil).Succeed), ctx, login)
+}
+
+// MockPasswordHasher is a mock of PasswordHasher interface.
+type MockPasswordHasher struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordHasherMockRecorder
+}
+
+// MockPasswordHasherMockRecorder is the mock recorder for MockPasswordHasher.
+type MockPasswordHasherMockRecorder struct {
+	mock *MockPasswordHasher
+}
+
+// NewMockPasswordHasher creates a new mock instance.
+func NewMockPasswordHasher(ctrl *gomock.Controller) *MockPasswordHasher {
+	mock := &MockPasswordHasher{ctrl: ctrl}
+	mock.recorder = &MockPasswordHasherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordHasher) EXPECT() *MockPasswordHasherMockRecorder {
+	return m.recorder
+}
+
+// Hash mocks base method.
+func (m *MockPasswordHasher) Hash(password string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hash", password)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Hash indicates an expected call of Hash.
+func (mr *MockPasswordHasherMockRecorder) Hash(password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hash", reflect.TypeOf((*MockPasswordHasher)(nil).Hash), password)
+}
+
+// NeedsRehash mocks base method.
+func (m *MockPasswordHasher) NeedsRehash(hash string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NeedsRehash", hash)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// NeedsRehash indicates an expected call of NeedsRehash.
+func (mr *MockPasswordHasherMockRecorder) NeedsRehash(hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NeedsRehash", reflect.TypeOf((*MockPasswordHasher)(nil).NeedsRehash), hash)
+}
+
+// Verify mocks base method.
+func (m *MockPasswordHasher) Verify(password, hash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", password, hash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockPasswordHasherMockRecorder) Verify(password, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockPasswordHasher)(nil).Verify), password, hash)
+}
+
+// MockTokenRepository is a mock of TokenRepository interface.
+type MockTokenRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenRepositoryMockRecorder
+}
+
+// MockTokenRepositoryMockRecorder is the mock recorder for MockTokenRepository.
+type MockTokenRepositoryMockRecorder struct {
+	mock *MockTokenRepository
+}
+
+// NewMockTokenRepository creates a new mock instance.
+func NewMockTokenRepository(ctrl *gomock.Controller) *MockTokenRepository {
+	mock := &MockTokenRepository{ctrl: ctrl}
+	mock.recorder = &MockTokenRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenRepository) EXPECT() *MockTokenRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateRefresh mocks base method.
+func (m *MockTokenRepository) CreateRefresh(ctx context.Context, t entity.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefresh", ctx, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRefresh indicates an expected call of CreateRefresh.
+func (mr *MockTokenRepositoryMockRecorder) CreateRefresh(ctx, t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefresh", reflect.TypeOf((*MockTokenRepository)(nil).CreateRefresh), ctx, t)
+}
+
+// FindRefresh mocks base method.
+func (m *MockTokenRepository) FindRefresh(ctx context.Context, hash string) (entity.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRefresh", ctx, hash)
+	ret0, _ := ret[0].(entity.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRefresh indicates an expected call of FindRefresh.
+func (mr *MockTokenRepositoryMockRecorder) FindRefresh(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRefresh", reflect.TypeOf((*MockTokenRepository)(nil).FindRefresh), ctx, hash)
+}
+
+// IsAccessRevoked mocks base method.
+func (m *MockTokenRepository) IsAccessRevoked(ctx context.Context, jti string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsAccessRevoked", ctx, jti)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsAccessRevoked indicates an expected call of IsAccessRevoked.
+func (mr *MockTokenRepositoryMockRecorder) IsAccessRevoked(ctx, jti interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAccessRevoked", reflect.TypeOf((*MockTokenRepository)(nil).IsAccessRevoked), ctx, jti)
+}
+
+// RevokeAccess mocks base method.
+func (m *MockTokenRepository) RevokeAccess(ctx context.Context, jti string, userID uint64, expires time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccess", ctx, jti, userID, expires)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccess indicates an expected call of RevokeAccess.
+func (mr *MockTokenRepositoryMockRecorder) RevokeAccess(ctx, jti, userID, expires interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccess", reflect.TypeOf((*MockTokenRepository)(nil).RevokeAccess), ctx, jti, userID, expires)
+}
+
+// RevokeAllRefresh mocks base method.
+func (m *MockTokenRepository) RevokeAllRefresh(ctx context.Context, userID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllRefresh", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllRefresh indicates an expected call of RevokeAllRefresh.
+func (mr *MockTokenRepositoryMockRecorder) RevokeAllRefresh(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllRefresh", reflect.TypeOf((*MockTokenRepository)(nil).RevokeAllRefresh), ctx, userID)
+}
+
+// RevokeRefresh mocks base method.
+func (m *MockTokenRepository) RevokeRefresh(ctx context.Context, userID uint64, hash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefresh", ctx, userID, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefresh indicates an expected call of RevokeRefresh.
+func (mr *MockTokenRepositoryMockRecorder) RevokeRefresh(ctx, userID, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefresh", reflect.TypeOf((*MockTokenRepository)(nil).RevokeRefresh), ctx, userID, hash)
+}
+
+// RotateRefresh mocks base method.
+func (m *MockTokenRepository) RotateRefresh(ctx context.Context, usedID uint64, next entity.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateRefresh", ctx, usedID, next)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RotateRefresh indicates an expected call of RotateRefresh.
+func (mr *MockTokenRepositoryMockRecorder) RotateRefresh(ctx, usedID, next interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefresh", reflect.TypeOf((*MockTokenRepository)(nil).RotateRefresh), ctx, usedID, next)
+}
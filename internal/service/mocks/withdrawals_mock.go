@@ -36,30 +36,30 @@ func (m *MockWithdrawalsRepository) EXPECT() *MockWithdrawalsRepositoryMockRecor
 }
 
 // Create mocks base method.
-func (m *MockWithdrawalsRepository) Create(ctx context.Context, widrawals entity.Withdrawals) error {
+func (m *MockWithdrawalsRepository) Create(ctx context.Context, widrawals entity.Withdrawals, maxPayments uint64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, widrawals)
+	ret := m.ctrl.Call(m, "Create", ctx, widrawals, maxPayments)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockWithdrawalsRepositoryMockRecorder) Create(ctx, widrawals interface{}) *gomock.Call {
+func (mr *MockWithdrawalsRepositoryMockRecorder) Create(ctx, widrawals, maxPayments interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWithdrawalsRepository)(nil).Create), ctx, widrawals)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWithdrawalsRepository)(nil).Create), ctx, widrawals, maxPayments)
 }
 
-// GetAllByUser mocks base method.
-func (m *MockWithdrawalsRepository) GetAllByUser(ctx context.Context, userID uint64) ([]entity.Withdrawals, error) {
+// ListByUser mocks base method.
+func (m *MockWithdrawalsRepository) ListByUser(ctx context.Context, userID uint64, f entity.WithdrawalsFilter) ([]entity.Withdrawals, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllByUser", ctx, userID)
+	ret := m.ctrl.Call(m, "ListByUser", ctx, userID, f)
 	ret0, _ := ret[0].([]entity.Withdrawals)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllByUser indicates an expected call of GetAllByUser.
-func (mr *MockWithdrawalsRepositoryMockRecorder) GetAllByUser(ctx, userID interface{}) *gomock.Call {
+// ListByUser indicates an expected call of ListByUser.
+func (mr *MockWithdrawalsRepositoryMockRecorder) ListByUser(ctx, userID, f interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllByUser", reflect.TypeOf((*MockWithdrawalsRepository)(nil).GetAllByUser), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUser", reflect.TypeOf((*MockWithdrawalsRepository)(nil).ListByUser), ctx, userID, f)
 }
@@ -3,17 +3,21 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/middleware"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
 	repErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/repository/errors"
 	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
 )
 
 type OrderRepository interface {
 	GetByNumber(ctx context.Context, number string) (entity.Order, error)
-	GetAllByUser(ctx context.Context, userID uint64) ([]entity.Order, error)
+	ListByUser(ctx context.Context, userID uint64, f entity.OrderFilter) ([]entity.Order, error)
 	Create(ctx context.Context, order entity.Order) error
 }
 
@@ -27,9 +31,12 @@ func NewOrder(r OrderRepository, l Logger) *Order {
 }
 
 func (o *Order) Upload(ctx context.Context, orderNumber string) error {
+	ctx, span := tracer.Start(ctx, "Order.Upload")
+	defer span.End()
+
 	userID, ok := ctx.Value(middleware.KeyUserID).(uint64)
 	if !ok {
-		o.logger.Error("failed to get user id", srvErrors.ErrUnexpected)
+		o.logger.Error("failed to get user id", srvErrors.ErrUnexpected, logger.Request(ctx))
 		return srvErrors.ErrUnexpected
 	}
 
@@ -46,42 +53,89 @@ func (o *Order) Upload(ctx context.Context, orderNumber string) error {
 	if errors.Is(err, repErrors.ErrDuplicateKey) {
 		return o.checkExistingOrder(ctx, orderNumber, userID)
 	}
-	o.logger.Error("failed to upload order", err)
+	o.logger.Error("failed to upload order", err, logger.OrderNumber(orderNumber), logger.UserID(userID), logger.Request(ctx))
 	return srvErrors.ErrUnexpected
 }
 
-func (o *Order) List(ctx context.Context) (list []dto.Order, err error) {
+func (o *Order) List(ctx context.Context, q dto.OrderQuery) (page dto.OrderPage, err error) {
+	ctx, span := tracer.Start(ctx, "Order.List")
+	defer span.End()
+
 	userID, ok := ctx.Value(middleware.KeyUserID).(uint64)
 	if !ok {
-		o.logger.Error("failed to get user id", srvErrors.ErrUnexpected)
-		return list, srvErrors.ErrUnexpected
+		o.logger.Error("failed to get user id", srvErrors.ErrUnexpected, logger.Request(ctx))
+		return page, srvErrors.ErrUnexpected
+	}
+
+	filter, err := orderFilter(q)
+	if err != nil {
+		return page, err
 	}
+	limit := filter.Limit
+	// Лишняя запись показывает, есть ли следующая страница.
+	filter.Limit++
 
-	orders, err := o.repository.GetAllByUser(ctx, userID)
+	orders, err := o.repository.ListByUser(ctx, userID, filter)
 	if err != nil {
-		o.logger.Error("failed to get user orders", err)
-		return list, srvErrors.ErrUnexpected
+		o.logger.Error("failed to get user orders", err, logger.UserID(userID), logger.Request(ctx))
+		return page, srvErrors.ErrUnexpected
+	}
+
+	if len(orders) > limit {
+		orders = orders[:limit]
+		last := orders[limit-1]
+		page.NextCursor = encodeCursor(entity.OrderCursor{Uploaded: last.Uploaded, Number: last.Number})
 	}
 
 	for _, order := range orders {
 		item := dto.Order{
 			Number:   order.Number,
-			Status:   order.Status,
-			Uploaded: order.Updated,
+			Status:   userOrderStatus(order.Status),
+			Uploaded: order.Uploaded,
 		}
 		if order.Accrual > 0 {
 			item.Accrual = &order.Accrual
 		}
-		list = append(list, item)
+		page.Orders = append(page.Orders, item)
+	}
+
+	return page, nil
+}
+
+func orderFilter(q dto.OrderQuery) (f entity.OrderFilter, err error) {
+	var after entity.OrderCursor
+
+	f.Limit, f.Ascending, err = listParams(q.ListQuery, &after)
+	if err != nil {
+		return f, err
+	}
+	if q.Cursor != "" {
+		if after.Uploaded.IsZero() || after.Number == "" {
+			return f, fmt.Errorf("%w: invalid cursor", srvErrors.ErrListInvalidQuery)
+		}
+		f.After = &after
+	}
+
+	for _, status := range q.Statuses {
+		status = strings.ToUpper(status)
+		if !slices.Contains(entity.OrderStatuses, status) {
+			return f, fmt.Errorf("%w: unknown order status %q", srvErrors.ErrListInvalidQuery, status)
+		}
+		f.Statuses = append(f.Statuses, status)
+		if status == entity.OrderStatusProcessing {
+			f.Statuses = append(f.Statuses, entity.OrderStatusFailed)
+		}
 	}
 
-	return list, nil
+	f.From, f.To = q.From, q.To
+
+	return f, nil
 }
 
 func (o *Order) checkExistingOrder(ctx context.Context, orderNumber string, userID uint64) error {
 	order, err := o.repository.GetByNumber(ctx, orderNumber)
 	if err != nil {
-		o.logger.Error("failed get existing order", err)
+		o.logger.Error("failed get existing order", err, logger.OrderNumber(orderNumber), logger.UserID(userID), logger.Request(ctx))
 		return srvErrors.ErrUnexpected
 	}
 	if order.UserID == userID {
@@ -89,3 +143,12 @@ func (o *Order) checkExistingOrder(ctx context.Context, orderNumber string, user
 	}
 	return srvErrors.ErrOrderUploadedByAnotherUser
 }
+
+// userOrderStatus скрывает от пользователя FAILED: для него заказ все еще обрабатывается.
+func userOrderStatus(status string) string {
+	if status == entity.OrderStatusFailed {
+		return entity.OrderStatusProcessing
+	}
+
+	return status
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -11,6 +12,7 @@ import (
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/middleware"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
 	repErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/repository/errors"
 	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/mocks"
@@ -67,7 +69,7 @@ func TestOrder_Upload(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Error("failed to get user id", gomock.All())
+					Error("failed to get user id", gomock.All(), gomock.Any())
 				return logger
 			},
 			want: srvErrors.ErrUnexpected,
@@ -163,7 +165,7 @@ func TestOrder_Upload(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Error("failed get existing order", gomock.All())
+					Error("failed get existing order", gomock.All(), gomock.Any(), gomock.Any(), gomock.Any())
 				return logger
 			},
 			want: srvErrors.ErrUnexpected,
@@ -184,7 +186,7 @@ func TestOrder_Upload(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Error("failed to upload order", gomock.All())
+					Error("failed to upload order", gomock.All(), gomock.Any(), gomock.Any(), gomock.Any())
 				return logger
 			},
 			want: srvErrors.ErrUnexpected,
@@ -205,39 +207,75 @@ func TestOrder_Upload(t *testing.T) {
 func TestOrder_List(t *testing.T) {
 	userID := uint64(13)
 	userIDctx := context.WithValue(context.Background(), middleware.KeyUserID, userID)
+	uploaded := time.Date(2025, 10, 8, 10, 0, 0, 0, time.UTC)
 	orderEntities := []entity.Order{
-		{Number: "5062821234567892", Status: entity.OrderStatusNew},
-		{Number: "5062821234567819", Status: entity.OrderStatusProcessed, Accrual: 100},
+		{Number: "5062821234567892", Status: entity.OrderStatusNew, Uploaded: uploaded},
+		{
+			Number:   "5062821234567819",
+			Status:   entity.OrderStatusProcessed,
+			Accrual:  money.New(100, 0),
+			Uploaded: uploaded.Add(-time.Hour),
+			Updated:  uploaded,
+		},
 	}
 	orderDTOlist := []dto.Order{
-		{Number: "5062821234567892", Status: entity.OrderStatusNew},
+		{Number: "5062821234567892", Status: entity.OrderStatusNew, Uploaded: uploaded},
 		{
-			Number:  "5062821234567819",
-			Status:  entity.OrderStatusProcessed,
-			Accrual: &orderEntities[1].Accrual,
+			Number:   "5062821234567819",
+			Status:   entity.OrderStatusProcessed,
+			Accrual:  &orderEntities[1].Accrual,
+			Uploaded: uploaded.Add(-time.Hour),
 		},
 	}
+	cursor := entity.OrderCursor{Uploaded: uploaded, Number: "5062821234567892"}
 
 	type want struct {
-		list []dto.Order
+		page dto.OrderPage
 		err  error
 	}
 
 	tests := []struct {
 		name   string
 		ctx    context.Context
+		query  dto.OrderQuery
 		rSetup func(t *testing.T) OrderRepository
 		lSetup func(t *testing.T) Logger
 		want   want
 	}{
 		{
-			name: "success",
-			ctx:  userIDctx,
+			name:  "success",
+			ctx:   userIDctx,
+			query: dto.OrderQuery{},
+			rSetup: func(t *testing.T) OrderRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockOrderRepository(ctrl)
+				repository.EXPECT().
+					ListByUser(gomock.All(), userID, entity.OrderFilter{Limit: defaultListLimit + 1}).
+					Return(orderEntities, nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				page: dto.OrderPage{Orders: orderDTOlist},
+				err:  nil,
+			},
+		},
+		{
+			name:  "success_next_page",
+			ctx:   userIDctx,
+			query: dto.OrderQuery{ListQuery: dto.ListQuery{Limit: 1}},
 			rSetup: func(t *testing.T) OrderRepository {
 				ctrl := gomock.NewController(t)
 				repository := mocks.NewMockOrderRepository(ctrl)
 				repository.EXPECT().
-					GetAllByUser(gomock.All(), userID).
+					ListByUser(gomock.All(), userID, entity.OrderFilter{Limit: 2}).
 					Return(orderEntities, nil)
 				return repository
 			},
@@ -250,18 +288,90 @@ func TestOrder_List(t *testing.T) {
 				return logger
 			},
 			want: want{
-				list: orderDTOlist,
+				page: dto.OrderPage{Orders: orderDTOlist[:1], NextCursor: encodeCursor(cursor)},
 				err:  nil,
 			},
 		},
 		{
-			name: "success_empty_list",
+			name: "success_with_cursor_and_filters",
 			ctx:  userIDctx,
+			query: dto.OrderQuery{
+				ListQuery: dto.ListQuery{
+					Limit:  10,
+					Cursor: encodeCursor(cursor),
+					Sort:   dto.SortAsc,
+					From:   uploaded.Add(-24 * time.Hour),
+				},
+				Statuses: []string{"processed", "INVALID"},
+			},
+			rSetup: func(t *testing.T) OrderRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockOrderRepository(ctrl)
+				repository.EXPECT().
+					ListByUser(gomock.All(), userID, entity.OrderFilter{
+						Statuses:  []string{entity.OrderStatusProcessed, entity.OrderStatusInvalid},
+						From:      uploaded.Add(-24 * time.Hour),
+						After:     &cursor,
+						Ascending: true,
+						Limit:     11,
+					}).
+					Return(orderEntities[1:], nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				page: dto.OrderPage{Orders: orderDTOlist[1:]},
+				err:  nil,
+			},
+		},
+		{
+			name:  "success_failed_shown_as_processing",
+			ctx:   userIDctx,
+			query: dto.OrderQuery{Statuses: []string{"processing"}},
 			rSetup: func(t *testing.T) OrderRepository {
 				ctrl := gomock.NewController(t)
 				repository := mocks.NewMockOrderRepository(ctrl)
 				repository.EXPECT().
-					GetAllByUser(gomock.All(), userID).
+					ListByUser(gomock.All(), userID, entity.OrderFilter{
+						Statuses: []string{entity.OrderStatusProcessing, entity.OrderStatusFailed},
+						Limit:    defaultListLimit + 1,
+					}).
+					Return([]entity.Order{
+						{Number: "5062821234567892", Status: entity.OrderStatusFailed, Uploaded: uploaded},
+					}, nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				page: dto.OrderPage{Orders: []dto.Order{
+					{Number: "5062821234567892", Status: entity.OrderStatusProcessing, Uploaded: uploaded},
+				}},
+				err: nil,
+			},
+		},
+		{
+			name:  "success_empty_list",
+			ctx:   userIDctx,
+			query: dto.OrderQuery{},
+			rSetup: func(t *testing.T) OrderRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockOrderRepository(ctrl)
+				repository.EXPECT().
+					ListByUser(gomock.All(), userID, gomock.All()).
 					Return([]entity.Order{}, nil)
 				return repository
 			},
@@ -274,18 +384,44 @@ func TestOrder_List(t *testing.T) {
 				return logger
 			},
 			want: want{
-				list: []dto.Order(nil),
+				page: dto.OrderPage{},
 				err:  nil,
 			},
 		},
 		{
-			name: "negative_without_userID",
-			ctx:  context.Background(),
+			name:  "negative_unknown_status",
+			ctx:   userIDctx,
+			query: dto.OrderQuery{Statuses: []string{"LOST"}},
+			rSetup: func(t *testing.T) OrderRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockOrderRepository(ctrl)
+				repository.EXPECT().
+					ListByUser(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				page: dto.OrderPage{},
+				err:  srvErrors.ErrListInvalidQuery,
+			},
+		},
+		{
+			name:  "negative_invalid_cursor",
+			ctx:   userIDctx,
+			query: dto.OrderQuery{ListQuery: dto.ListQuery{Cursor: "not a cursor"}},
 			rSetup: func(t *testing.T) OrderRepository {
 				ctrl := gomock.NewController(t)
 				repository := mocks.NewMockOrderRepository(ctrl)
 				repository.EXPECT().
-					GetAllByUser(gomock.All(), userID).
+					ListByUser(gomock.All(), gomock.All(), gomock.All()).
 					Times(0)
 				return repository
 			},
@@ -293,22 +429,73 @@ func TestOrder_List(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Error("failed to get user id", gomock.All())
+					Error("", gomock.All()).
+					Times(0)
 				return logger
 			},
 			want: want{
-				list: []dto.Order(nil),
+				page: dto.OrderPage{},
+				err:  srvErrors.ErrListInvalidQuery,
+			},
+		},
+		{
+			name:  "negative_limit_too_large",
+			ctx:   userIDctx,
+			query: dto.OrderQuery{ListQuery: dto.ListQuery{Limit: maxListLimit + 1}},
+			rSetup: func(t *testing.T) OrderRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockOrderRepository(ctrl)
+				repository.EXPECT().
+					ListByUser(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				page: dto.OrderPage{},
+				err:  srvErrors.ErrListInvalidQuery,
+			},
+		},
+		{
+			name:  "negative_without_userID",
+			ctx:   context.Background(),
+			query: dto.OrderQuery{},
+			rSetup: func(t *testing.T) OrderRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockOrderRepository(ctrl)
+				repository.EXPECT().
+					ListByUser(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to get user id", gomock.All(), gomock.Any())
+				return logger
+			},
+			want: want{
+				page: dto.OrderPage{},
 				err:  srvErrors.ErrUnexpected,
 			},
 		},
 		{
-			name: "negative_repository_error",
-			ctx:  userIDctx,
+			name:  "negative_repository_error",
+			ctx:   userIDctx,
+			query: dto.OrderQuery{},
 			rSetup: func(t *testing.T) OrderRepository {
 				ctrl := gomock.NewController(t)
 				repository := mocks.NewMockOrderRepository(ctrl)
 				repository.EXPECT().
-					GetAllByUser(gomock.All(), userID).
+					ListByUser(gomock.All(), userID, gomock.All()).
 					Return([]entity.Order{}, fmt.Errorf("any error"))
 				return repository
 			},
@@ -316,11 +503,11 @@ func TestOrder_List(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Error("failed to get user orders", gomock.All())
+					Error("failed to get user orders", gomock.All(), gomock.Any())
 				return logger
 			},
 			want: want{
-				list: []dto.Order(nil),
+				page: dto.OrderPage{},
 				err:  srvErrors.ErrUnexpected,
 			},
 		},
@@ -331,8 +518,8 @@ func TestOrder_List(t *testing.T) {
 			repository := test.rSetup(t)
 			logger := test.lSetup(t)
 			orderService := NewOrder(repository, logger)
-			list, err := orderService.List(test.ctx)
-			assert.Equal(t, test.want.list, list, "Get user orders error")
+			page, err := orderService.List(test.ctx, test.query)
+			assert.Equal(t, test.want.page, page, "Get user orders page")
 			assert.ErrorIs(t, err, test.want.err, "Get user orders error")
 		})
 	}
@@ -5,44 +5,74 @@ import (
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/dto"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/metrics"
 )
 
 type ProcessingRepository interface {
-	OrderNubmersForProcess(ctx context.Context, statuses []string) ([]string, error)
+	ClaimOrdersForProcess(ctx context.Context, statuses []string, lease entity.OrderLease) ([]string, error)
+	ReleaseOrders(ctx context.Context, owner string, numbers []string) error
 	ProcessOrder(ctx context.Context, order entity.Order) error
-	MarkOrderForRetryOrInvalid(
-		ctx context.Context,
-		number string,
-		toStatus string,
-		ivalidStatus string,
-	) error
+	MarkOrderForRetry(ctx context.Context, number string, f entity.OrderFailure) error
 }
 
+// RetryLimits заказ, который система расчета не знает, становится INVALID после Unregistered повторов,
+// а заказ, опрос которого не удается из-за ошибок, становится FAILED после Failed повторов.
+type RetryLimits struct {
+	Unregistered uint64
+	Failed       uint64
+}
+
+const reasonNotRegistered = "order is not registered in accrual system"
+
 type Processing struct {
 	reository ProcessingRepository
 	logger    Logger
+	lease     entity.OrderLease
+	limits    RetryLimits
 }
 
-func NewProcessing(r ProcessingRepository, l Logger) *Processing {
-	return &Processing{reository: r, logger: l}
+func NewProcessing(r ProcessingRepository, l Logger, lease entity.OrderLease, limits RetryLimits) *Processing {
+	return &Processing{reository: r, logger: l, lease: lease, limits: limits}
 }
 
-func (p *Processing) ListToProccess(ctx context.Context) (orderNumbers []string) {
+// ListToProccess выдает не больше limit заказов и не больше размера пачки из настроек.
+func (p *Processing) ListToProccess(ctx context.Context, limit int) (orderNumbers []string) {
+	ctx, span := tracer.Start(ctx, "Processing.ListToProccess")
+	defer span.End()
+
 	statuses := []string{
 		entity.OrderStatusNew,
 		entity.OrderStatusProcessing,
 	}
 
-	orderNumbers, err := p.reository.OrderNubmersForProcess(ctx, statuses)
+	lease := p.lease
+	lease.Limit = min(lease.Limit, limit)
+
+	orderNumbers, err := p.reository.ClaimOrdersForProcess(ctx, statuses, lease)
 	if err != nil {
-		p.logger.Error("failed to get orders for process", err)
+		p.logger.Error("failed to get orders for process", err, logger.Request(ctx))
 		return orderNumbers
 	}
 
 	return orderNumbers
 }
 
+// Release возвращает в очередь полученные, но не отправленные на обработку заказы.
+func (p *Processing) Release(ctx context.Context, orderNumbers []string) {
+	ctx, span := tracer.Start(ctx, "Processing.Release")
+	defer span.End()
+
+	err := p.reository.ReleaseOrders(ctx, p.lease.Owner, orderNumbers)
+	if err != nil {
+		p.logger.Error("failed to release orders", err, logger.Request(ctx))
+	}
+}
+
 func (p *Processing) ProsessOrder(ctx context.Context, order dto.Order) {
+	ctx, span := tracer.Start(ctx, "Processing.ProsessOrder")
+	defer span.End()
+
 	ent := entity.Order{
 		Number:  order.Number,
 		Status:  mapStatus(order.Status),
@@ -55,19 +85,39 @@ func (p *Processing) ProsessOrder(ctx context.Context, order dto.Order) {
 
 	err := p.reository.ProcessOrder(ctx, ent)
 	if err != nil {
-		p.logger.Error("failed to process order", err)
+		p.logger.Error("failed to process order", err, logger.OrderNumber(order.Number), logger.Request(ctx))
+		return
 	}
+
+	metrics.PointsAccrued(ent.Accrual.Float64())
 }
 
 func (p *Processing) MarkOrderForRetry(ctx context.Context, number string) {
-	err := p.reository.MarkOrderForRetryOrInvalid(
-		ctx,
-		number,
-		entity.OrderStatusNew,
-		entity.OrderStatusInvalid,
-	)
+	ctx, span := tracer.Start(ctx, "Processing.MarkOrderForRetry")
+	defer span.End()
+
+	err := p.reository.MarkOrderForRetry(ctx, number, entity.OrderFailure{
+		Reason:      reasonNotRegistered,
+		FinalStatus: entity.OrderStatusInvalid,
+		Retries:     p.limits.Unregistered,
+	})
+	if err != nil {
+		p.logger.Error("failed to mark order fo retry or invalid", err, logger.OrderNumber(number), logger.Request(ctx))
+	}
+}
+
+// MarkOrderFailed откладывает опрос заказа после ошибки запроса к системе расчета.
+func (p *Processing) MarkOrderFailed(ctx context.Context, number string, reason error) {
+	ctx, span := tracer.Start(ctx, "Processing.MarkOrderFailed")
+	defer span.End()
+
+	err := p.reository.MarkOrderForRetry(ctx, number, entity.OrderFailure{
+		Reason:      reason.Error(),
+		FinalStatus: entity.OrderStatusFailed,
+		Retries:     p.limits.Failed,
+	})
 	if err != nil {
-		p.logger.Error("failed to mark order fo retry or invalid", err)
+		p.logger.Error("failed to mark order for retry or failed", err, logger.OrderNumber(number), logger.Request(ctx))
 	}
 }
 
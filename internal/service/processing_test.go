@@ -4,15 +4,22 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/accrual/dto"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/mocks"
 )
 
+var (
+	testOrderLease  = entity.OrderLease{Owner: "test-worker", Limit: 10, Duration: time.Minute}
+	testRetryLimits = RetryLimits{Unregistered: 3, Failed: 10}
+)
+
 func TestProcessing_ListToProccess(t *testing.T) {
 	orderNumbers := []string{"5062821234567892", "5062821234567819"}
 	statuses := []string{
@@ -22,17 +29,40 @@ func TestProcessing_ListToProccess(t *testing.T) {
 
 	tests := []struct {
 		name   string
+		limit  int
 		rSetup func(t *testing.T) ProcessingRepository
 		lSetup func(t *testing.T) Logger
 		want   []string
 	}{
 		{
-			name: "success",
+			name:  "success",
+			limit: 20,
+			rSetup: func(t *testing.T) ProcessingRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockProcessingRepository(ctrl)
+				repository.EXPECT().
+					ClaimOrdersForProcess(gomock.All(), statuses, testOrderLease).
+					Return(orderNumbers, nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: orderNumbers,
+		},
+		{
+			name:  "limited_by_free_capacity",
+			limit: 2,
 			rSetup: func(t *testing.T) ProcessingRepository {
 				ctrl := gomock.NewController(t)
 				repository := mocks.NewMockProcessingRepository(ctrl)
 				repository.EXPECT().
-					OrderNubmersForProcess(gomock.All(), statuses).
+					ClaimOrdersForProcess(gomock.All(), statuses, entity.OrderLease{Owner: "test-worker", Limit: 2, Duration: time.Minute}).
 					Return(orderNumbers, nil)
 				return repository
 			},
@@ -47,12 +77,13 @@ func TestProcessing_ListToProccess(t *testing.T) {
 			want: orderNumbers,
 		},
 		{
-			name: "empty_list",
+			name:  "empty_list",
+			limit: 20,
 			rSetup: func(t *testing.T) ProcessingRepository {
 				ctrl := gomock.NewController(t)
 				repository := mocks.NewMockProcessingRepository(ctrl)
 				repository.EXPECT().
-					OrderNubmersForProcess(gomock.All(), statuses).
+					ClaimOrdersForProcess(gomock.All(), statuses, testOrderLease).
 					Return([]string{}, nil)
 				return repository
 			},
@@ -67,12 +98,13 @@ func TestProcessing_ListToProccess(t *testing.T) {
 			want: []string{},
 		},
 		{
-			name: "repository_error",
+			name:  "repository_error",
+			limit: 20,
 			rSetup: func(t *testing.T) ProcessingRepository {
 				ctrl := gomock.NewController(t)
 				repository := mocks.NewMockProcessingRepository(ctrl)
 				repository.EXPECT().
-					OrderNubmersForProcess(gomock.All(), statuses).
+					ClaimOrdersForProcess(gomock.All(), statuses, testOrderLease).
 					Return([]string{}, fmt.Errorf("any error"))
 				return repository
 			},
@@ -80,7 +112,7 @@ func TestProcessing_ListToProccess(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Error("failed to get orders for process", gomock.All())
+					Error("failed to get orders for process", gomock.All(), gomock.Any())
 				return logger
 			},
 			want: []string{},
@@ -90,8 +122,8 @@ func TestProcessing_ListToProccess(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			repository := test.rSetup(t)
 			logger := test.lSetup(t)
-			processingService := NewProcessing(repository, logger)
-			list := processingService.ListToProccess(context.Background())
+			processingService := NewProcessing(repository, logger, testOrderLease, testRetryLimits)
+			list := processingService.ListToProccess(context.Background(), test.limit)
 			assert.Equal(t, test.want, list, "Get orders numbers")
 		})
 	}
@@ -101,7 +133,7 @@ func TestProcessing_ProsessOrder(t *testing.T) {
 	orderDTO := dto.Order{
 		Number:  "5062821234567892",
 		Status:  dto.OrderStatusRegistred,
-		Accrual: 100,
+		Accrual: money.New(100, 0),
 	}
 	orderEntiy := entity.Order{
 		Number: "5062821234567892",
@@ -146,7 +178,7 @@ func TestProcessing_ProsessOrder(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Error("failed to process order", gomock.All())
+					Error("failed to process order", gomock.All(), gomock.Any())
 				return logger
 			},
 		},
@@ -155,7 +187,7 @@ func TestProcessing_ProsessOrder(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			repository := test.rSetup(t)
 			logger := test.lSetup(t)
-			processingService := NewProcessing(repository, logger)
+			processingService := NewProcessing(repository, logger, testOrderLease, testRetryLimits)
 			processingService.ProsessOrder(context.Background(), orderDTO)
 		})
 	}
@@ -175,12 +207,11 @@ func TestProcessing_MarkOrderForRetry(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				repository := mocks.NewMockProcessingRepository(ctrl)
 				repository.EXPECT().
-					MarkOrderForRetryOrInvalid(
-						gomock.All(),
-						orderNumber,
-						entity.OrderStatusNew,
-						entity.OrderStatusInvalid,
-					).
+					MarkOrderForRetry(gomock.All(), orderNumber, entity.OrderFailure{
+						Reason:      "order is not registered in accrual system",
+						FinalStatus: entity.OrderStatusInvalid,
+						Retries:     3,
+					}).
 					Return(nil)
 				return repository
 			},
@@ -199,12 +230,11 @@ func TestProcessing_MarkOrderForRetry(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				repository := mocks.NewMockProcessingRepository(ctrl)
 				repository.EXPECT().
-					MarkOrderForRetryOrInvalid(
-						gomock.All(),
-						orderNumber,
-						entity.OrderStatusNew,
-						entity.OrderStatusInvalid,
-					).
+					MarkOrderForRetry(gomock.All(), orderNumber, entity.OrderFailure{
+						Reason:      "order is not registered in accrual system",
+						FinalStatus: entity.OrderStatusInvalid,
+						Retries:     3,
+					}).
 					Return(fmt.Errorf("any error"))
 				return repository
 			},
@@ -212,7 +242,7 @@ func TestProcessing_MarkOrderForRetry(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Error("failed to mark order fo retry or invalid", gomock.All())
+					Error("failed to mark order fo retry or invalid", gomock.All(), gomock.Any())
 				return logger
 			},
 		},
@@ -221,12 +251,71 @@ func TestProcessing_MarkOrderForRetry(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			repository := test.rSetup(t)
 			logger := test.lSetup(t)
-			processingService := NewProcessing(repository, logger)
+			processingService := NewProcessing(repository, logger, testOrderLease, testRetryLimits)
 			processingService.MarkOrderForRetry(context.Background(), orderNumber)
 		})
 	}
 }
 
+func TestProcessing_MarkOrderFailed(t *testing.T) {
+	orderNumber := "5062821234567892"
+	failure := entity.OrderFailure{
+		Reason:      "internal server error",
+		FinalStatus: entity.OrderStatusFailed,
+		Retries:     10,
+	}
+
+	tests := []struct {
+		name   string
+		rSetup func(t *testing.T) ProcessingRepository
+		lSetup func(t *testing.T) Logger
+	}{
+		{
+			name: "success",
+			rSetup: func(t *testing.T) ProcessingRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockProcessingRepository(ctrl)
+				repository.EXPECT().
+					MarkOrderForRetry(gomock.All(), orderNumber, failure).
+					Return(nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+		},
+		{
+			name: "repository_error",
+			rSetup: func(t *testing.T) ProcessingRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockProcessingRepository(ctrl)
+				repository.EXPECT().
+					MarkOrderForRetry(gomock.All(), orderNumber, failure).
+					Return(fmt.Errorf("any error"))
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to mark order for retry or failed", gomock.All(), gomock.Any())
+				return logger
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			processingService := NewProcessing(test.rSetup(t), test.lSetup(t), testOrderLease, testRetryLimits)
+			processingService.MarkOrderFailed(context.Background(), orderNumber, fmt.Errorf("internal server error"))
+		})
+	}
+}
+
 func Test_mapStatus(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -268,3 +357,57 @@ func Test_mapStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessing_Release(t *testing.T) {
+	orderNumbers := []string{"5062821234567892", "5062821234567819"}
+
+	tests := []struct {
+		name   string
+		rSetup func(t *testing.T) ProcessingRepository
+		lSetup func(t *testing.T) Logger
+	}{
+		{
+			name: "success",
+			rSetup: func(t *testing.T) ProcessingRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockProcessingRepository(ctrl)
+				repository.EXPECT().
+					ReleaseOrders(gomock.All(), testOrderLease.Owner, orderNumbers).
+					Return(nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+		},
+		{
+			name: "repository_error",
+			rSetup: func(t *testing.T) ProcessingRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockProcessingRepository(ctrl)
+				repository.EXPECT().
+					ReleaseOrders(gomock.All(), testOrderLease.Owner, orderNumbers).
+					Return(fmt.Errorf("any error"))
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to release orders", gomock.All(), gomock.Any())
+				return logger
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			processingService := NewProcessing(test.rSetup(t), test.lSetup(t), testOrderLease, testRetryLimits)
+			processingService.Release(context.Background(), orderNumbers)
+		})
+	}
+}
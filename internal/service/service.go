@@ -1,9 +1,13 @@
 package service
 
-import "strconv"
+import (
+	"strconv"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+)
 
 type Logger interface {
-	Error(message string, err error)
+	Error(message string, err error, fields ...logger.Field)
 }
 
 func isOrderNumberValid(number string) bool {
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+)
+
+type LoginAttemptRepository interface {
+	LockedUntil(ctx context.Context, keys []string) (time.Time, error)
+	Fail(ctx context.Context, key string, window time.Duration) (int, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+	DeleteExpired(ctx context.Context, window time.Duration) error
+}
+
+// ThrottlePolicy задает, сколько неудач прощается до первой блокировки.
+// Каждая следующая неудача удваивает блокировку, но не больше MaxDelay.
+type ThrottlePolicy struct {
+	FreeAttempts int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+}
+
+// Счетчик неудач забывается через час без новых неудач,
+// это дольше максимальной блокировки, поэтому она не обнуляется раньше времени.
+const throttleWindow = time.Hour
+
+var (
+	DefaultLoginPolicy = ThrottlePolicy{FreeAttempts: 5, BaseDelay: time.Second, MaxDelay: 15 * time.Minute}
+	DefaultIPPolicy    = ThrottlePolicy{FreeAttempts: 20, BaseDelay: time.Second, MaxDelay: 15 * time.Minute}
+)
+
+func (p ThrottlePolicy) delay(failures int) time.Duration {
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, p.MaxDelay)
+}
+
+// LoginThrottle ограничивает подбор паролей отдельно по логину и по IP адресу.
+// При недоступности хранилища вход не блокируется, ошибка только логируется.
+type LoginThrottle struct {
+	repository  LoginAttemptRepository
+	logger      Logger
+	loginPolicy ThrottlePolicy
+	ipPolicy    ThrottlePolicy
+	now         func() time.Time
+}
+
+func NewLoginThrottle(r LoginAttemptRepository, l Logger, loginPolicy, ipPolicy ThrottlePolicy) *LoginThrottle {
+	return &LoginThrottle{
+		repository:  r,
+		logger:      l,
+		loginPolicy: loginPolicy,
+		ipPolicy:    ipPolicy,
+		now:         time.Now,
+	}
+}
+
+// Allow возвращает *RetryError, если логин или адрес заблокированы.
+func (t *LoginThrottle) Allow(ctx context.Context, login, ip string) error {
+	ctx, span := tracer.Start(ctx, "LoginThrottle.Allow")
+	defer span.End()
+
+	until, err := t.repository.LockedUntil(ctx, []string{loginKey(login), ipKey(ip)})
+	if err != nil {
+		t.logger.Error("failed to check login lock", err, logger.Request(ctx))
+		return nil
+	}
+
+	if wait := until.Sub(t.now()); wait > 0 {
+		return &srvErrors.RetryError{Err: srvErrors.ErrAuthTooManyAttempts, After: wait}
+	}
+
+	return nil
+}
+
+func (t *LoginThrottle) Fail(ctx context.Context, login, ip string) {
+	ctx, span := tracer.Start(ctx, "LoginThrottle.Fail")
+	defer span.End()
+
+	t.fail(ctx, loginKey(login), t.loginPolicy)
+	t.fail(ctx, ipKey(ip), t.ipPolicy)
+}
+
+// Succeed сбрасывает счетчик логина. Счетчик адреса не сбрасывается,
+// иначе перебор можно было бы продолжать, периодически входя в свой аккаунт.
+func (t *LoginThrottle) Succeed(ctx context.Context, login string) {
+	ctx, span := tracer.Start(ctx, "LoginThrottle.Succeed")
+	defer span.End()
+
+	if err := t.repository.Reset(ctx, loginKey(login)); err != nil {
+		t.logger.Error("failed to reset login attempts", err, logger.Request(ctx))
+	}
+}
+
+// Cleanup удаляет забытые счетчики, вызывается периодически в фоне.
+func (t *LoginThrottle) Cleanup(ctx context.Context) {
+	if err := t.repository.DeleteExpired(ctx, throttleWindow); err != nil {
+		t.logger.Error("failed to delete stale login attempts", err)
+	}
+}
+
+func (t *LoginThrottle) fail(ctx context.Context, key string, policy ThrottlePolicy) {
+	failures, err := t.repository.Fail(ctx, key, throttleWindow)
+	if err != nil {
+		t.logger.Error("failed to register login failure", err, logger.Request(ctx))
+		return
+	}
+
+	delay := policy.delay(failures)
+	if delay == 0 {
+		return
+	}
+
+	if err := t.repository.Lock(ctx, key, t.now().Add(delay)); err != nil {
+		t.logger.Error("failed to lock login attempts", err, logger.Request(ctx))
+	}
+}
+
+func loginKey(login string) string {
+	return "login:" + login
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
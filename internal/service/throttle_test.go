@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/mocks"
+)
+
+var testThrottlePolicy = ThrottlePolicy{FreeAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Minute}
+
+func TestThrottlePolicy_delay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 1, want: 0},
+		{failures: 3, want: 0},
+		{failures: 4, want: time.Second},
+		{failures: 5, want: 2 * time.Second},
+		{failures: 9, want: 32 * time.Second},
+		{failures: 10, want: time.Minute},
+		{failures: 1000, want: time.Minute},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("failures_%d", test.failures), func(t *testing.T) {
+			assert.Equal(t, test.want, testThrottlePolicy.delay(test.failures), "Lock delay")
+		})
+	}
+}
+
+func TestLoginThrottle_Allow(t *testing.T) {
+	now := time.Date(2025, 10, 6, 10, 0, 0, 0, time.UTC)
+	keys := []string{"login:testLogin", "ip:192.0.2.1"}
+
+	tests := []struct {
+		name   string
+		rSetup func(t *testing.T) LoginAttemptRepository
+		lSetup func(t *testing.T) Logger
+		wait   time.Duration
+	}{
+		{
+			name: "not_locked",
+			rSetup: func(t *testing.T) LoginAttemptRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockLoginAttemptRepository(ctrl)
+				repository.EXPECT().
+					LockedUntil(gomock.All(), keys).
+					Return(time.Time{}, nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			wait: 0,
+		},
+		{
+			name: "lock_expired",
+			rSetup: func(t *testing.T) LoginAttemptRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockLoginAttemptRepository(ctrl)
+				repository.EXPECT().
+					LockedUntil(gomock.All(), keys).
+					Return(now.Add(-time.Second), nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			wait: 0,
+		},
+		{
+			name: "negative_locked",
+			rSetup: func(t *testing.T) LoginAttemptRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockLoginAttemptRepository(ctrl)
+				repository.EXPECT().
+					LockedUntil(gomock.All(), keys).
+					Return(now.Add(90*time.Second), nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			wait: 90 * time.Second,
+		},
+		{
+			name: "repository_error_does_not_block",
+			rSetup: func(t *testing.T) LoginAttemptRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockLoginAttemptRepository(ctrl)
+				repository.EXPECT().
+					LockedUntil(gomock.All(), keys).
+					Return(time.Time{}, fmt.Errorf("any error"))
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to check login lock", gomock.All(), gomock.Any())
+				return logger
+			},
+			wait: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			throttle := NewLoginThrottle(test.rSetup(t), test.lSetup(t), testThrottlePolicy, testThrottlePolicy)
+			throttle.now = func() time.Time { return now }
+
+			err := throttle.Allow(context.Background(), "testLogin", "192.0.2.1")
+			if test.wait == 0 {
+				assert.Nil(t, err, "Login allowed")
+				return
+			}
+
+			var retryErr *srvErrors.RetryError
+			require.True(t, errors.As(err, &retryErr), "Retry error")
+			assert.ErrorIs(t, err, srvErrors.ErrAuthTooManyAttempts, "Throttle error")
+			assert.Equal(t, test.wait, retryErr.After, "Retry after")
+		})
+	}
+}
+
+func TestLoginThrottle_Fail(t *testing.T) {
+	now := time.Date(2025, 10, 6, 10, 0, 0, 0, time.UTC)
+	loginPolicy := testThrottlePolicy
+	ipPolicy := ThrottlePolicy{FreeAttempts: 10, BaseDelay: time.Second, MaxDelay: time.Minute}
+
+	tests := []struct {
+		name   string
+		rSetup func(t *testing.T) LoginAttemptRepository
+		lSetup func(t *testing.T) Logger
+	}{
+		{
+			name: "free_attempts",
+			rSetup: func(t *testing.T) LoginAttemptRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockLoginAttemptRepository(ctrl)
+				repository.EXPECT().
+					Fail(gomock.All(), "login:testLogin", throttleWindow).
+					Return(3, nil)
+				repository.EXPECT().
+					Fail(gomock.All(), "ip:192.0.2.1", throttleWindow).
+					Return(3, nil)
+				repository.EXPECT().
+					Lock(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+		},
+		{
+			name: "login_locked",
+			rSetup: func(t *testing.T) LoginAttemptRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockLoginAttemptRepository(ctrl)
+				repository.EXPECT().
+					Fail(gomock.All(), "login:testLogin", throttleWindow).
+					Return(5, nil)
+				repository.EXPECT().
+					Lock(gomock.All(), "login:testLogin", now.Add(2*time.Second)).
+					Return(nil)
+				repository.EXPECT().
+					Fail(gomock.All(), "ip:192.0.2.1", throttleWindow).
+					Return(5, nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+		},
+		{
+			name: "login_and_ip_locked",
+			rSetup: func(t *testing.T) LoginAttemptRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockLoginAttemptRepository(ctrl)
+				repository.EXPECT().
+					Fail(gomock.All(), "login:testLogin", throttleWindow).
+					Return(20, nil)
+				repository.EXPECT().
+					Lock(gomock.All(), "login:testLogin", now.Add(time.Minute)).
+					Return(nil)
+				repository.EXPECT().
+					Fail(gomock.All(), "ip:192.0.2.1", throttleWindow).
+					Return(11, nil)
+				repository.EXPECT().
+					Lock(gomock.All(), "ip:192.0.2.1", now.Add(time.Second)).
+					Return(nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+		},
+		{
+			name: "negative_repository_error",
+			rSetup: func(t *testing.T) LoginAttemptRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockLoginAttemptRepository(ctrl)
+				repository.EXPECT().
+					Fail(gomock.All(), gomock.All(), throttleWindow).
+					Return(0, fmt.Errorf("any error")).
+					Times(2)
+				repository.EXPECT().
+					Lock(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to register login failure", gomock.All(), gomock.Any()).
+					Times(2)
+				return logger
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			throttle := NewLoginThrottle(test.rSetup(t), test.lSetup(t), loginPolicy, ipPolicy)
+			throttle.now = func() time.Time { return now }
+
+			throttle.Fail(context.Background(), "testLogin", "192.0.2.1")
+		})
+	}
+}
+
+func TestLoginThrottle_Succeed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	repository := mocks.NewMockLoginAttemptRepository(ctrl)
+	repository.EXPECT().
+		Reset(gomock.All(), "login:testLogin").
+		Return(fmt.Errorf("any error"))
+	logger := mocks.NewMockLogger(ctrl)
+	logger.EXPECT().
+		Error("failed to reset login attempts", gomock.All(), gomock.Any())
+
+	throttle := NewLoginThrottle(repository, logger, testThrottlePolicy, testThrottlePolicy)
+	throttle.Succeed(context.Background(), "testLogin")
+}
+
+func TestLoginThrottle_Cleanup(t *testing.T) {
+	tests := []struct {
+		name   string
+		rSetup func(t *testing.T) LoginAttemptRepository
+		lSetup func(t *testing.T) Logger
+	}{
+		{
+			name: "success",
+			rSetup: func(t *testing.T) LoginAttemptRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockLoginAttemptRepository(ctrl)
+				repository.EXPECT().
+					DeleteExpired(gomock.All(), throttleWindow).
+					Return(nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+		},
+		{
+			name: "negative_repository_error",
+			rSetup: func(t *testing.T) LoginAttemptRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockLoginAttemptRepository(ctrl)
+				repository.EXPECT().
+					DeleteExpired(gomock.All(), throttleWindow).
+					Return(fmt.Errorf("any error"))
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to delete stale login attempts", gomock.All())
+				return logger
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			throttle := NewLoginThrottle(test.rSetup(t), test.lSetup(t), testThrottlePolicy, testThrottlePolicy)
+			throttle.Cleanup(context.Background())
+		})
+	}
+}
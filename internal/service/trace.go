@@ -0,0 +1,6 @@
+package service
+
+import "go.opentelemetry.io/otel"
+
+// Спаны сервисов называются по типу и методу, например Order.Upload.
+var tracer = otel.Tracer("github.com/EshkinKot1980/gophermart-loyalty/internal/service")
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/middleware"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+	repErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/repository/errors"
+	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/webhook"
+)
+
+const (
+	maxWebhooksPerUser = 5
+	maxWebhookURLLen   = 2048
+	webhookSecretSize  = 32
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, w entity.Webhook, maxPerUser int) (entity.Webhook, error)
+	ListByUser(ctx context.Context, userID uint64) ([]entity.Webhook, error)
+	Delete(ctx context.Context, userID, id uint64) error
+}
+
+type Webhook struct {
+	repository WebhookRepository
+	logger     Logger
+}
+
+func NewWebhook(r WebhookRepository, l Logger) *Webhook {
+	return &Webhook{repository: r, logger: l}
+}
+
+// Create регистрирует вебхук и возвращает секрет для проверки подписи событий.
+func (s *Webhook) Create(ctx context.Context, req dto.WebhookReq) (webhook dto.Webhook, err error) {
+	ctx, span := tracer.Start(ctx, "Webhook.Create")
+	defer span.End()
+
+	userID, ok := ctx.Value(middleware.KeyUserID).(uint64)
+	if !ok {
+		s.logger.Error("failed to get user id", srvErrors.ErrUnexpected, logger.Request(ctx))
+		return webhook, srvErrors.ErrUnexpected
+	}
+
+	rawURL := strings.TrimSpace(req.URL)
+	if err := validateWebhookURL(rawURL); err != nil {
+		return webhook, err
+	}
+
+	secret := make([]byte, webhookSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		s.logger.Error("failed to generate webhook secret", err, logger.Request(ctx))
+		return webhook, srvErrors.ErrUnexpected
+	}
+
+	created, err := s.repository.Create(
+		ctx,
+		entity.Webhook{UserID: userID, URL: rawURL, Secret: hex.EncodeToString(secret)},
+		maxWebhooksPerUser,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, repErrors.ErrDuplicateKey):
+			return webhook, srvErrors.ErrWebhookAlreadyExists
+		case errors.Is(err, repErrors.ErrNoRowsUpdated):
+			return webhook, srvErrors.ErrWebhookLimitReached
+		default:
+			s.logger.Error("failed to create webhook", err, logger.UserID(userID), logger.Request(ctx))
+			return webhook, srvErrors.ErrUnexpected
+		}
+	}
+
+	webhook = webhookDTO(created)
+	webhook.Secret = created.Secret
+
+	return webhook, nil
+}
+
+func (s *Webhook) List(ctx context.Context) (list []dto.Webhook, err error) {
+	ctx, span := tracer.Start(ctx, "Webhook.List")
+	defer span.End()
+
+	userID, ok := ctx.Value(middleware.KeyUserID).(uint64)
+	if !ok {
+		s.logger.Error("failed to get user id", srvErrors.ErrUnexpected, logger.Request(ctx))
+		return list, srvErrors.ErrUnexpected
+	}
+
+	webhooks, err := s.repository.ListByUser(ctx, userID)
+	if err != nil {
+		s.logger.Error("failed to get user webhooks", err, logger.UserID(userID), logger.Request(ctx))
+		return list, srvErrors.ErrUnexpected
+	}
+
+	for _, w := range webhooks {
+		list = append(list, webhookDTO(w))
+	}
+
+	return list, nil
+}
+
+func (s *Webhook) Delete(ctx context.Context, id uint64) error {
+	ctx, span := tracer.Start(ctx, "Webhook.Delete")
+	defer span.End()
+
+	userID, ok := ctx.Value(middleware.KeyUserID).(uint64)
+	if !ok {
+		s.logger.Error("failed to get user id", srvErrors.ErrUnexpected, logger.Request(ctx))
+		return srvErrors.ErrUnexpected
+	}
+
+	err := s.repository.Delete(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, repErrors.ErrNotFound) {
+			return srvErrors.ErrWebhookNotFound
+		}
+		s.logger.Error("failed to delete webhook", err, logger.UserID(userID), logger.Request(ctx))
+		return srvErrors.ErrUnexpected
+	}
+
+	return nil
+}
+
+func validateWebhookURL(rawURL string) error {
+	if len(rawURL) > maxWebhookURLLen {
+		return fmt.Errorf("%w: url too long, max %d characters", srvErrors.ErrWebhookInvalidURL, maxWebhookURLLen)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("%w: absolute http or https url expected", srvErrors.ErrWebhookInvalidURL)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials in url are not allowed", srvErrors.ErrWebhookInvalidURL)
+	}
+
+	// Имена проверяются диспетчером при каждой отправке, здесь отсекаются очевидные случаи.
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: internal addresses are not allowed", srvErrors.ErrWebhookInvalidURL)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !webhook.PublicAddr(addr) {
+		return fmt.Errorf("%w: internal addresses are not allowed", srvErrors.ErrWebhookInvalidURL)
+	}
+
+	return nil
+}
+
+func webhookDTO(w entity.Webhook) dto.Webhook {
+	return dto.Webhook{ID: w.ID, URL: w.URL, Created: w.Created}
+}
@@ -3,76 +3,129 @@ package service
 import (
 	"context"
 	"errors"
-	"math"
+	"fmt"
 
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/middleware"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/metrics"
 	repErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/repository/errors"
 	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
 )
 
 type WithdrawalsRepository interface {
-	Create(ctx context.Context, widrawals entity.Withdrawals) error
-	GetAllByUser(ctx context.Context, userID uint64) ([]entity.Withdrawals, error)
+	Create(ctx context.Context, widrawals entity.Withdrawals, maxPayments uint64) error
+	ListByUser(ctx context.Context, userID uint64, f entity.WithdrawalsFilter) ([]entity.Withdrawals, error)
 }
 
 type Withdrawals struct {
-	repository WithdrawalsRepository
-	logger     Logger
+	repository  WithdrawalsRepository
+	logger      Logger
+	maxPayments uint64
 }
 
-func NewWithdrawals(r WithdrawalsRepository, l Logger) *Withdrawals {
-	return &Withdrawals{repository: r, logger: l}
+// NewWithdrawals maxOrderPayments ограничивает число частичных списаний
+// в счет одного заказа, 1 запрещает повторную оплату заказа.
+func NewWithdrawals(r WithdrawalsRepository, l Logger, maxOrderPayments uint64) *Withdrawals {
+	return &Withdrawals{repository: r, logger: l, maxPayments: max(maxOrderPayments, 1)}
 }
 
 func (s *Withdrawals) Withdraw(ctx context.Context, w dto.Withdrawals) error {
+	ctx, span := tracer.Start(ctx, "Withdrawals.Withdraw")
+	defer span.End()
+
 	userID, ok := ctx.Value(middleware.KeyUserID).(uint64)
 	if !ok {
-		s.logger.Error("failed to get user id", srvErrors.ErrUnexpected)
+		s.logger.Error("failed to get user id", srvErrors.ErrUnexpected, logger.Request(ctx))
 		return srvErrors.ErrUnexpected
 	}
 
 	if !isOrderNumberValid(w.Order) {
 		return srvErrors.ErrOrderInvalidNumber
 	}
-	if math.Round(w.Sum*100)/100 <= 0 {
+	if w.Sum <= 0 {
 		return srvErrors.ErrWithdrawInvalidSum
 	}
 
 	entity := entity.Withdrawals{UserID: userID, OrderNumber: w.Order, Sum: w.Sum}
-	err := s.repository.Create(ctx, entity)
+	err := s.repository.Create(ctx, entity, s.maxPayments)
 	if err == nil {
+		metrics.PointsWithdrawn(w.Sum.Float64())
 		return nil
 	}
 
-	if errors.Is(err, repErrors.ErrNoRowsUpdated) {
+	switch {
+	case errors.Is(err, repErrors.ErrNoRowsUpdated):
 		return srvErrors.ErrWithdrawInsufficientFunds
+	case errors.Is(err, repErrors.ErrDuplicateKey):
+		return srvErrors.ErrWithdrawOrderAlreadyPaid
 	}
-	s.logger.Error("failed to withdraw", err)
+	s.logger.Error("failed to withdraw", err, logger.Request(ctx))
 	return srvErrors.ErrUnexpected
 }
 
-func (s *Withdrawals) List(ctx context.Context) (list []dto.WithdrawalsResp, err error) {
+func (s *Withdrawals) List(ctx context.Context, q dto.WithdrawalsQuery) (page dto.WithdrawalsPage, err error) {
+	ctx, span := tracer.Start(ctx, "Withdrawals.List")
+	defer span.End()
+
 	userID, ok := ctx.Value(middleware.KeyUserID).(uint64)
 	if !ok {
-		s.logger.Error("failed to get user id", srvErrors.ErrUnexpected)
-		return list, srvErrors.ErrUnexpected
+		s.logger.Error("failed to get user id", srvErrors.ErrUnexpected, logger.Request(ctx))
+		return page, srvErrors.ErrUnexpected
+	}
+
+	filter, err := withdrawalsFilter(q)
+	if err != nil {
+		return page, err
 	}
+	limit := filter.Limit
+	// Лишняя запись показывает, есть ли следующая страница.
+	filter.Limit++
 
-	entities, err := s.repository.GetAllByUser(ctx, userID)
+	entities, err := s.repository.ListByUser(ctx, userID, filter)
 	if err != nil {
-		s.logger.Error("failed to get user withdrawals", err)
-		return list, srvErrors.ErrUnexpected
+		s.logger.Error("failed to get user withdrawals", err, logger.UserID(userID), logger.Request(ctx))
+		return page, srvErrors.ErrUnexpected
+	}
+
+	if len(entities) > limit {
+		entities = entities[:limit]
+		last := entities[limit-1]
+		page.NextCursor = encodeCursor(entity.WithdrawalsCursor{Processed: last.Processed, ID: last.ID})
 	}
 
 	for _, entity := range entities {
-		list = append(list, dto.WithdrawalsResp{
+		page.Withdrawals = append(page.Withdrawals, dto.WithdrawalsResp{
 			Order:     entity.OrderNumber,
 			Sum:       entity.Sum,
 			Processed: entity.Processed,
 		})
 	}
 
-	return list, nil
+	return page, nil
+}
+
+func withdrawalsFilter(q dto.WithdrawalsQuery) (f entity.WithdrawalsFilter, err error) {
+	var after entity.WithdrawalsCursor
+
+	f.Limit, f.Ascending, err = listParams(q.ListQuery, &after)
+	if err != nil {
+		return f, err
+	}
+	if q.Cursor != "" {
+		if after.Processed.IsZero() || after.ID == 0 {
+			return f, fmt.Errorf("%w: invalid cursor", srvErrors.ErrListInvalidQuery)
+		}
+		f.After = &after
+	}
+
+	if q.MinSum != nil && q.MaxSum != nil && *q.MinSum > *q.MaxSum {
+		return f, fmt.Errorf("%w: min_sum must not exceed max_sum", srvErrors.ErrListInvalidQuery)
+	}
+
+	f.From, f.To = q.From, q.To
+	f.MinSum, f.MaxSum = q.MinSum, q.MaxSum
+
+	return f, nil
 }
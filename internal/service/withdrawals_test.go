@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -11,6 +12,7 @@ import (
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/dto"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/api/middleware"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
 	repErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/repository/errors"
 	srvErrors "github.com/EshkinKot1980/gophermart-loyalty/internal/service/errors"
 	"github.com/EshkinKot1980/gophermart-loyalty/internal/service/mocks"
@@ -31,7 +33,7 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 		{
 			name:        "success",
 			ctx:         userIDctx,
-			withdrawals: dto.Withdrawals{Order: "5062821234567892", Sum: 99.99},
+			withdrawals: dto.Withdrawals{Order: "5062821234567892", Sum: money.New(99, 99)},
 			rSetup: func(t *testing.T) WithdrawalsRepository {
 				ctrl := gomock.NewController(t)
 				repository := mocks.NewMockWithdrawalsRepository(ctrl)
@@ -39,8 +41,8 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 					Create(gomock.All(), entity.Withdrawals{
 						UserID:      userID,
 						OrderNumber: "5062821234567892",
-						Sum:         99.99,
-					}).
+						Sum:         money.New(99, 99),
+					}, uint64(1)).
 					Return(nil)
 				return repository
 			},
@@ -57,12 +59,12 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 		{
 			name:        "negative_without_userID",
 			ctx:         context.Background(),
-			withdrawals: dto.Withdrawals{Order: "5062821234567892", Sum: 99.99},
+			withdrawals: dto.Withdrawals{Order: "5062821234567892", Sum: money.New(99, 99)},
 			rSetup: func(t *testing.T) WithdrawalsRepository {
 				ctrl := gomock.NewController(t)
 				repository := mocks.NewMockWithdrawalsRepository(ctrl)
 				repository.EXPECT().
-					Create(gomock.All(), gomock.All()).
+					Create(gomock.All(), gomock.All(), gomock.All()).
 					Times(0)
 				return repository
 			},
@@ -70,7 +72,7 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Error("failed to get user id", gomock.All())
+					Error("failed to get user id", gomock.All(), gomock.Any())
 				return logger
 			},
 			want: srvErrors.ErrUnexpected,
@@ -78,12 +80,12 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 		{
 			name:        "negative_ivalid_order_number",
 			ctx:         userIDctx,
-			withdrawals: dto.Withdrawals{Order: "5062821234567899", Sum: 99.99},
+			withdrawals: dto.Withdrawals{Order: "5062821234567899", Sum: money.New(99, 99)},
 			rSetup: func(t *testing.T) WithdrawalsRepository {
 				ctrl := gomock.NewController(t)
 				repository := mocks.NewMockWithdrawalsRepository(ctrl)
 				repository.EXPECT().
-					Create(gomock.All(), gomock.All()).
+					Create(gomock.All(), gomock.All(), gomock.All()).
 					Times(0)
 				return repository
 			},
@@ -100,12 +102,12 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 		{
 			name:        "negative_ivalid_sum",
 			ctx:         userIDctx,
-			withdrawals: dto.Withdrawals{Order: "5062821234567892", Sum: 0.004},
+			withdrawals: dto.Withdrawals{Order: "5062821234567892", Sum: money.New(-5, 0)},
 			rSetup: func(t *testing.T) WithdrawalsRepository {
 				ctrl := gomock.NewController(t)
 				repository := mocks.NewMockWithdrawalsRepository(ctrl)
 				repository.EXPECT().
-					Create(gomock.All(), gomock.All()).
+					Create(gomock.All(), gomock.All(), gomock.All()).
 					Times(0)
 				return repository
 			},
@@ -122,7 +124,7 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 		{
 			name:        "negative_insufficient_funds",
 			ctx:         userIDctx,
-			withdrawals: dto.Withdrawals{Order: "5062821234567892", Sum: 99.99},
+			withdrawals: dto.Withdrawals{Order: "5062821234567892", Sum: money.New(99, 99)},
 			rSetup: func(t *testing.T) WithdrawalsRepository {
 				ctrl := gomock.NewController(t)
 				repository := mocks.NewMockWithdrawalsRepository(ctrl)
@@ -130,8 +132,8 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 					Create(gomock.All(), entity.Withdrawals{
 						UserID:      userID,
 						OrderNumber: "5062821234567892",
-						Sum:         99.99,
-					}).
+						Sum:         money.New(99, 99),
+					}, uint64(1)).
 					Return(repErrors.ErrNoRowsUpdated)
 				return repository
 			},
@@ -145,10 +147,36 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 			},
 			want: srvErrors.ErrWithdrawInsufficientFunds,
 		},
+		{
+			name:        "negative_order_already_paid",
+			ctx:         userIDctx,
+			withdrawals: dto.Withdrawals{Order: "5062821234567892", Sum: money.New(99, 99)},
+			rSetup: func(t *testing.T) WithdrawalsRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockWithdrawalsRepository(ctrl)
+				repository.EXPECT().
+					Create(gomock.All(), entity.Withdrawals{
+						UserID:      userID,
+						OrderNumber: "5062821234567892",
+						Sum:         money.New(99, 99),
+					}, uint64(1)).
+					Return(fmt.Errorf("wrapped: %w", repErrors.ErrDuplicateKey))
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: srvErrors.ErrWithdrawOrderAlreadyPaid,
+		},
 		{
 			name:        "negative_repository_error",
 			ctx:         userIDctx,
-			withdrawals: dto.Withdrawals{Order: "5062821234567892", Sum: 99.99},
+			withdrawals: dto.Withdrawals{Order: "5062821234567892", Sum: money.New(99, 99)},
 			rSetup: func(t *testing.T) WithdrawalsRepository {
 				ctrl := gomock.NewController(t)
 				repository := mocks.NewMockWithdrawalsRepository(ctrl)
@@ -156,8 +184,8 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 					Create(gomock.All(), entity.Withdrawals{
 						UserID:      userID,
 						OrderNumber: "5062821234567892",
-						Sum:         99.99,
-					}).
+						Sum:         money.New(99, 99),
+					}, uint64(1)).
 					Return(fmt.Errorf("any error"))
 				return repository
 			},
@@ -165,7 +193,7 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Error("failed to withdraw", gomock.All())
+					Error("failed to withdraw", gomock.All(), gomock.Any())
 				return logger
 			},
 			want: srvErrors.ErrUnexpected,
@@ -176,7 +204,7 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			repository := test.rSetup(t)
 			logger := test.lSetup(t)
-			service := NewWithdrawals(repository, logger)
+			service := NewWithdrawals(repository, logger, 1)
 			err := service.Withdraw(test.ctx, test.withdrawals)
 			assert.ErrorIs(t, err, test.want, "Create withdrawals error")
 		})
@@ -186,36 +214,41 @@ func TestWithdrawals_Withdraw(t *testing.T) {
 func TestWithdrawals_List(t *testing.T) {
 	userID := uint64(13)
 	userIDctx := context.WithValue(context.Background(), middleware.KeyUserID, userID)
+	processed := time.Date(2025, 10, 8, 10, 0, 0, 0, time.UTC)
 
 	entityList := []entity.Withdrawals{
-		{OrderNumber: "5062821234567892", Sum: 99.99},
-		{OrderNumber: "5062821234567819", Sum: 500},
+		{ID: 2, OrderNumber: "5062821234567892", Sum: money.New(99, 99), Processed: processed},
+		{ID: 1, OrderNumber: "5062821234567819", Sum: money.New(500, 0), Processed: processed.Add(-time.Hour)},
 	}
 	dtoList := []dto.WithdrawalsResp{
-		{Order: "5062821234567892", Sum: 99.99},
-		{Order: "5062821234567819", Sum: 500},
+		{Order: "5062821234567892", Sum: money.New(99, 99), Processed: processed},
+		{Order: "5062821234567819", Sum: money.New(500, 0), Processed: processed.Add(-time.Hour)},
 	}
+	cursor := entity.WithdrawalsCursor{Processed: processed, ID: 2}
+	minSum, maxSum := money.New(100, 0), money.New(1000, 0)
 
 	type want struct {
-		list []dto.WithdrawalsResp
+		page dto.WithdrawalsPage
 		err  error
 	}
 
 	tests := []struct {
 		name   string
 		ctx    context.Context
+		query  dto.WithdrawalsQuery
 		rSetup func(t *testing.T) WithdrawalsRepository
 		lSetup func(t *testing.T) Logger
 		want   want
 	}{
 		{
-			name: "success",
-			ctx:  userIDctx,
+			name:  "success",
+			ctx:   userIDctx,
+			query: dto.WithdrawalsQuery{},
 			rSetup: func(t *testing.T) WithdrawalsRepository {
 				ctrl := gomock.NewController(t)
 				repository := mocks.NewMockWithdrawalsRepository(ctrl)
 				repository.EXPECT().
-					GetAllByUser(userIDctx, userID).
+					ListByUser(gomock.Any(), userID, entity.WithdrawalsFilter{Limit: defaultListLimit + 1}).
 					Return(entityList, nil)
 				return repository
 			},
@@ -228,18 +261,79 @@ func TestWithdrawals_List(t *testing.T) {
 				return logger
 			},
 			want: want{
-				list: dtoList,
+				page: dto.WithdrawalsPage{Withdrawals: dtoList},
 				err:  nil,
 			},
 		},
 		{
-			name: "success_empty_list",
+			name:  "success_next_page",
+			ctx:   userIDctx,
+			query: dto.WithdrawalsQuery{ListQuery: dto.ListQuery{Limit: 1}},
+			rSetup: func(t *testing.T) WithdrawalsRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockWithdrawalsRepository(ctrl)
+				repository.EXPECT().
+					ListByUser(gomock.Any(), userID, entity.WithdrawalsFilter{Limit: 2}).
+					Return(entityList, nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				page: dto.WithdrawalsPage{Withdrawals: dtoList[:1], NextCursor: encodeCursor(cursor)},
+				err:  nil,
+			},
+		},
+		{
+			name: "success_with_cursor_and_filters",
 			ctx:  userIDctx,
+			query: dto.WithdrawalsQuery{
+				ListQuery: dto.ListQuery{Cursor: encodeCursor(cursor), To: processed},
+				MinSum:    &minSum,
+				MaxSum:    &maxSum,
+			},
+			rSetup: func(t *testing.T) WithdrawalsRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockWithdrawalsRepository(ctrl)
+				repository.EXPECT().
+					ListByUser(gomock.Any(), userID, entity.WithdrawalsFilter{
+						To:     processed,
+						MinSum: &minSum,
+						MaxSum: &maxSum,
+						After:  &cursor,
+						Limit:  defaultListLimit + 1,
+					}).
+					Return(entityList[1:], nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				page: dto.WithdrawalsPage{Withdrawals: dtoList[1:]},
+				err:  nil,
+			},
+		},
+		{
+			name:  "success_empty_list",
+			ctx:   userIDctx,
+			query: dto.WithdrawalsQuery{},
 			rSetup: func(t *testing.T) WithdrawalsRepository {
 				ctrl := gomock.NewController(t)
 				repository := mocks.NewMockWithdrawalsRepository(ctrl)
 				repository.EXPECT().
-					GetAllByUser(userIDctx, userID).
+					ListByUser(gomock.Any(), userID, gomock.All()).
 					Return([]entity.Withdrawals{}, nil)
 				return repository
 			},
@@ -252,18 +346,19 @@ func TestWithdrawals_List(t *testing.T) {
 				return logger
 			},
 			want: want{
-				list: []dto.WithdrawalsResp(nil),
+				page: dto.WithdrawalsPage{},
 				err:  nil,
 			},
 		},
 		{
-			name: "negative_without_userID",
-			ctx:  context.Background(),
+			name:  "negative_min_sum_exceeds_max_sum",
+			ctx:   userIDctx,
+			query: dto.WithdrawalsQuery{MinSum: &maxSum, MaxSum: &minSum},
 			rSetup: func(t *testing.T) WithdrawalsRepository {
 				ctrl := gomock.NewController(t)
 				repository := mocks.NewMockWithdrawalsRepository(ctrl)
 				repository.EXPECT().
-					GetAllByUser(gomock.All(), gomock.All()).
+					ListByUser(gomock.All(), gomock.All(), gomock.All()).
 					Times(0)
 				return repository
 			},
@@ -271,22 +366,75 @@ func TestWithdrawals_List(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Error("failed to get user id", gomock.All())
+					Error("", gomock.All()).
+					Times(0)
 				return logger
 			},
 			want: want{
-				list: []dto.WithdrawalsResp(nil),
-				err:  srvErrors.ErrUnexpected,
+				page: dto.WithdrawalsPage{},
+				err:  srvErrors.ErrListInvalidQuery,
 			},
 		},
 		{
-			name: "negative_repository_error",
+			name: "negative_empty_period",
 			ctx:  userIDctx,
+			query: dto.WithdrawalsQuery{
+				ListQuery: dto.ListQuery{From: processed, To: processed},
+			},
+			rSetup: func(t *testing.T) WithdrawalsRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockWithdrawalsRepository(ctrl)
+				repository.EXPECT().
+					ListByUser(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+			want: want{
+				page: dto.WithdrawalsPage{},
+				err:  srvErrors.ErrListInvalidQuery,
+			},
+		},
+		{
+			name:  "negative_without_userID",
+			ctx:   context.Background(),
+			query: dto.WithdrawalsQuery{},
+			rSetup: func(t *testing.T) WithdrawalsRepository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockWithdrawalsRepository(ctrl)
+				repository.EXPECT().
+					ListByUser(gomock.All(), gomock.All(), gomock.All()).
+					Times(0)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to get user id", gomock.All(), gomock.Any())
+				return logger
+			},
+			want: want{
+				page: dto.WithdrawalsPage{},
+				err:  srvErrors.ErrUnexpected,
+			},
+		},
+		{
+			name:  "negative_repository_error",
+			ctx:   userIDctx,
+			query: dto.WithdrawalsQuery{},
 			rSetup: func(t *testing.T) WithdrawalsRepository {
 				ctrl := gomock.NewController(t)
 				repository := mocks.NewMockWithdrawalsRepository(ctrl)
 				repository.EXPECT().
-					GetAllByUser(userIDctx, userID).
+					ListByUser(gomock.Any(), userID, gomock.All()).
 					Return([]entity.Withdrawals{}, fmt.Errorf("any error"))
 				return repository
 			},
@@ -294,11 +442,11 @@ func TestWithdrawals_List(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				logger := mocks.NewMockLogger(ctrl)
 				logger.EXPECT().
-					Error("failed to get user withdrawals", gomock.All())
+					Error("failed to get user withdrawals", gomock.All(), gomock.Any())
 				return logger
 			},
 			want: want{
-				list: []dto.WithdrawalsResp(nil),
+				page: dto.WithdrawalsPage{},
 				err:  srvErrors.ErrUnexpected,
 			},
 		},
@@ -308,9 +456,9 @@ func TestWithdrawals_List(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			repository := test.rSetup(t)
 			logger := test.lSetup(t)
-			service := NewWithdrawals(repository, logger)
-			list, err := service.List(test.ctx)
-			assert.Equal(t, test.want.list, list, "Get users withdrawals")
+			service := NewWithdrawals(repository, logger, 1)
+			page, err := service.List(test.ctx, test.query)
+			assert.Equal(t, test.want.page, page, "Get users withdrawals")
 			assert.ErrorIs(t, err, test.want.err, "Get users withdrawals error")
 		})
 	}
@@ -0,0 +1,210 @@
+// Package webhook доставляет события изменения статуса заказов на вебхуки пользователей.
+// События берутся из таблицы-outbox, поэтому переживают перезапуск сервиса.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/logger"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/poller"
+)
+
+const (
+	HeaderEventID   = "X-Gophermart-Event-Id"
+	HeaderSignature = "X-Gophermart-Signature"
+
+	maxErrorBody = 512
+)
+
+type Repository interface {
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id uint64) error
+	Retry(ctx context.Context, id uint64, reason string, at time.Time) error
+	Release(ctx context.Context, id uint64, at time.Time) error
+	Fail(ctx context.Context, id uint64, reason string) error
+}
+
+type Logger interface {
+	Error(message string, err error, fields ...logger.Field)
+	Warn(message string, err error, fields ...logger.Field)
+}
+
+// Config задержка перед повторной попыткой удваивается, начиная с BaseDelay,
+// но не превышает MaxDelay. После MaxAttempts неудач событие больше не отправляется.
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	Timeout      time.Duration
+	MaxAttempts  int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+}
+
+var DefaultConfig = Config{
+	PollInterval: time.Second,
+	BatchSize:    20,
+	Timeout:      5 * time.Second,
+	MaxAttempts:  10,
+	BaseDelay:    10 * time.Second,
+	MaxDelay:     time.Hour,
+}
+
+type Event struct {
+	ID      string    `json:"id"`
+	Type    string    `json:"type"`
+	Created time.Time `json:"created_at"`
+	Data    OrderData `json:"data"`
+}
+
+type OrderData struct {
+	Number  string        `json:"number"`
+	Status  string        `json:"status"`
+	Accrual *money.Amount `json:"accrual,omitempty"`
+}
+
+// Dispatcher в отличие от outbox-релея доставляет события независимо друг от друга:
+// неудача одного вебхука не задерживает остальные, а после MaxAttempts событие отбрасывается.
+type Dispatcher struct {
+	repository Repository
+	logger     Logger
+	config     Config
+	client     *http.Client
+	now        func() time.Time
+	poller     *poller.Poller
+}
+
+func NewDispatcher(r Repository, l Logger, cfg Config) *Dispatcher {
+	d := &Dispatcher{
+		repository: r,
+		logger:     l,
+		config:     cfg,
+		client:     newClient(cfg.Timeout, PublicAddr),
+		now:        time.Now,
+	}
+	d.poller = poller.New("webhook dispatcher", cfg.PollInterval, d.dispatch)
+
+	return d
+}
+
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.poller.Run(ctx)
+}
+
+// Stop дожидается окончания отправки текущего события.
+func (d *Dispatcher) Stop() {
+	d.poller.Stop()
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context) {
+	deliveries, err := d.repository.ClaimDeliveries(ctx, d.config.BatchSize, poller.Lease(d.config.BatchSize, d.config.Timeout))
+	if err != nil {
+		d.logger.Error("failed to claim webhook deliveries", err)
+		return
+	}
+
+	// Отметки о доставке сохраняются и после сигнала остановки.
+	storeCtx := context.WithoutCancel(ctx)
+
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			d.release(storeCtx, delivery)
+			continue
+		}
+
+		err := d.deliver(ctx, delivery)
+		switch {
+		case err == nil:
+			if err := d.repository.MarkDelivered(storeCtx, delivery.ID); err != nil {
+				d.logger.Error("failed to mark webhook delivered", err)
+			}
+		case ctx.Err() != nil:
+			d.release(storeCtx, delivery)
+		case delivery.Attempts+1 >= d.config.MaxAttempts:
+			d.logger.Warn("webhook delivery failed, attempts exhausted", err)
+			if err := d.repository.Fail(storeCtx, delivery.ID, err.Error()); err != nil {
+				d.logger.Error("failed to mark webhook delivery failed", err)
+			}
+		default:
+			at := d.now().Add(d.delay(delivery.Attempts + 1))
+			if err := d.repository.Retry(storeCtx, delivery.ID, err.Error(), at); err != nil {
+				d.logger.Error("failed to reschedule webhook delivery", err)
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery entity.WebhookDelivery) error {
+	event := Event{
+		ID:      delivery.EventID,
+		Type:    entity.WebhookEventOrderStatusChanged,
+		Created: delivery.Created,
+		Data:    OrderData{Number: delivery.OrderNumber, Status: delivery.Status},
+	}
+	if delivery.Accrual > 0 {
+		event.Data.Accrual = &delivery.Accrual
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gophermart-webhooks")
+	req.Header.Set(HeaderEventID, delivery.EventID)
+	req.Header.Set(HeaderSignature, "t="+strconv.FormatInt(timestamp, 10)+",v1="+Sign(delivery.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, bytes.TrimSpace(text))
+	}
+
+	return nil
+}
+
+// release возвращает событие в очередь, не засчитывая попытку.
+func (d *Dispatcher) release(ctx context.Context, delivery entity.WebhookDelivery) {
+	if err := d.repository.Release(ctx, delivery.ID, d.now()); err != nil {
+		d.logger.Error("failed to release webhook delivery", err)
+	}
+}
+
+// delay failures считает и текущую неудачу.
+func (d *Dispatcher) delay(failures int) time.Duration {
+	return poller.Backoff(failures, d.config.BaseDelay, d.config.MaxDelay)
+}
+
+// Sign подпись события: HMAC-SHA256 от "<timestamp>.<body>" в hex.
+// Получатель проверяет ее и отклоняет события со старой меткой времени.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
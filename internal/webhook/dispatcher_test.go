@@ -0,0 +1,301 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/entity"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/money"
+	"github.com/EshkinKot1980/gophermart-loyalty/internal/webhook/mocks"
+)
+
+var testConfig = Config{
+	PollInterval: time.Second,
+	BatchSize:    10,
+	Timeout:      time.Second,
+	MaxAttempts:  3,
+	BaseDelay:    10 * time.Second,
+	MaxDelay:     time.Minute,
+}
+
+func TestDispatcher_delay(t *testing.T) {
+	d := NewDispatcher(nil, nil, testConfig)
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 10 * time.Second},
+		{attempts: 2, want: 20 * time.Second},
+		{attempts: 3, want: 40 * time.Second},
+		{attempts: 4, want: time.Minute},
+		{attempts: 100, want: time.Minute},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("attempts_%d", test.attempts), func(t *testing.T) {
+			assert.Equal(t, test.want, d.delay(test.attempts), "Retry delay")
+		})
+	}
+}
+
+func TestDispatcher_dispatch(t *testing.T) {
+	now := time.Date(2025, 10, 9, 10, 0, 0, 0, time.UTC)
+	wantBody := `{"id":"6f1c2e5a-0b7d-4f4e-9a51-1d2f0c3b4a59","type":"order.status_changed",` +
+		`"created_at":"2025-10-09T09:59:00Z","data":{"number":"5062821234567892","status":"PROCESSED","accrual":729.98}}`
+
+	delivery := func(url string, attempts int) entity.WebhookDelivery {
+		return entity.WebhookDelivery{
+			ID:          3,
+			EventID:     "6f1c2e5a-0b7d-4f4e-9a51-1d2f0c3b4a59",
+			OrderNumber: "5062821234567892",
+			Status:      entity.OrderStatusProcessed,
+			Accrual:     money.New(729, 98),
+			Attempts:    attempts,
+			Created:     now.Add(-time.Minute),
+			URL:         url,
+			Secret:      "testSecret",
+		}
+	}
+
+	tests := []struct {
+		name   string
+		status int
+		rSetup func(t *testing.T, url string) Repository
+		lSetup func(t *testing.T) Logger
+	}{
+		{
+			name:   "success",
+			status: http.StatusNoContent,
+			rSetup: func(t *testing.T, url string) Repository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockRepository(ctrl)
+				repository.EXPECT().
+					ClaimDeliveries(gomock.All(), testConfig.BatchSize, gomock.All()).
+					Return([]entity.WebhookDelivery{delivery(url, 0)}, nil)
+				repository.EXPECT().
+					MarkDelivered(gomock.All(), uint64(3)).
+					Return(nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+		},
+		{
+			name:   "retry_on_server_error",
+			status: http.StatusInternalServerError,
+			rSetup: func(t *testing.T, url string) Repository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockRepository(ctrl)
+				repository.EXPECT().
+					ClaimDeliveries(gomock.All(), testConfig.BatchSize, gomock.All()).
+					Return([]entity.WebhookDelivery{delivery(url, 1)}, nil)
+				repository.EXPECT().
+					Retry(gomock.All(), uint64(3), "unexpected status code 500: oops", now.Add(20*time.Second)).
+					Return(nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+		},
+		{
+			name:   "retry_on_redirect",
+			status: http.StatusFound,
+			rSetup: func(t *testing.T, url string) Repository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockRepository(ctrl)
+				repository.EXPECT().
+					ClaimDeliveries(gomock.All(), testConfig.BatchSize, gomock.All()).
+					Return([]entity.WebhookDelivery{delivery(url, 0)}, nil)
+				repository.EXPECT().
+					Retry(gomock.All(), uint64(3), "unexpected status code 302: oops", now.Add(10*time.Second)).
+					Return(nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("", gomock.All()).
+					Times(0)
+				return logger
+			},
+		},
+		{
+			name:   "fail_when_attempts_exhausted",
+			status: http.StatusInternalServerError,
+			rSetup: func(t *testing.T, url string) Repository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockRepository(ctrl)
+				repository.EXPECT().
+					ClaimDeliveries(gomock.All(), testConfig.BatchSize, gomock.All()).
+					Return([]entity.WebhookDelivery{delivery(url, 2)}, nil)
+				repository.EXPECT().
+					Fail(gomock.All(), uint64(3), "unexpected status code 500: oops").
+					Return(nil)
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Warn("webhook delivery failed, attempts exhausted", gomock.All())
+				return logger
+			},
+		},
+		{
+			name: "negative_claim_error",
+			rSetup: func(t *testing.T, url string) Repository {
+				ctrl := gomock.NewController(t)
+				repository := mocks.NewMockRepository(ctrl)
+				repository.EXPECT().
+					ClaimDeliveries(gomock.All(), testConfig.BatchSize, gomock.All()).
+					Return(nil, fmt.Errorf("any error"))
+				return repository
+			},
+			lSetup: func(t *testing.T) Logger {
+				ctrl := gomock.NewController(t)
+				logger := mocks.NewMockLogger(ctrl)
+				logger.EXPECT().
+					Error("failed to claim webhook deliveries", gomock.All())
+				return logger
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				timestamp := strconv.FormatInt(now.Unix(), 10)
+				assert.Equal(t, http.MethodPost, r.Method, "Request method")
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"), "Content-Type header")
+				assert.Equal(t, "6f1c2e5a-0b7d-4f4e-9a51-1d2f0c3b4a59", r.Header.Get(HeaderEventID), "Event id header")
+				assert.Equal(t, "t="+timestamp+",v1="+Sign("testSecret", now.Unix(), body), r.Header.Get(HeaderSignature), "Signature header")
+				assert.JSONEq(t, wantBody, string(body), "Request body")
+
+				if test.status >= 300 {
+					// Если клиент пойдет по редиректу, повторный GET-запрос провалит проверки выше.
+					w.Header().Set("Location", "/redirected")
+					http.Error(w, "oops", test.status)
+					return
+				}
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			d := NewDispatcher(test.rSetup(t, server.URL), test.lSetup(t), testConfig)
+			// Тестовый сервер слушает loopback.
+			d.client = newClient(testConfig.Timeout, func(netip.Addr) bool { return true })
+			d.now = func() time.Time { return now }
+			d.dispatch(context.Background())
+		})
+	}
+}
+
+func TestDispatcher_dispatchInternalAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request to loopback address must not be sent")
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	repository := mocks.NewMockRepository(ctrl)
+	repository.EXPECT().
+		ClaimDeliveries(gomock.All(), testConfig.BatchSize, gomock.All()).
+		Return([]entity.WebhookDelivery{{ID: 3, URL: server.URL, Status: entity.OrderStatusProcessed}}, nil)
+	repository.EXPECT().
+		Retry(gomock.All(), uint64(3), gomock.All(), gomock.All()).
+		DoAndReturn(func(_ context.Context, _ uint64, reason string, _ time.Time) error {
+			assert.Contains(t, reason, ErrForbiddenAddress.Error(), "Retry reason")
+			return nil
+		})
+
+	d := NewDispatcher(repository, mocks.NewMockLogger(ctrl), testConfig)
+	d.dispatch(context.Background())
+}
+
+func TestPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "93.184.216.34", want: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", want: true},
+		{addr: "127.0.0.1", want: false},
+		{addr: "::1", want: false},
+		{addr: "10.1.2.3", want: false},
+		{addr: "172.16.0.1", want: false},
+		{addr: "192.168.1.1", want: false},
+		{addr: "fd00::1", want: false},
+		{addr: "169.254.169.254", want: false},
+		{addr: "fe80::1", want: false},
+		{addr: "0.0.0.0", want: false},
+		{addr: "::", want: false},
+		{addr: "::ffff:127.0.0.1", want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.addr, func(t *testing.T) {
+			assert.Equal(t, test.want, PublicAddr(netip.MustParseAddr(test.addr)), "Public address")
+		})
+	}
+}
+
+func TestDispatcher_dispatchStopped(t *testing.T) {
+	now := time.Date(2025, 10, 9, 10, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	repository := mocks.NewMockRepository(ctrl)
+	repository.EXPECT().
+		ClaimDeliveries(gomock.All(), testConfig.BatchSize, gomock.All()).
+		Return([]entity.WebhookDelivery{{ID: 3, Attempts: 1}, {ID: 4}}, nil)
+	repository.EXPECT().
+		Release(gomock.All(), uint64(3), now).
+		Return(nil)
+	repository.EXPECT().
+		Release(gomock.All(), uint64(4), now).
+		Return(nil)
+
+	d := NewDispatcher(repository, mocks.NewMockLogger(ctrl), testConfig)
+	d.now = func() time.Time { return now }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d.dispatch(ctx)
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+
+	assert.Equal(t, Sign("testSecret", 1760004000, body), Sign("testSecret", 1760004000, body), "Stable signature")
+	assert.NotEqual(t, Sign("testSecret", 1760004000, body), Sign("testSecret", 1760004001, body), "Timestamp is signed")
+	assert.NotEqual(t, Sign("testSecret", 1760004000, body), Sign("otherSecret", 1760004000, body), "Secret is used")
+	assert.Len(t, Sign("testSecret", 1760004000, body), 64, "Hex encoded sha256")
+}